@@ -0,0 +1,105 @@
+package audit
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"rmpc-server/api/_pkg/auth"
+	"rmpc-server/api/_pkg/db"
+)
+
+// Actions recorded in the audit log.
+const (
+	ActionSessionCreate  = "session.create"
+	ActionSessionRotate  = "session.rotate"
+	ActionSessionDelete  = "session.delete"  // signed out
+	ActionSessionRevoke  = "session.revoke"  // by the player, one or all of their own
+	ActionSessionsRevoke = "sessions.revoke" // by a moderator
+	ActionAPIKeyCreate   = "api_key.create"
+	ActionAPIKeyRevoke   = "api_key.revoke"
+	ActionPlayerBan      = "player.ban"
+	ActionPlayerUnban    = "player.unban"
+	ActionScoreRemove    = "score.remove"
+	ActionScoreRestore   = "score.restore"
+	ActionScoreAccept    = "score.accept"
+	ActionScoreReject    = "score.reject"
+	ActionScoreRejected  = "score.rejected"
+	ActionScoreFlagged   = "score.flagged"
+	ActionScoreProof     = "score.proof"
+	ActionMetricRejected = "metric.rejected"
+)
+
+// Kinds of thing an entry can be about.
+const (
+	TargetPlayer  = "player"
+	TargetSession = "session"
+	TargetAPIKey  = "api_key"
+	TargetScore   = "score"
+	TargetMetric  = "metric"
+)
+
+// maxTargetIDLength matches audit_log.target_id. Target IDs can come straight
+// from client input (a rejected metric name, say), so longer ones are cut.
+const maxTargetIDLength = 255
+
+type Entry struct {
+	Actor      uuid.UUID // uuid.Nil when nobody is signed in
+	Action     string
+	TargetType string
+	TargetID   string
+	Details    map[string]any
+}
+
+// Record appends e to the audit log, stamped with the request's client IP.
+// Errors are logged rather than returned: failing to audit an action must not
+// fail the action itself.
+func Record(r *http.Request, e Entry) {
+	input, err := e.input(auth.GetClientIP(r))
+	if err != nil {
+		slog.Error("audit entry encoding error", "error", err, "action", e.Action)
+		return
+	}
+
+	database, err := db.GetDB()
+	if err != nil {
+		slog.Error("database connection error", "error", err)
+		return
+	}
+
+	if err := db.InsertAuditEntry(database, input); err != nil {
+		slog.Error("audit log write error", "error", err, "action", e.Action)
+	}
+}
+
+func (e Entry) input(ip string) (db.AuditInput, error) {
+	input := db.AuditInput{
+		Action:     e.Action,
+		TargetType: e.TargetType,
+	}
+	if e.Actor != uuid.Nil {
+		input.ActorID = &e.Actor
+	}
+	if e.TargetID != "" {
+		targetID := e.TargetID
+		if len(targetID) > maxTargetIDLength {
+			targetID = strings.ToValidUTF8(targetID[:maxTargetIDLength], "")
+		}
+		input.TargetID = &targetID
+	}
+	if ip != "" {
+		input.IP = &ip
+	}
+	if len(e.Details) > 0 {
+		b, err := json.Marshal(e.Details)
+		if err != nil {
+			return db.AuditInput{}, err
+		}
+		details := string(b)
+		input.Details = &details
+	}
+	return input, nil
+}
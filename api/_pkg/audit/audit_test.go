@@ -0,0 +1,89 @@
+package audit
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestEntryInput(t *testing.T) {
+	actor := uuid.New()
+
+	tests := []struct {
+		name        string
+		entry       Entry
+		ip          string
+		wantActor   bool
+		wantTarget  bool
+		wantIP      bool
+		wantDetails string
+	}{
+		{
+			name:        "full entry",
+			entry:       Entry{Actor: actor, Action: ActionPlayerBan, TargetType: TargetPlayer, TargetID: "abc", Details: map[string]any{"reason": "cheating"}},
+			ip:          "203.0.113.7",
+			wantActor:   true,
+			wantTarget:  true,
+			wantIP:      true,
+			wantDetails: `{"reason":"cheating"}`,
+		},
+		{
+			name:  "anonymous entry without details",
+			entry: Entry{Action: ActionMetricRejected, TargetType: TargetMetric},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input, err := tt.entry.input(tt.ip)
+			if err != nil {
+				t.Fatalf("input() error = %v", err)
+			}
+			if input.Action != tt.entry.Action || input.TargetType != tt.entry.TargetType {
+				t.Errorf("action/target type = %q/%q, want %q/%q", input.Action, input.TargetType, tt.entry.Action, tt.entry.TargetType)
+			}
+			if (input.ActorID != nil) != tt.wantActor {
+				t.Errorf("ActorID = %v, want set = %v", input.ActorID, tt.wantActor)
+			}
+			if tt.wantActor && *input.ActorID != actor {
+				t.Errorf("ActorID = %v, want %v", *input.ActorID, actor)
+			}
+			if (input.TargetID != nil) != tt.wantTarget {
+				t.Errorf("TargetID = %v, want set = %v", input.TargetID, tt.wantTarget)
+			}
+			if (input.IP != nil) != tt.wantIP {
+				t.Errorf("IP = %v, want set = %v", input.IP, tt.wantIP)
+			}
+			if tt.wantDetails == "" {
+				if input.Details != nil {
+					t.Errorf("Details = %q, want nil", *input.Details)
+				}
+			} else if input.Details == nil || *input.Details != tt.wantDetails {
+				t.Errorf("Details = %v, want %q", input.Details, tt.wantDetails)
+			}
+		})
+	}
+}
+
+func TestEntryInputTruncatesTargetID(t *testing.T) {
+	// 254 ASCII bytes followed by a two-byte rune straddling the limit
+	id := strings.Repeat("a", 254) + "é" + "tail"
+	e := Entry{Action: ActionMetricRejected, TargetType: TargetMetric, TargetID: id}
+
+	input, err := e.input("")
+	if err != nil {
+		t.Fatalf("input() error = %v", err)
+	}
+	got := *input.TargetID
+	if got != strings.Repeat("a", 254) {
+		t.Errorf("TargetID = %q (len %d), want 254 a's", got, len(got))
+	}
+}
+
+func TestEntryInputUnencodableDetails(t *testing.T) {
+	e := Entry{Action: ActionScoreRejected, TargetType: TargetPlayer, Details: map[string]any{"bad": make(chan int)}}
+	if _, err := e.input(""); err == nil {
+		t.Error("input() error = nil, want encoding error")
+	}
+}
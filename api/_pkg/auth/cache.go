@@ -0,0 +1,70 @@
+package auth
+
+import (
+	"sync"
+	"time"
+)
+
+const maxCachedIdentities = 1000
+
+// identityCache remembers successful validations keyed by token hash, so a
+// burst of logins with the same token only reaches the upstream once.
+type identityCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]cachedIdentity
+}
+
+type cachedIdentity struct {
+	identity  Identity
+	expiresAt time.Time
+}
+
+func newIdentityCache(ttl time.Duration) *identityCache {
+	return &identityCache{
+		ttl:     ttl,
+		entries: make(map[string]cachedIdentity),
+	}
+}
+
+func (c *identityCache) Get(tokenHash string) (*Identity, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[tokenHash]
+	if !ok {
+		return nil, false
+	}
+	if time.Now().After(e.expiresAt) {
+		delete(c.entries, tokenHash)
+		return nil, false
+	}
+	identity := e.identity
+	return &identity, true
+}
+
+func (c *identityCache) Put(tokenHash string, identity Identity) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if len(c.entries) >= maxCachedIdentities {
+		for k, e := range c.entries {
+			if now.After(e.expiresAt) {
+				delete(c.entries, k)
+			}
+		}
+	}
+	// Still full of live entries: drop an arbitrary one rather than grow
+	if len(c.entries) >= maxCachedIdentities {
+		for k := range c.entries {
+			delete(c.entries, k)
+			break
+		}
+	}
+
+	c.entries[tokenHash] = cachedIdentity{
+		identity:  identity,
+		expiresAt: now.Add(c.ttl),
+	}
+}
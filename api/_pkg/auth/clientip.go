@@ -0,0 +1,150 @@
+package auth
+
+import (
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+	"sync"
+
+	"rmpc-server/api/_pkg/config"
+)
+
+// TrustedProxies decides whether X-Real-Ip and X-Forwarded-For are believed.
+// Forwarding headers are only honored when the direct peer is a trusted
+// proxy; otherwise anyone could pick their own IP and dodge rate limits.
+type TrustedProxies struct {
+	// vercel trusts the headers unconditionally: Vercel's edge overwrites
+	// them and functions never see the client connection directly.
+	vercel   bool
+	prefixes []netip.Prefix
+}
+
+// ParseTrustedProxies parses a comma-separated list of CIDRs or single IPs,
+// or one of the presets "vercel" and "none". An empty list trusts nobody.
+func ParseTrustedProxies(s string) (*TrustedProxies, error) {
+	p := &TrustedProxies{}
+	entries := strings.Split(s, ",")
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		switch {
+		case entry == "":
+			continue
+		case entry == "vercel" || entry == "none":
+			if len(entries) > 1 {
+				return nil, fmt.Errorf("preset %q cannot be combined with other entries", entry)
+			}
+			p.vercel = entry == "vercel"
+		case strings.Contains(entry, "/"):
+			prefix, err := netip.ParsePrefix(entry)
+			if err != nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q: %w", entry, err)
+			}
+			p.prefixes = append(p.prefixes, prefix.Masked())
+		default:
+			addr, err := netip.ParseAddr(entry)
+			if err != nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q: %w", entry, err)
+			}
+			addr = addr.Unmap()
+			p.prefixes = append(p.prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+		}
+	}
+	return p, nil
+}
+
+func (p *TrustedProxies) trusts(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	for _, prefix := range p.prefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClientIP returns the address of the client behind r.
+func (p *TrustedProxies) ClientIP(r *http.Request) string {
+	if p.vercel {
+		// Prefer X-Real-Ip, falling back to the rightmost X-Forwarded-For
+		// entry (appended by the proxy)
+		if realIP := r.Header.Get("X-Real-Ip"); realIP != "" {
+			return strings.TrimSpace(realIP)
+		}
+		if xff := r.Header.Get("X-Forwarded-For"); xff != "" {
+			parts := strings.Split(xff, ",")
+			return strings.TrimSpace(parts[len(parts)-1])
+		}
+		return remoteHost(r)
+	}
+
+	peer, ok := parseIP(remoteHost(r))
+	if !ok || !p.trusts(peer) {
+		return remoteHost(r)
+	}
+
+	// Walk X-Forwarded-For from the right: each entry was appended by the hop
+	// to its right, so the first one that isn't a trusted proxy is the client.
+	// An unparseable entry can only have come from behind a trusted proxy's
+	// back, so stop at the last good hop.
+	client := peer
+	if xff := r.Header.Values("X-Forwarded-For"); len(xff) > 0 {
+		parts := strings.Split(strings.Join(xff, ","), ",")
+		for i := len(parts) - 1; i >= 0; i-- {
+			addr, ok := parseIP(parts[i])
+			if !ok {
+				break
+			}
+			client = addr
+			if !p.trusts(addr) {
+				break
+			}
+		}
+		return client.String()
+	}
+
+	if addr, ok := parseIP(r.Header.Get("X-Real-Ip")); ok {
+		return addr.String()
+	}
+	return client.String()
+}
+
+// parseIP accepts a bare IP or an IP with a port, as some proxies write it.
+func parseIP(s string) (netip.Addr, bool) {
+	s = strings.TrimSpace(s)
+	if addr, err := netip.ParseAddr(s); err == nil {
+		return addr.Unmap(), true
+	}
+	if addrPort, err := netip.ParseAddrPort(s); err == nil {
+		return addrPort.Addr().Unmap(), true
+	}
+	return netip.Addr{}, false
+}
+
+func remoteHost(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+var (
+	trustedProxies     *TrustedProxies
+	trustedProxiesOnce sync.Once
+)
+
+// GetClientIP resolves the client address of r according to TRUSTED_PROXIES.
+func GetClientIP(r *http.Request) string {
+	trustedProxiesOnce.Do(func() {
+		var err error
+		trustedProxies, err = ParseTrustedProxies(config.Env.TrustedProxies)
+		if err != nil {
+			slog.Error("invalid TRUSTED_PROXIES, ignoring forwarding headers", "error", err)
+			trustedProxies = &TrustedProxies{}
+		}
+	})
+	return trustedProxies.ClientIP(r)
+}
@@ -0,0 +1,172 @@
+package auth
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParseTrustedProxies(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr bool
+	}{
+		{"vercel preset", "vercel", false},
+		{"none preset", "none", false},
+		{"empty", "", false},
+		{"cidrs", "10.0.0.0/8, 192.168.1.0/24,fd00::/8", false},
+		{"single addresses", "127.0.0.1,::1", false},
+		{"invalid cidr", "10.0.0.0/33", true},
+		{"invalid address", "proxy.local", true},
+		{"preset mixed with cidr", "vercel,10.0.0.0/8", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseTrustedProxies(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseTrustedProxies(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	tests := []struct {
+		name       string
+		proxies    string
+		remoteAddr string
+		realIP     string
+		xff        []string
+		want       string
+	}{
+		{
+			name:       "vercel prefers X-Real-Ip",
+			proxies:    "vercel",
+			remoteAddr: "10.0.0.1:1234",
+			realIP:     "203.0.113.7",
+			xff:        []string{"198.51.100.1"},
+			want:       "203.0.113.7",
+		},
+		{
+			name:       "vercel falls back to rightmost X-Forwarded-For",
+			proxies:    "vercel",
+			remoteAddr: "10.0.0.1:1234",
+			xff:        []string{"1.1.1.1, 203.0.113.7"},
+			want:       "203.0.113.7",
+		},
+		{
+			name:       "vercel without headers uses peer",
+			proxies:    "vercel",
+			remoteAddr: "203.0.113.7:1234",
+			want:       "203.0.113.7",
+		},
+		{
+			name:       "none ignores spoofed headers",
+			proxies:    "none",
+			remoteAddr: "203.0.113.7:1234",
+			realIP:     "1.1.1.1",
+			xff:        []string{"1.1.1.1"},
+			want:       "203.0.113.7",
+		},
+		{
+			name:       "untrusted peer ignores headers",
+			proxies:    "10.0.0.0/8",
+			remoteAddr: "203.0.113.7:1234",
+			realIP:     "1.1.1.1",
+			xff:        []string{"1.1.1.1"},
+			want:       "203.0.113.7",
+		},
+		{
+			name:       "trusted peer honors X-Forwarded-For",
+			proxies:    "10.0.0.0/8",
+			remoteAddr: "10.0.0.1:1234",
+			xff:        []string{"203.0.113.7"},
+			want:       "203.0.113.7",
+		},
+		{
+			name:       "client-supplied entries left of the real client are ignored",
+			proxies:    "10.0.0.0/8",
+			remoteAddr: "10.0.0.1:1234",
+			xff:        []string{"1.1.1.1, 203.0.113.7"},
+			want:       "203.0.113.7",
+		},
+		{
+			name:       "chained trusted proxies are skipped",
+			proxies:    "10.0.0.0/8",
+			remoteAddr: "10.0.0.1:1234",
+			xff:        []string{"1.1.1.1, 203.0.113.7, 10.0.0.2", "10.0.0.3"},
+			want:       "203.0.113.7",
+		},
+		{
+			name:       "all hops trusted yields leftmost",
+			proxies:    "10.0.0.0/8",
+			remoteAddr: "10.0.0.1:1234",
+			xff:        []string{"10.0.0.5, 10.0.0.2"},
+			want:       "10.0.0.5",
+		},
+		{
+			name:       "garbage entry stops at last good hop",
+			proxies:    "10.0.0.0/8",
+			remoteAddr: "10.0.0.1:1234",
+			xff:        []string{"203.0.113.7, not-an-ip, 10.0.0.2"},
+			want:       "10.0.0.2",
+		},
+		{
+			name:       "entries with ports",
+			proxies:    "10.0.0.0/8",
+			remoteAddr: "10.0.0.1:1234",
+			xff:        []string{"203.0.113.7:5555"},
+			want:       "203.0.113.7",
+		},
+		{
+			name:       "trusted peer falls back to X-Real-Ip",
+			proxies:    "10.0.0.0/8",
+			remoteAddr: "10.0.0.1:1234",
+			realIP:     "203.0.113.7",
+			want:       "203.0.113.7",
+		},
+		{
+			name:       "trusted peer without headers",
+			proxies:    "10.0.0.0/8",
+			remoteAddr: "10.0.0.1:1234",
+			want:       "10.0.0.1",
+		},
+		{
+			name:       "ipv6 trusted proxy",
+			proxies:    "::1",
+			remoteAddr: "[::1]:1234",
+			xff:        []string{"2001:db8::7"},
+			want:       "2001:db8::7",
+		},
+		{
+			name:       "ipv4-mapped peer matches ipv4 cidr",
+			proxies:    "127.0.0.0/8",
+			remoteAddr: "[::ffff:127.0.0.1]:1234",
+			xff:        []string{"203.0.113.7"},
+			want:       "203.0.113.7",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			proxies, err := ParseTrustedProxies(tt.proxies)
+			if err != nil {
+				t.Fatalf("ParseTrustedProxies(%q) error = %v", tt.proxies, err)
+			}
+
+			r := httptest.NewRequest("GET", "/", nil)
+			r.RemoteAddr = tt.remoteAddr
+			if tt.realIP != "" {
+				r.Header.Set("X-Real-Ip", tt.realIP)
+			}
+			for _, v := range tt.xff {
+				r.Header.Add("X-Forwarded-For", v)
+			}
+
+			if got := proxies.ClientIP(r); got != tt.want {
+				t.Errorf("ClientIP() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,163 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"rmpc-server/api/_pkg/config"
+)
+
+// ErrInvalidCredential is returned by providers that recognise the credential
+// format but don't accept the credential itself.
+var ErrInvalidCredential = errors.New("invalid credential")
+
+// ErrProviderUnavailable is returned when the upstream identity service can't
+// be reached, so callers can tell "try again later" from "bad token".
+var ErrProviderUnavailable = errors.New("identity provider unavailable")
+
+// Identity is the player an IdentityProvider vouches for.
+type Identity struct {
+	AccountID   string `json:"account_id"`
+	DisplayName string `json:"display_name"`
+}
+
+// IdentityProvider turns a client-presented credential into an Identity.
+type IdentityProvider interface {
+	Validate(token string) (*Identity, error)
+}
+
+// StaticProvider accepts a fixed set of plaintext tokens. Meant for tests and
+// local development only.
+type StaticProvider struct {
+	users map[string]Identity
+}
+
+func NewStaticProvider(users map[string]Identity) *StaticProvider {
+	return &StaticProvider{users: users}
+}
+
+func (p *StaticProvider) Validate(token string) (*Identity, error) {
+	user, ok := p.users[token]
+	if !ok {
+		return nil, ErrInvalidCredential
+	}
+	return &user, nil
+}
+
+// APIKeyProvider accepts long-lived keys for bots. Keys are configured by
+// their SHA-256 hash (see HashToken) so the plaintext never sits in config.
+type APIKeyProvider struct {
+	keys map[string]Identity
+}
+
+func NewAPIKeyProvider(keyHashes map[string]Identity) *APIKeyProvider {
+	return &APIKeyProvider{keys: keyHashes}
+}
+
+func (p *APIKeyProvider) Validate(token string) (*Identity, error) {
+	user, ok := p.keys[HashToken(token)]
+	if !ok {
+		return nil, ErrInvalidCredential
+	}
+	return &user, nil
+}
+
+// chainProvider tries each provider in order and returns the first identity
+// accepted. When none accepts the token, an unavailable provider takes
+// precedence over rejections, since the token may well have been valid there.
+type chainProvider []IdentityProvider
+
+func (c chainProvider) Validate(token string) (*Identity, error) {
+	var lastErr, unavailableErr error
+	for _, p := range c {
+		user, err := p.Validate(token)
+		if err == nil {
+			return user, nil
+		}
+		if errors.Is(err, ErrProviderUnavailable) {
+			unavailableErr = err
+		}
+		lastErr = err
+	}
+	if unavailableErr != nil {
+		return nil, unavailableErr
+	}
+	return nil, lastErr
+}
+
+// NewProvider builds a provider from a comma-separated list of names
+// ("openplanet", "static", "apikey"). With several names, they are tried in
+// the given order.
+func NewProvider(names string) (IdentityProvider, error) {
+	var chain chainProvider
+	for _, name := range strings.Split(names, ",") {
+		switch strings.TrimSpace(name) {
+		case "openplanet":
+			chain = append(chain, NewOpenplanetProvider(config.Env.OpenplanetAuthURL, config.Env.OpenplanetPluginSecret))
+		case "static":
+			users, err := ParseIdentityList(config.Env.IdentityStaticUsers)
+			if err != nil {
+				return nil, fmt.Errorf("IDENTITY_STATIC_USERS: %w", err)
+			}
+			chain = append(chain, NewStaticProvider(users))
+		case "apikey":
+			keys, err := ParseIdentityList(config.Env.IdentityAPIKeys)
+			if err != nil {
+				return nil, fmt.Errorf("IDENTITY_API_KEYS: %w", err)
+			}
+			chain = append(chain, NewAPIKeyProvider(keys))
+		default:
+			return nil, fmt.Errorf("unknown identity provider %q", name)
+		}
+	}
+	if len(chain) == 1 {
+		return chain[0], nil
+	}
+	return chain, nil
+}
+
+// ParseIdentityList parses "key=account_id:Display Name" entries separated by
+// commas. The key is a plaintext token for the static provider and a token
+// hash for the API key provider.
+func ParseIdentityList(s string) (map[string]Identity, error) {
+	out := make(map[string]Identity)
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		key, rest, ok := strings.Cut(entry, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid entry %q, expected key=account_id:name", entry)
+		}
+		accountID, displayName, ok := strings.Cut(rest, ":")
+		if !ok || accountID == "" || displayName == "" {
+			return nil, fmt.Errorf("invalid entry %q, expected key=account_id:name", entry)
+		}
+		out[key] = Identity{AccountID: accountID, DisplayName: displayName}
+	}
+	return out, nil
+}
+
+var (
+	provider     IdentityProvider
+	providerOnce sync.Once
+	providerErr  error
+)
+
+// Provider returns the identity provider selected by IDENTITY_PROVIDER.
+func Provider() (IdentityProvider, error) {
+	providerOnce.Do(func() {
+		provider, providerErr = NewProvider(config.Env.IdentityProvider)
+	})
+	return provider, providerErr
+}
+
+// SetProvider overrides the configured provider, e.g. for the dev server.
+// Must be called before the first request is served.
+func SetProvider(p IdentityProvider) {
+	providerOnce.Do(func() {})
+	provider, providerErr = p, nil
+}
@@ -0,0 +1,103 @@
+package auth
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestStaticProvider(t *testing.T) {
+	p := NewStaticProvider(map[string]Identity{
+		"token-alice": {AccountID: "op-alice", DisplayName: "Alice"},
+	})
+
+	user, err := p.Validate("token-alice")
+	if err != nil {
+		t.Fatalf("Validate() error: %v", err)
+	}
+	if user.AccountID != "op-alice" || user.DisplayName != "Alice" {
+		t.Fatalf("Validate() = %+v, want op-alice/Alice", user)
+	}
+
+	if _, err := p.Validate("token-mallory"); !errors.Is(err, ErrInvalidCredential) {
+		t.Fatalf("Validate(unknown) error = %v, want ErrInvalidCredential", err)
+	}
+}
+
+func TestAPIKeyProviderMatchesHash(t *testing.T) {
+	p := NewAPIKeyProvider(map[string]Identity{
+		HashToken("bot-key"): {AccountID: "bot-1", DisplayName: "Bot"},
+	})
+
+	if _, err := p.Validate("bot-key"); err != nil {
+		t.Fatalf("Validate(plaintext key) error: %v", err)
+	}
+	if _, err := p.Validate(HashToken("bot-key")); !errors.Is(err, ErrInvalidCredential) {
+		t.Fatalf("Validate(hash) error = %v, want ErrInvalidCredential", err)
+	}
+}
+
+func TestChainProvider(t *testing.T) {
+	chain := chainProvider{
+		NewStaticProvider(map[string]Identity{"a": {AccountID: "op-a", DisplayName: "A"}}),
+		NewStaticProvider(map[string]Identity{"b": {AccountID: "op-b", DisplayName: "B"}}),
+	}
+
+	for token, want := range map[string]string{"a": "op-a", "b": "op-b"} {
+		user, err := chain.Validate(token)
+		if err != nil {
+			t.Fatalf("Validate(%q) error: %v", token, err)
+		}
+		if user.AccountID != want {
+			t.Fatalf("Validate(%q) = %q, want %q", token, user.AccountID, want)
+		}
+	}
+	if _, err := chain.Validate("c"); !errors.Is(err, ErrInvalidCredential) {
+		t.Fatalf("Validate(unknown) error = %v, want ErrInvalidCredential", err)
+	}
+}
+
+func TestParseIdentityList(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    map[string]Identity
+		wantErr bool
+	}{
+		{"empty", "", map[string]Identity{}, false},
+		{"single", "tok=op-1:Alice", map[string]Identity{"tok": {"op-1", "Alice"}}, false},
+		{"multiple with spaces", " a=op-a:A , b=op-b:Bee Bee ", map[string]Identity{
+			"a": {"op-a", "A"},
+			"b": {"op-b", "Bee Bee"},
+		}, false},
+		{"colon in name", "a=op-a:A:B", map[string]Identity{"a": {"op-a", "A:B"}}, false},
+		{"missing key", "=op-a:A", nil, true},
+		{"missing name", "a=op-a", nil, true},
+		{"missing account", "a=:A", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseIdentityList(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseIdentityList(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("ParseIdentityList(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+			for k, v := range tt.want {
+				if got[k] != v {
+					t.Errorf("ParseIdentityList(%q)[%q] = %+v, want %+v", tt.input, k, got[k], v)
+				}
+			}
+		})
+	}
+}
+
+func TestNewProviderUnknown(t *testing.T) {
+	if _, err := NewProvider("openplanet,ldap"); err == nil {
+		t.Fatal("NewProvider with unknown name should fail")
+	}
+}
@@ -3,64 +3,131 @@ package auth
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"net"
 	"net/http"
-	"strings"
 	"time"
-
-	"rmpc-server/api/_pkg/config"
 )
 
-var openplanetClient = &http.Client{
-	Timeout: 10 * time.Second,
+type openplanetValidateRequest struct {
+	Token  string `json:"token"`
+	Secret string `json:"secret"`
 }
 
-type OpenplanetUser struct {
-	AccountID   string `json:"account_id"`
-	DisplayName string `json:"display_name"`
+const (
+	openplanetAttempts         = 3
+	openplanetAttemptTimeout   = 3 * time.Second
+	openplanetBackoff          = 250 * time.Millisecond
+	openplanetBreakerThreshold = 5
+	openplanetBreakerCooldown  = 30 * time.Second
+	openplanetCacheTTL         = time.Minute
+)
+
+// errUpstream marks failures worth retrying: timeouts, connection errors and
+// 5xx/429 responses.
+var errUpstream = errors.New("openplanet upstream error")
+
+// OpenplanetProvider validates plugin auth tokens against the Openplanet API.
+// Upstream failures are retried with exponential backoff; repeated failures
+// trip a circuit breaker so logins fail fast with ErrProviderUnavailable
+// instead of each waiting out the timeouts. Successful validations are cached
+// briefly by token hash.
+type OpenplanetProvider struct {
+	url      string
+	secret   string
+	client   *http.Client
+	attempts int
+	backoff  time.Duration
+	breaker  *circuitBreaker
+	cache    *identityCache
 }
 
-type openplanetValidateRequest struct {
-	Token  string `json:"token"`
-	Secret string `json:"secret"`
+func NewOpenplanetProvider(url, secret string) *OpenplanetProvider {
+	return &OpenplanetProvider{
+		url:      url,
+		secret:   secret,
+		client:   &http.Client{Timeout: openplanetAttemptTimeout},
+		attempts: openplanetAttempts,
+		backoff:  openplanetBackoff,
+		breaker:  newCircuitBreaker(openplanetBreakerThreshold, openplanetBreakerCooldown),
+		cache:    newIdentityCache(openplanetCacheTTL),
+	}
 }
 
-func ValidateOpenplanetToken(token string) (*OpenplanetUser, error) {
-	secret := config.Env.OpenplanetPluginSecret
-	if secret == "" {
+func (p *OpenplanetProvider) Validate(token string) (*Identity, error) {
+	if p.secret == "" {
 		return nil, fmt.Errorf("OPENPLANET_PLUGIN_SECRET is not set")
 	}
 
+	tokenHash := HashToken(token)
+	if user, ok := p.cache.Get(tokenHash); ok {
+		return user, nil
+	}
+
+	if !p.breaker.Allow() {
+		return nil, fmt.Errorf("%w: openplanet circuit open", ErrProviderUnavailable)
+	}
+
+	var lastErr error
+	for attempt := 0; attempt < p.attempts; attempt++ {
+		if attempt > 0 {
+			time.Sleep(p.backoff << (attempt - 1))
+		}
+
+		user, err := p.validateOnce(token)
+		if err == nil {
+			p.breaker.Success()
+			p.cache.Put(tokenHash, *user)
+			return user, nil
+		}
+		if !errors.Is(err, errUpstream) {
+			// Openplanet answered, it just didn't like the token
+			p.breaker.Success()
+			return nil, err
+		}
+		lastErr = err
+	}
+
+	p.breaker.Failure()
+	return nil, fmt.Errorf("%w: %w", ErrProviderUnavailable, lastErr)
+}
+
+func (p *OpenplanetProvider) validateOnce(token string) (*Identity, error) {
 	body, err := json.Marshal(openplanetValidateRequest{
 		Token:  token,
-		Secret: secret,
+		Secret: p.secret,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	resp, err := openplanetClient.Post(
-		config.Env.OpenplanetAuthURL,
+	resp, err := p.client.Post(
+		p.url,
 		"application/json",
 		bytes.NewReader(body),
 	)
 	if err != nil {
-		return nil, fmt.Errorf("failed to contact Openplanet API: %w", err)
+		return nil, fmt.Errorf("%w: failed to contact Openplanet API: %w", errUpstream, err)
 	}
 	defer resp.Body.Close()
 
 	respBody, err := io.ReadAll(io.LimitReader(resp.Body, 64*1024)) // 64KB max
 	if err != nil {
-		return nil, fmt.Errorf("failed to read Openplanet response: %w", err)
+		return nil, fmt.Errorf("%w: failed to read Openplanet response: %w", errUpstream, err)
 	}
 
+	if resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests {
+		return nil, fmt.Errorf("%w: openplanet validation failed (status %d): %s", errUpstream, resp.StatusCode, string(respBody))
+	}
+	if resp.StatusCode >= 400 {
+		return nil, fmt.Errorf("%w: openplanet rejected token (status %d): %s", ErrInvalidCredential, resp.StatusCode, string(respBody))
+	}
 	if resp.StatusCode != http.StatusOK {
 		return nil, fmt.Errorf("openplanet validation failed (status %d): %s", resp.StatusCode, string(respBody))
 	}
 
-	var user OpenplanetUser
+	var user Identity
 	if err := json.Unmarshal(respBody, &user); err != nil {
 		return nil, fmt.Errorf("failed to parse Openplanet response: %w", err)
 	}
@@ -71,20 +138,3 @@ func ValidateOpenplanetToken(token string) (*OpenplanetUser, error) {
 
 	return &user, nil
 }
-
-func GetClientIP(r *http.Request) string {
-	// Prefer X-Real-Ip set by trusted reverse proxies (Vercel, nginx)
-	if realIP := r.Header.Get("X-Real-Ip"); realIP != "" {
-		return strings.TrimSpace(realIP)
-	}
-	// Fall back to rightmost X-Forwarded-For entry (appended by the proxy)
-	if xff := r.Header.Get("X-Forwarded-For"); xff != "" {
-		parts := strings.Split(xff, ",")
-		return strings.TrimSpace(parts[len(parts)-1])
-	}
-	host, _, err := net.SplitHostPort(r.RemoteAddr)
-	if err != nil {
-		return r.RemoteAddr
-	}
-	return host
-}
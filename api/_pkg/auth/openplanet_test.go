@@ -0,0 +1,174 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newTestOpenplanet returns a provider pointed at handler with retries that
+// don't sleep.
+func newTestOpenplanet(t *testing.T, handler http.HandlerFunc) (*OpenplanetProvider, *int32) {
+	t.Helper()
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		handler(w, r)
+	}))
+	t.Cleanup(srv.Close)
+
+	p := NewOpenplanetProvider(srv.URL, "secret")
+	p.backoff = 0
+	return p, &calls
+}
+
+func okHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(`{"account_id":"op-alice","display_name":"Alice"}`))
+}
+
+func TestOpenplanetProvider(t *testing.T) {
+	p, _ := newTestOpenplanet(t, okHandler)
+
+	user, err := p.Validate("token")
+	if err != nil {
+		t.Fatalf("Validate() error: %v", err)
+	}
+	if user.AccountID != "op-alice" {
+		t.Fatalf("Validate() = %+v, want op-alice", user)
+	}
+}
+
+func TestOpenplanetProviderRejectedNotRetried(t *testing.T) {
+	p, calls := newTestOpenplanet(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	})
+
+	if _, err := p.Validate("token"); !errors.Is(err, ErrInvalidCredential) {
+		t.Fatalf("Validate() error = %v, want ErrInvalidCredential", err)
+	}
+	if *calls != 1 {
+		t.Fatalf("upstream called %d times, want 1", *calls)
+	}
+}
+
+func TestOpenplanetProviderRetriesServerErrors(t *testing.T) {
+	var n int32
+	p, calls := newTestOpenplanet(t, func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&n, 1) < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		okHandler(w, r)
+	})
+
+	if _, err := p.Validate("token"); err != nil {
+		t.Fatalf("Validate() error: %v", err)
+	}
+	if *calls != 3 {
+		t.Fatalf("upstream called %d times, want 3", *calls)
+	}
+}
+
+func TestOpenplanetProviderUnavailable(t *testing.T) {
+	p, calls := newTestOpenplanet(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+
+	if _, err := p.Validate("token"); !errors.Is(err, ErrProviderUnavailable) {
+		t.Fatalf("Validate() error = %v, want ErrProviderUnavailable", err)
+	}
+	if *calls != openplanetAttempts {
+		t.Fatalf("upstream called %d times, want %d", *calls, openplanetAttempts)
+	}
+}
+
+func TestOpenplanetProviderCircuitOpens(t *testing.T) {
+	p, calls := newTestOpenplanet(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	for i := 0; i < openplanetBreakerThreshold; i++ {
+		p.Validate("token")
+	}
+	before := atomic.LoadInt32(calls)
+
+	if _, err := p.Validate("token"); !errors.Is(err, ErrProviderUnavailable) {
+		t.Fatalf("Validate() error = %v, want ErrProviderUnavailable", err)
+	}
+	if after := atomic.LoadInt32(calls); after != before {
+		t.Fatalf("upstream called while circuit open (%d -> %d)", before, after)
+	}
+}
+
+func TestOpenplanetProviderCachesSuccess(t *testing.T) {
+	p, calls := newTestOpenplanet(t, okHandler)
+
+	for i := 0; i < 5; i++ {
+		if _, err := p.Validate("token"); err != nil {
+			t.Fatalf("Validate() error: %v", err)
+		}
+	}
+	if *calls != 1 {
+		t.Fatalf("upstream called %d times, want 1", *calls)
+	}
+
+	if _, err := p.Validate("other-token"); err != nil {
+		t.Fatalf("Validate() error: %v", err)
+	}
+	if *calls != 2 {
+		t.Fatalf("upstream called %d times for a new token, want 2", *calls)
+	}
+}
+
+func TestCircuitBreakerHalfOpen(t *testing.T) {
+	b := newCircuitBreaker(2, 20*time.Millisecond)
+
+	b.Failure()
+	if !b.Allow() {
+		t.Fatal("breaker should stay closed below threshold")
+	}
+	b.Failure()
+	if b.Allow() {
+		t.Fatal("breaker should open at threshold")
+	}
+
+	time.Sleep(30 * time.Millisecond)
+
+	if !b.Allow() {
+		t.Fatal("breaker should allow a trial call after cooldown")
+	}
+	if b.Allow() {
+		t.Fatal("breaker should allow only one trial call")
+	}
+
+	b.Failure()
+	if b.Allow() {
+		t.Fatal("failed trial should re-open the breaker")
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	b.Allow()
+	b.Success()
+	if !b.Allow() || !b.Allow() {
+		t.Fatal("successful trial should close the breaker")
+	}
+}
+
+func TestIdentityCacheExpiry(t *testing.T) {
+	c := newIdentityCache(20 * time.Millisecond)
+	c.Put("h", Identity{AccountID: "op-a", DisplayName: "A"})
+
+	if _, ok := c.Get("h"); !ok {
+		t.Fatal("entry should be cached")
+	}
+
+	time.Sleep(30 * time.Millisecond)
+
+	if _, ok := c.Get("h"); ok {
+		t.Fatal("entry should have expired")
+	}
+}
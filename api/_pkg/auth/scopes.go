@@ -0,0 +1,159 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"rmpc-server/api/_pkg/db"
+	"rmpc-server/api/_pkg/response"
+)
+
+// API key scopes. Each privileged endpoint requires exactly one of these.
+const (
+	ScopeScoresModerate = "scores:moderate"
+	ScopePlayersRead    = "players:read"
+	ScopePlayersBan     = "players:ban"
+	ScopeSessionsRevoke = "sessions:revoke"
+	ScopeMetricsRead    = "metrics:read"
+	ScopeAuditRead      = "audit:read"
+)
+
+// roleScopes lists what each role may grant to its API keys. A key's
+// effective scopes are re-checked against its owner's role on every request,
+// so demoting a player disables their keys' privileges immediately; banning
+// them disables their keys altogether.
+var roleScopes = map[string][]string{
+	"moderator": {ScopeScoresModerate, ScopePlayersRead, ScopePlayersBan, ScopeSessionsRevoke},
+	"admin":     {ScopeScoresModerate, ScopePlayersRead, ScopePlayersBan, ScopeSessionsRevoke, ScopeMetricsRead, ScopeAuditRead},
+}
+
+// APIKeyPrefix tells API keys apart from session tokens at a glance.
+const APIKeyPrefix = "rmpc_ak_"
+
+// apiKeyTouchInterval limits how often last_used_at is written for a key.
+const apiKeyTouchInterval = 5 * time.Minute
+
+// RoleAllows reports whether a player with role may hold scope.
+func RoleAllows(role, scope string) bool {
+	return slices.Contains(roleScopes[role], scope)
+}
+
+// ParseScopes validates a space-separated scope list against role and returns
+// it normalized (sorted, deduplicated).
+func ParseScopes(role, s string) ([]string, error) {
+	scopes := strings.Fields(s)
+	if len(scopes) == 0 {
+		return nil, fmt.Errorf("at least one scope is required")
+	}
+	for _, scope := range scopes {
+		if !RoleAllows(role, scope) {
+			return nil, fmt.Errorf("scope %q is not allowed for role %q", scope, role)
+		}
+	}
+	slices.Sort(scopes)
+	return slices.Compact(scopes), nil
+}
+
+func GenerateAPIKey() (plaintext string, hash string, err error) {
+	token, _, err := GenerateSessionToken()
+	if err != nil {
+		return "", "", err
+	}
+	plaintext = APIKeyPrefix + token
+	return plaintext, HashToken(plaintext), nil
+}
+
+// Principal is the caller behind a privileged request.
+type Principal struct {
+	PlayerID uuid.UUID
+	KeyID    uuid.UUID
+	Role     string
+	Scopes   []string
+}
+
+func (p *Principal) HasScope(scope string) bool {
+	return slices.Contains(p.Scopes, scope)
+}
+
+type ScopedHandler func(w http.ResponseWriter, r *http.Request, principal *Principal)
+
+// RequireScope authenticates the request with an API key and rejects it with
+// 403 unless the key holds scope.
+func RequireScope(scope string, next ScopedHandler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		principal, err := AuthenticateAPIKey(r)
+		if err != nil {
+			if errors.Is(err, errInternal) {
+				response.Error(w, http.StatusServiceUnavailable, "service unavailable")
+			} else {
+				response.Error(w, http.StatusUnauthorized, "unauthorized")
+			}
+			return
+		}
+		if !principal.HasScope(scope) {
+			response.Error(w, http.StatusForbidden, "forbidden")
+			return
+		}
+		next(w, r, principal)
+	}
+}
+
+func AuthenticateAPIKey(r *http.Request) (*Principal, error) {
+	token, err := BearerToken(r)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(token, APIKeyPrefix) {
+		return nil, fmt.Errorf("not an API key")
+	}
+
+	database, err := db.GetDB()
+	if err != nil {
+		slog.Error("database connection error", "error", err)
+		return nil, errInternal
+	}
+
+	key, err := db.FindAPIKeyByHash(database, HashToken(token))
+	if err != nil {
+		slog.Error("api key lookup error", "error", err)
+		return nil, errInternal
+	}
+	if key == nil {
+		return nil, fmt.Errorf("invalid API key")
+	}
+	if key.RevokedAt != nil {
+		return nil, fmt.Errorf("API key has been revoked")
+	}
+	if key.ExpiresAt != nil && time.Now().After(*key.ExpiresAt) {
+		return nil, fmt.Errorf("API key has expired")
+	}
+	if key.OwnerBanned {
+		return nil, fmt.Errorf("API key owner is banned")
+	}
+
+	role := key.OwnerRole.String()
+	var scopes []string
+	for _, scope := range strings.Fields(key.Scopes) {
+		if RoleAllows(role, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+
+	if err := db.TouchAPIKey(database, key.ID, apiKeyTouchInterval); err != nil {
+		slog.Error("api key touch error", "error", err)
+	}
+
+	return &Principal{
+		PlayerID: key.PlayerID,
+		KeyID:    key.ID,
+		Role:     role,
+		Scopes:   scopes,
+	}, nil
+}
@@ -0,0 +1,61 @@
+package auth
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestRoleAllows(t *testing.T) {
+	tests := []struct {
+		role  string
+		scope string
+		want  bool
+	}{
+		{"admin", ScopeMetricsRead, true},
+		{"admin", ScopePlayersBan, true},
+		{"moderator", ScopePlayersBan, true},
+		{"moderator", ScopeMetricsRead, false},
+		{"moderator", ScopeAuditRead, false},
+		{"admin", ScopeAuditRead, true},
+		{"player", ScopeScoresModerate, false},
+		{"admin", "everything:all", false},
+	}
+
+	for _, tt := range tests {
+		if got := RoleAllows(tt.role, tt.scope); got != tt.want {
+			t.Errorf("RoleAllows(%q, %q) = %v, want %v", tt.role, tt.scope, got, tt.want)
+		}
+	}
+}
+
+func TestParseScopes(t *testing.T) {
+	got, err := ParseScopes("moderator", "players:ban scores:moderate players:ban")
+	if err != nil {
+		t.Fatalf("ParseScopes() error: %v", err)
+	}
+	want := []string{ScopePlayersBan, ScopeScoresModerate}
+	if !slices.Equal(got, want) {
+		t.Fatalf("ParseScopes() = %v, want %v", got, want)
+	}
+
+	if _, err := ParseScopes("moderator", "metrics:read"); err == nil {
+		t.Fatal("moderator should not be able to grant metrics:read")
+	}
+	if _, err := ParseScopes("admin", "  "); err == nil {
+		t.Fatal("empty scope list should be rejected")
+	}
+}
+
+func TestGenerateAPIKey(t *testing.T) {
+	plaintext, hash, err := GenerateAPIKey()
+	if err != nil {
+		t.Fatalf("GenerateAPIKey() error: %v", err)
+	}
+	if !strings.HasPrefix(plaintext, APIKeyPrefix) {
+		t.Fatalf("key %q lacks prefix %q", plaintext, APIKeyPrefix)
+	}
+	if HashToken(plaintext) != hash {
+		t.Fatal("hash should match HashToken(plaintext)")
+	}
+}
@@ -14,17 +14,31 @@ import (
 
 	"github.com/google/uuid"
 
+	"rmpc-server/api/_pkg/config"
 	"rmpc-server/api/_pkg/db"
 	"rmpc-server/api/_pkg/response"
 )
 
 var errInternal = errors.New("internal error")
 
+// sessionTouchInterval limits how often last_used_at is written for a session.
+const sessionTouchInterval = 5 * time.Minute
+
 type AuthenticatedHandler func(w http.ResponseWriter, r *http.Request, playerID uuid.UUID)
 
+// SessionHandler is like AuthenticatedHandler but receives the full session,
+// for endpoints that manage sessions themselves.
+type SessionHandler func(w http.ResponseWriter, r *http.Request, session *db.Session)
+
 func RequireAuth(next AuthenticatedHandler) http.HandlerFunc {
+	return RequireSession(func(w http.ResponseWriter, r *http.Request, session *db.Session) {
+		next(w, r, session.PlayerID)
+	})
+}
+
+func RequireSession(next SessionHandler) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		playerID, err := AuthenticateRequest(r)
+		session, err := AuthenticateRequest(r)
 		if err != nil {
 			if errors.Is(err, errInternal) {
 				response.Error(w, http.StatusServiceUnavailable, "service unavailable")
@@ -33,7 +47,7 @@ func RequireAuth(next AuthenticatedHandler) http.HandlerFunc {
 			}
 			return
 		}
-		next(w, r, playerID)
+		next(w, r, session)
 	}
 }
 
@@ -52,19 +66,28 @@ func HashToken(plaintext string) string {
 	return hex.EncodeToString(h[:])
 }
 
-func AuthenticateRequest(r *http.Request) (uuid.UUID, error) {
+// BearerToken extracts the token from an "Authorization: Bearer <token>" header.
+func BearerToken(r *http.Request) (string, error) {
 	authHeader := r.Header.Get("Authorization")
 	if authHeader == "" {
-		return uuid.Nil, fmt.Errorf("missing Authorization header")
+		return "", fmt.Errorf("missing Authorization header")
 	}
 
 	if !strings.HasPrefix(authHeader, "Bearer ") {
-		return uuid.Nil, fmt.Errorf("invalid Authorization header format")
+		return "", fmt.Errorf("invalid Authorization header format")
 	}
 
 	token := strings.TrimPrefix(authHeader, "Bearer ")
 	if token == "" {
-		return uuid.Nil, fmt.Errorf("empty bearer token")
+		return "", fmt.Errorf("empty bearer token")
+	}
+	return token, nil
+}
+
+func AuthenticateRequest(r *http.Request) (*db.Session, error) {
+	token, err := BearerToken(r)
+	if err != nil {
+		return nil, err
 	}
 
 	tokenHash := HashToken(token)
@@ -72,21 +95,47 @@ func AuthenticateRequest(r *http.Request) (uuid.UUID, error) {
 	database, err := db.GetDB()
 	if err != nil {
 		slog.Error("database connection error", "error", err)
-		return uuid.Nil, errInternal
+		return nil, errInternal
 	}
 
 	session, err := db.FindSessionByTokenHash(database, tokenHash)
 	if err != nil {
 		slog.Error("session lookup error", "error", err)
-		return uuid.Nil, errInternal
+		return nil, errInternal
 	}
 	if session == nil {
-		return uuid.Nil, fmt.Errorf("invalid session token")
+		return nil, fmt.Errorf("invalid session token")
 	}
 
 	if time.Now().After(session.ExpiresAt) {
-		return uuid.Nil, fmt.Errorf("session token has expired")
+		return nil, fmt.Errorf("session token has expired")
 	}
 
-	return session.PlayerID, nil
+	// Best effort: a failed touch only makes the session list less accurate
+	// and delays the sliding expiry until the next request.
+	var slideTo *time.Time
+	if config.Env.SessionSlidingExpiry {
+		next := SessionExpiry(session.CreatedAt, time.Now())
+		slideTo = &next
+	}
+	if err := db.TouchSession(database, session.ID, sessionTouchInterval, slideTo); err != nil {
+		slog.Error("session touch error", "error", err)
+	}
+
+	return session, nil
+}
+
+// SessionExpiry returns when a session created at createdAt should expire if
+// (re)issued at now: SESSION_TOKEN_EXPIRY from now, but never past
+// SESSION_MAX_LIFETIME from creation.
+func SessionExpiry(createdAt, now time.Time) time.Time {
+	return capExpiry(createdAt, now, config.Env.SessionTokenExpiry, config.Env.SessionMaxLifetime)
+}
+
+func capExpiry(createdAt, now time.Time, ttl, maxLifetime time.Duration) time.Time {
+	expiresAt := now.Add(ttl)
+	if hardLimit := createdAt.Add(maxLifetime); expiresAt.After(hardLimit) {
+		return hardLimit
+	}
+	return expiresAt
 }
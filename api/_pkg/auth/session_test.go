@@ -2,6 +2,7 @@ package auth
 
 import (
 	"testing"
+	"time"
 )
 
 func TestGenerateSessionToken(t *testing.T) {
@@ -65,3 +66,29 @@ func TestHashTokenRoundtrip(t *testing.T) {
 		t.Fatalf("hash mismatch: HashToken(plaintext) = %q, expected %q", actualHash, expectedHash)
 	}
 }
+
+func TestCapExpiry(t *testing.T) {
+	created := time.Date(2025, time.November, 1, 0, 0, 0, 0, time.UTC)
+	ttl := 30 * 24 * time.Hour
+	maxLifetime := 90 * 24 * time.Hour
+
+	tests := []struct {
+		name string
+		now  time.Time
+		want time.Time
+	}{
+		{"fresh session", created, created.Add(ttl)},
+		{"slides forward", created.Add(10 * 24 * time.Hour), created.Add(40 * 24 * time.Hour)},
+		{"capped near max lifetime", created.Add(80 * 24 * time.Hour), created.Add(maxLifetime)},
+		{"capped past max lifetime", created.Add(100 * 24 * time.Hour), created.Add(maxLifetime)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := capExpiry(created, tt.now, ttl, maxLifetime)
+			if !got.Equal(tt.want) {
+				t.Errorf("capExpiry(now=%v) = %v, want %v", tt.now, got, tt.want)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package config
 
 import (
 	"os"
+	"strconv"
 	"time"
 )
 
@@ -17,15 +18,121 @@ var Env struct {
 	// OPENPLANET_AUTH_URL - Openplanet auth endpoint
 	OpenplanetAuthURL string
 
+	// IDENTITY_PROVIDER - comma-separated identity providers tried in order:
+	// "openplanet", "static" (fixture tokens for dev/tests), "apikey" (bots)
+	IdentityProvider string
+
+	// IDENTITY_STATIC_USERS - static provider users as "token=account_id:name,..."
+	IdentityStaticUsers string
+
+	// IDENTITY_API_KEYS - API key provider users as "sha256(key)=account_id:name,..."
+	IdentityAPIKeys string
+
 	// SESSION_TOKEN_EXPIRY - session lifetime as Go duration, e.g. "720h"
 	SessionTokenExpiry time.Duration
 
+	// SESSION_SLIDING_EXPIRY - "true" to push a session's expiry forward on use
+	SessionSlidingExpiry bool
+
+	// SESSION_MAX_LIFETIME - hard cap on a session's age regardless of sliding
+	// expiry or token rotation, e.g. "2160h"
+	SessionMaxLifetime time.Duration
+
+	// MAX_SESSIONS_PER_PLAYER - concurrent sessions kept per player; the least
+	// recently used ones are dropped when a new session exceeds the limit
+	MaxSessionsPerPlayer int
+
 	// SCORE_COOLDOWN - minimum time between score submissions per player, e.g. "1m"
 	ScoreCooldown time.Duration
 
+	// REQUIRE_RUN - "true" to reject score submissions without a run_id from
+	// POST /api/runs/start. Batch uploads can't carry one, so this turns them
+	// away too.
+	RequireRun bool
+
+	// RUN_MAX_AGE - how long after POST /api/runs/start a run can still be
+	// submitted, e.g. "3h"
+	RunMaxAge time.Duration
+
+	// MAX_OPEN_RUNS - unfinished runs kept per player; starting another drops
+	// the oldest, which can then no longer be submitted
+	MaxOpenRuns int
+
+	// RUN_DURATION_TOLERANCE - allowed difference between a run's reported
+	// duration_ms and the server's wall-clock time since the run started
+	RunDurationTolerance time.Duration
+
+	// MAP_RESULTS_SCORE_CHECK - "true" to also hold runs whose score differs
+	// from the sum of the target times of the maps they completed. Off until
+	// that is confirmed to be how the plugin scores; maps completed and
+	// skipped are always compared
+	MapResultsScoreCheck bool
+
+	// IDEMPOTENCY_KEY_TTL - how long a score submission's Idempotency-Key is
+	// remembered and its response replayed, e.g. "24h"
+	IdempotencyKeyTTL time.Duration
+
+	// IDEMPOTENCY_RESERVATION_TIMEOUT - how long a submission may hold its
+	// Idempotency-Key without answering before a retry takes the key over;
+	// at least the function timeout, e.g. "60s"
+	IdempotencyReservationTimeout time.Duration
+
+	// BATCH_MAX_RUNS - most runs accepted in one POST /api/scores/batch
+	BatchMaxRuns int
+
+	// BATCH_MAX_AGE - how long before the upload a batched run may have
+	// finished, e.g. "72h"
+	BatchMaxAge time.Duration
+
+	// BATCH_PLAYER_DAILY_LIMIT - most batched runs one player may upload in
+	// 24 hours, across all of their sessions
+	BatchPlayerDailyLimit int
+
+	// BLOB_STORAGE_DIR - writable, durable directory where uploaded files such
+	// as score proofs are kept (required for proofs). Not a function's local
+	// disk: on Vercel that is read-only and discarded between invocations
+	BlobStorageDir string
+
+	// PROOF_MAX_BYTES - largest proof file accepted, in bytes
+	ProofMaxBytes int
+
+	// PROOF_CONTENT_TYPES - comma-separated media types proof files may have
+	ProofContentTypes string
+
+	// PROOF_REQUIRED_TOP_N - hold runs that would place in the top N of their
+	// leaderboard off the boards until a proof is attached and a moderator has
+	// checked it; unset disables it, and so does a missing or unwritable
+	// BLOB_STORAGE_DIR
+	ProofRequiredTopN int
+
 	// AUTH_RATE_LIMIT - max auth requests per IP per minute
 	AuthRateLimit int
 
+	// TRUSTED_PROXIES - comma-separated CIDRs of reverse proxies whose
+	// X-Forwarded-For/X-Real-Ip headers are honored, or a preset: "vercel"
+	// (trust the headers unconditionally) or "none". Unset means "vercel" on
+	// Vercel, where the platform sets VERCEL=1, and trusts nobody elsewhere
+	TrustedProxies string
+
+	// RANK_TIE_BREAKERS - comma-separated order in which runs with equal scores
+	// are ranked: "fewest_skips", "shortest_duration", "earliest_submission",
+	// or "none"
+	RankTieBreakers string
+
+	// FEWEST_SKIPS_MIN_SCORE - score a run needs to count on rank_by=fewest_skips
+	// leaderboards when ?min_score isn't given. Like scores, in milliseconds of
+	// medal time: the default of 10 minutes is some 10 to 15 maps
+	FewestSkipsMinScore int
+
+	// LEADERBOARD_PAGE_SIZE - leaderboard entries per page when ?limit isn't given
+	LeaderboardPageSize int
+
+	// LEADERBOARD_MAX_PAGE_SIZE - largest ?limit a leaderboard page may ask for
+	LeaderboardMaxPageSize int
+
+	// LEADERBOARD_MAX_RANGE_DAYS - longest custom from/to leaderboard range, in days
+	LeaderboardMaxRangeDays int
+
 	// LEADERBOARD_CACHE_TTL - how long Vercel edge may cache leaderboard responses, e.g. "5m"
 	LeaderboardCacheTTL time.Duration
 
@@ -49,10 +156,36 @@ func init() {
 	Env.DatabaseURL = os.Getenv("DATABASE_URL")
 	Env.OpenplanetPluginSecret = os.Getenv("OPENPLANET_PLUGIN_SECRET")
 	Env.OpenplanetAuthURL = stringEnv("OPENPLANET_AUTH_URL", "https://openplanet.dev/api/auth/validate")
+	Env.IdentityProvider = stringEnv("IDENTITY_PROVIDER", "openplanet")
+	Env.IdentityStaticUsers = os.Getenv("IDENTITY_STATIC_USERS")
+	Env.IdentityAPIKeys = os.Getenv("IDENTITY_API_KEYS")
 	Env.SessionTokenExpiry = durationEnv("SESSION_TOKEN_EXPIRY", 30*24*time.Hour)
+	Env.SessionSlidingExpiry = boolEnv("SESSION_SLIDING_EXPIRY", false)
+	Env.SessionMaxLifetime = durationEnv("SESSION_MAX_LIFETIME", 90*24*time.Hour)
+	Env.MaxSessionsPerPlayer = intEnv("MAX_SESSIONS_PER_PLAYER", 10)
 	Env.ScoreCooldown = durationEnv("SCORE_COOLDOWN", 10*time.Minute)
+	Env.RequireRun = boolEnv("REQUIRE_RUN", false)
+	Env.RunMaxAge = durationEnv("RUN_MAX_AGE", 3*time.Hour)
+	Env.MaxOpenRuns = intEnv("MAX_OPEN_RUNS", 5)
+	Env.RunDurationTolerance = durationEnv("RUN_DURATION_TOLERANCE", 2*time.Minute)
+	Env.MapResultsScoreCheck = boolEnv("MAP_RESULTS_SCORE_CHECK", false)
+	Env.IdempotencyKeyTTL = durationEnv("IDEMPOTENCY_KEY_TTL", 24*time.Hour)
+	Env.IdempotencyReservationTimeout = durationEnv("IDEMPOTENCY_RESERVATION_TIMEOUT", 60*time.Second)
+	Env.BatchMaxRuns = intEnv("BATCH_MAX_RUNS", 20)
+	Env.BatchMaxAge = durationEnv("BATCH_MAX_AGE", 72*time.Hour)
+	Env.BatchPlayerDailyLimit = intEnv("BATCH_PLAYER_DAILY_LIMIT", 50)
+	Env.BlobStorageDir = os.Getenv("BLOB_STORAGE_DIR")
+	Env.ProofMaxBytes = intEnv("PROOF_MAX_BYTES", 20*1024*1024)
+	Env.ProofContentTypes = stringEnv("PROOF_CONTENT_TYPES", "application/octet-stream,text/plain,application/json,application/zip")
+	Env.ProofRequiredTopN = intEnv("PROOF_REQUIRED_TOP_N", 0)
 	Env.AuthRateLimit = 10
+	Env.TrustedProxies = stringEnv("TRUSTED_PROXIES", defaultTrustedProxies())
 	Env.ActivityCacheTTL = durationEnv("ACTIVITY_CACHE_TTL", 4*time.Hour)
+	Env.RankTieBreakers = stringEnv("RANK_TIE_BREAKERS", "fewest_skips,shortest_duration,earliest_submission")
+	Env.FewestSkipsMinScore = intEnv("FEWEST_SKIPS_MIN_SCORE", 600000)
+	Env.LeaderboardPageSize = intEnv("LEADERBOARD_PAGE_SIZE", 50)
+	Env.LeaderboardMaxPageSize = intEnv("LEADERBOARD_MAX_PAGE_SIZE", 200)
+	Env.LeaderboardMaxRangeDays = intEnv("LEADERBOARD_MAX_RANGE_DAYS", 366)
 	Env.LeaderboardCacheTTL = durationEnv("LEADERBOARD_CACHE_TTL", 15*time.Minute)
 	Env.WorldRecordsCacheTTL = durationEnv("WORLDRECORDS_CACHE_TTL", 60*time.Minute)
 	Env.HallOfFameCacheTTL = durationEnv("HALLOFFAME_CACHE_TTL", 6*time.Hour)
@@ -60,6 +193,15 @@ func init() {
 	Env.PlayerCacheTTL = durationEnv("PLAYER_CACHE_TTL", 15*time.Minute)
 }
 
+// defaultTrustedProxies trusts Vercel's edge only when running on it; its
+// functions are never reachable except through it.
+func defaultTrustedProxies() string {
+	if os.Getenv("VERCEL") == "1" {
+		return "vercel"
+	}
+	return ""
+}
+
 func stringEnv(key, fallback string) string {
 	if v := os.Getenv(key); v != "" {
 		return v
@@ -67,6 +209,18 @@ func stringEnv(key, fallback string) string {
 	return fallback
 }
 
+func boolEnv(key string, fallback bool) bool {
+	v := os.Getenv(key)
+	if v == "" {
+		return fallback
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return fallback
+	}
+	return b
+}
+
 func durationEnv(key string, fallback time.Duration) time.Duration {
 	v := os.Getenv(key)
 	if v == "" {
@@ -78,3 +232,15 @@ func durationEnv(key string, fallback time.Duration) time.Duration {
 	}
 	return d
 }
+
+func intEnv(key string, fallback int) int {
+	v := os.Getenv(key)
+	if v == "" {
+		return fallback
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n <= 0 {
+		return fallback
+	}
+	return n
+}
@@ -0,0 +1,23 @@
+package config
+
+import "testing"
+
+func TestDefaultTrustedProxies(t *testing.T) {
+	tests := []struct {
+		vercel string
+		want   string
+	}{
+		{"1", "vercel"},
+		{"", ""},
+		{"0", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run("VERCEL="+tt.vercel, func(t *testing.T) {
+			t.Setenv("VERCEL", tt.vercel)
+			if got := defaultTrustedProxies(); got != tt.want {
+				t.Errorf("defaultTrustedProxies() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
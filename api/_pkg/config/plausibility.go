@@ -0,0 +1,54 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// PlausibilityRules are loaded from config/plausibility.yaml. Zero values
+// disable a rule.
+type PlausibilityRules struct {
+	MaxMapsPerMinute    float64                      `yaml:"max_maps_per_minute"`
+	MaxSkipRatio        float64                      `yaml:"max_skip_ratio"`
+	MinMapsForSkipRatio int32                        `yaml:"min_maps_for_skip_ratio"`
+	GameModes           map[string]ScorePerMapBounds `yaml:"game_modes"`
+}
+
+type ScorePerMapBounds struct {
+	MinMs int32 `yaml:"min_score_per_map_ms"`
+	MaxMs int32 `yaml:"max_score_per_map_ms"`
+}
+
+var (
+	plausibilityRules *PlausibilityRules
+	plausibilityOnce  sync.Once
+	plausibilityErr   error
+)
+
+func loadPlausibility() {
+	_, filename, _, _ := runtime.Caller(0)
+	configPath := filepath.Join(filepath.Dir(filename), "..", "..", "..", "config", "plausibility.yaml")
+
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		plausibilityErr = err
+		return
+	}
+
+	var rules PlausibilityRules
+	if err := yaml.Unmarshal(data, &rules); err != nil {
+		plausibilityErr = err
+		return
+	}
+	plausibilityRules = &rules
+}
+
+// Plausibility returns the plausibility rules.
+func Plausibility() (*PlausibilityRules, error) {
+	plausibilityOnce.Do(loadPlausibility)
+	return plausibilityRules, plausibilityErr
+}
@@ -0,0 +1,25 @@
+package config
+
+import "testing"
+
+func TestPlausibility(t *testing.T) {
+	// Depends on loading config/plausibility.yaml relative to this file
+	rules, err := Plausibility()
+	if err != nil {
+		t.Fatalf("Plausibility() error = %v", err)
+	}
+
+	if rules.MaxMapsPerMinute <= 0 {
+		t.Errorf("MaxMapsPerMinute = %v, want > 0", rules.MaxMapsPerMinute)
+	}
+	for _, mode := range []string{"author", "gold"} {
+		bounds, ok := rules.GameModes[mode]
+		if !ok {
+			t.Errorf("no score-per-map bounds for %q", mode)
+			continue
+		}
+		if bounds.MinMs <= 0 || bounds.MaxMs <= bounds.MinMs {
+			t.Errorf("bounds for %q = %+v, want 0 < min < max", mode, bounds)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// PluginRules are loaded from config/plugin.yaml.
+type PluginRules struct {
+	MinVersion      string    `yaml:"min_version"`
+	BlockedVersions []string  `yaml:"blocked_versions"`
+	Rulesets        []Ruleset `yaml:"rulesets"`
+}
+
+// Ruleset is an era of scoring rules, starting with a plugin version.
+type Ruleset struct {
+	ID         int16  `yaml:"id"`
+	MinVersion string `yaml:"min_version"`
+}
+
+// CurrentRuleset is the newest ruleset, or 1 if none are configured.
+func (r *PluginRules) CurrentRuleset() int16 {
+	if len(r.Rulesets) == 0 {
+		return 1
+	}
+	return r.Rulesets[len(r.Rulesets)-1].ID
+}
+
+var (
+	pluginRules *PluginRules
+	pluginOnce  sync.Once
+	pluginErr   error
+)
+
+func loadPlugin() {
+	_, filename, _, _ := runtime.Caller(0)
+	configPath := filepath.Join(filepath.Dir(filename), "..", "..", "..", "config", "plugin.yaml")
+
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		pluginErr = err
+		return
+	}
+
+	var rules PluginRules
+	if err := yaml.Unmarshal(data, &rules); err != nil {
+		pluginErr = err
+		return
+	}
+	pluginRules = &rules
+}
+
+// Plugin returns the plugin version rules.
+func Plugin() (*PluginRules, error) {
+	pluginOnce.Do(loadPlugin)
+	return pluginRules, pluginErr
+}
@@ -24,9 +24,9 @@ func GetMedalActivity(db *sql.DB, days int) (map[string]int64, error) {
 		SUM(table.Scores.MapsCompleted).AS("medals.total"),
 	).FROM(
 		table.Scores.
-			LEFT_JOIN(table.BannedPlayers, table.BannedPlayers.PlayerID.EQ(table.Scores.PlayerID)),
+			LEFT_JOIN(table.BannedPlayers, activeBanOf(table.Scores.PlayerID)),
 	).WHERE(AND(
-		table.BannedPlayers.ID.IS_NULL(),
+		visibleScore(),
 		table.Scores.CreatedAt.GT_EQ(NOW().SUB(INTERVAL(float64(days), DAY))),
 	)).GROUP_BY(
 		bucket,
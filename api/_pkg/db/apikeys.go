@@ -0,0 +1,140 @@
+package db
+
+import (
+	"database/sql"
+	"time"
+
+	. "github.com/go-jet/jet/v2/postgres"
+	"github.com/google/uuid"
+
+	"rmpc-server/db/.gen/rmpc/public/model"
+	"rmpc-server/db/.gen/rmpc/public/table"
+)
+
+type APIKeyInput struct {
+	PlayerID  uuid.UUID
+	Name      string
+	KeyHash   string
+	Scopes    string
+	ExpiresAt *time.Time
+}
+
+func CreateAPIKey(db *sql.DB, input APIKeyInput) (uuid.UUID, time.Time, error) {
+	stmt := table.APIKeys.INSERT(
+		table.APIKeys.PlayerID,
+		table.APIKeys.Name,
+		table.APIKeys.KeyHash,
+		table.APIKeys.Scopes,
+		table.APIKeys.ExpiresAt,
+	).VALUES(
+		input.PlayerID,
+		input.Name,
+		input.KeyHash,
+		input.Scopes,
+		input.ExpiresAt,
+	).RETURNING(
+		table.APIKeys.ID,
+		table.APIKeys.CreatedAt,
+	)
+
+	var dest model.APIKeys
+	if err := stmt.Query(db, &dest); err != nil {
+		return uuid.Nil, time.Time{}, err
+	}
+
+	createdAt := time.Time{}
+	if dest.CreatedAt != nil {
+		createdAt = *dest.CreatedAt
+	}
+	return dest.ID, createdAt, nil
+}
+
+// APIKey is a key together with its owner's current role, which bounds the
+// scopes the key can actually exercise, and whether the owner is banned.
+type APIKey struct {
+	model.APIKeys
+	OwnerRole   model.PlayerRole `alias:"players.role"`
+	OwnerBanned bool             `alias:"players.banned"`
+}
+
+// FindAPIKeyByHash returns the key matching hash, including revoked and
+// expired ones; callers decide what to accept. Returns (nil, nil) when no key
+// matches.
+func FindAPIKeyByHash(db *sql.DB, keyHash string) (*APIKey, error) {
+	stmt := SELECT(
+		table.APIKeys.AllColumns,
+		table.Players.Role,
+		table.BannedPlayers.ID.IS_NOT_NULL().AS("players.banned"),
+	).FROM(
+		table.APIKeys.
+			INNER_JOIN(table.Players, table.Players.ID.EQ(table.APIKeys.PlayerID)).
+			LEFT_JOIN(table.BannedPlayers, activeBanOf(table.APIKeys.PlayerID)),
+	).WHERE(
+		table.APIKeys.KeyHash.EQ(String(keyHash)),
+	).LIMIT(1)
+
+	var dest APIKey
+	err := stmt.Query(db, &dest)
+	if err != nil {
+		if err.Error() == "jet: sql: no rows in result set" {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &dest, nil
+}
+
+// ListAPIKeys returns the player's unrevoked keys, newest first.
+func ListAPIKeys(db *sql.DB, playerID uuid.UUID) ([]model.APIKeys, error) {
+	stmt := SELECT(
+		table.APIKeys.AllColumns,
+	).FROM(
+		table.APIKeys,
+	).WHERE(
+		table.APIKeys.PlayerID.EQ(UUID(playerID)).
+			AND(table.APIKeys.RevokedAt.IS_NULL()),
+	).ORDER_BY(
+		table.APIKeys.CreatedAt.DESC(),
+	)
+
+	keys := []model.APIKeys{}
+	if err := stmt.Query(db, &keys); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// RevokeAPIKey marks one of the player's keys as revoked. Reports whether an
+// active key was found.
+func RevokeAPIKey(db *sql.DB, playerID, keyID uuid.UUID) (bool, error) {
+	stmt := table.APIKeys.UPDATE().SET(
+		table.APIKeys.RevokedAt.SET(TimestampzExpression(NOW())),
+	).WHERE(
+		table.APIKeys.ID.EQ(UUID(keyID)).
+			AND(table.APIKeys.PlayerID.EQ(UUID(playerID))).
+			AND(table.APIKeys.RevokedAt.IS_NULL()),
+	)
+
+	res, err := stmt.Exec(db)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// TouchAPIKey bumps last_used_at at most once per interval.
+func TouchAPIKey(db *sql.DB, keyID uuid.UUID, interval time.Duration) error {
+	stmt := table.APIKeys.UPDATE().SET(
+		table.APIKeys.LastUsedAt.SET(TimestampzExpression(NOW())),
+	).WHERE(
+		table.APIKeys.ID.EQ(UUID(keyID)).AND(
+			table.APIKeys.LastUsedAt.IS_NULL().OR(
+				table.APIKeys.LastUsedAt.LT(TimestampzExpression(NOW().SUB(INTERVALd(interval)))),
+			),
+		),
+	)
+
+	_, err := stmt.Exec(db)
+	return err
+}
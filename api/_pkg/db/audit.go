@@ -0,0 +1,84 @@
+package db
+
+import (
+	"database/sql"
+	"time"
+
+	. "github.com/go-jet/jet/v2/postgres"
+	"github.com/google/uuid"
+
+	"rmpc-server/db/.gen/rmpc/public/model"
+	"rmpc-server/db/.gen/rmpc/public/table"
+)
+
+type AuditInput struct {
+	ActorID    *uuid.UUID
+	Action     string
+	TargetType string
+	TargetID   *string
+	IP         *string
+	Details    *string // JSON object
+}
+
+func InsertAuditEntry(db *sql.DB, input AuditInput) error {
+	stmt := table.AuditLog.INSERT(
+		table.AuditLog.ActorID,
+		table.AuditLog.Action,
+		table.AuditLog.TargetType,
+		table.AuditLog.TargetID,
+		table.AuditLog.IP,
+		table.AuditLog.Details,
+	).VALUES(
+		input.ActorID,
+		input.Action,
+		input.TargetType,
+		input.TargetID,
+		input.IP,
+		input.Details,
+	)
+
+	_, err := stmt.Exec(db)
+	return err
+}
+
+// AuditFilter narrows ListAuditEntries. Zero fields don't filter.
+type AuditFilter struct {
+	TargetType string
+	TargetID   string
+	From       time.Time // inclusive
+	To         time.Time // exclusive
+	Limit      int64
+}
+
+// ListAuditEntries returns matching audit entries, newest first.
+func ListAuditEntries(db *sql.DB, filter AuditFilter) ([]model.AuditLog, error) {
+	condition := Bool(true)
+	if filter.TargetType != "" {
+		condition = condition.AND(table.AuditLog.TargetType.EQ(String(filter.TargetType)))
+	}
+	if filter.TargetID != "" {
+		condition = condition.AND(table.AuditLog.TargetID.EQ(String(filter.TargetID)))
+	}
+	if !filter.From.IsZero() {
+		condition = condition.AND(table.AuditLog.CreatedAt.GT_EQ(TimestampzT(filter.From)))
+	}
+	if !filter.To.IsZero() {
+		condition = condition.AND(table.AuditLog.CreatedAt.LT(TimestampzT(filter.To)))
+	}
+
+	stmt := SELECT(
+		table.AuditLog.AllColumns,
+	).FROM(
+		table.AuditLog,
+	).WHERE(
+		condition,
+	).ORDER_BY(
+		table.AuditLog.CreatedAt.DESC(),
+	).LIMIT(filter.Limit)
+
+	rows := []model.AuditLog{}
+	if err := stmt.Query(db, &rows); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
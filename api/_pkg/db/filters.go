@@ -0,0 +1,38 @@
+package db
+
+import (
+	. "github.com/go-jet/jet/v2/postgres"
+
+	"rmpc-server/db/.gen/rmpc/public/enum"
+	"rmpc-server/db/.gen/rmpc/public/table"
+)
+
+// visibleScore is the condition every public read applies to scores: the
+// player isn't banned, no moderator has removed the score, and it isn't held
+// for (or rejected in) review. Queries using it must LEFT JOIN banned_players
+// ON activeBanOf(<score's player>).
+func visibleScore() BoolExpression {
+	return AND(
+		table.BannedPlayers.ID.IS_NULL(),
+		table.Scores.RemovedAt.IS_NULL(),
+		table.Scores.ReviewStatus.EQ(enum.ReviewStatus.Accepted),
+	)
+}
+
+// activeBan matches ban rows that are in force right now: not lifted and not
+// yet expired.
+func activeBan() BoolExpression {
+	return AND(
+		table.BannedPlayers.LiftedAt.IS_NULL(),
+		OR(
+			table.BannedPlayers.ExpiresAt.IS_NULL(),
+			table.BannedPlayers.ExpiresAt.GT(NOW()),
+		),
+	)
+}
+
+// activeBanOf is the join condition for banned_players. Only the open ban can
+// match, so the join never multiplies rows however long the history is.
+func activeBanOf(playerID ColumnString) BoolExpression {
+	return table.BannedPlayers.PlayerID.EQ(playerID).AND(activeBan())
+}
@@ -7,6 +7,7 @@ import (
 
 	. "github.com/go-jet/jet/v2/postgres"
 
+	"rmpc-server/api/_pkg/ranking"
 	"rmpc-server/db/.gen/rmpc/public/table"
 )
 
@@ -20,12 +21,14 @@ type HallOfFameRow struct {
 
 // GetHallOfFame returns players ranked by trophy count for a single game mode
 // within [earliest, before). For each month it awards gold/silver/bronze to
-// the top 3 best-per-player scores, then aggregates per player. Rows arrive
-// pre-sorted by (gold, silver, bronze, best_score, name) — best_score is the
-// player's career best within the period, used to break trophy-count ties.
+// the players whose best runs rank 1-3 as on the leaderboard, ties sharing a
+// trophy, then aggregates per player. Rows arrive pre-sorted by (gold, silver,
+// bronze, best_score, name) — best_score is the player's career best within
+// the period, used to break trophy-count ties.
 //
-// Banned players are excluded. gameMode must be "author" or "gold".
-func GetHallOfFame(db *sql.DB, gameMode string, earliest, before time.Time) ([]HallOfFameRow, error) {
+// Only scores under ruleset count. Banned players and removed or unreviewed
+// scores are excluded. gameMode must be "author" or "gold".
+func GetHallOfFame(db *sql.DB, gameMode string, ruleset int16, earliest, before time.Time) ([]HallOfFameRow, error) {
 	modeExpr, ok := gameModeExpression[gameMode]
 	if !ok {
 		return nil, fmt.Errorf("invalid game mode: %s", gameMode)
@@ -33,33 +36,49 @@ func GetHallOfFame(db *sql.DB, gameMode string, earliest, before time.Time) ([]H
 
 	month := DATE_TRUNC(MONTH, table.Scores.CreatedAt, "UTC")
 
-	// One pass: GROUP BY collapses each player's monthly scores into a single
-	// row (their best for that month); the window function then ranks players
-	// within the month using those aggregates. Window functions run after
-	// GROUP BY, so MAX/MIN are valid inside ORDER BY.
-	rn := ROW_NUMBER().OVER(
-		PARTITION_BY(month).
-			ORDER_BY(MAX(table.Scores.Score).DESC(), MIN(table.Scores.CreatedAt).ASC()),
-	)
-	monthly := SELECT(
+	// Number each player's runs within a month in leaderboard order, so the
+	// first is their best run of the month.
+	playerMonths := SELECT(
 		table.Players.OpenplanetID,
 		table.Players.DisplayName,
-		MAX(table.Scores.Score).AS("best_score"),
-		rn.AS("rn"),
+		table.Scores.PlayerID,
+		table.Scores.Score,
+		table.Scores.MapsSkipped,
+		table.Scores.DurationMs,
+		table.Scores.CreatedAt,
+		month.AS("month"),
+		ROW_NUMBER().OVER(
+			PARTITION_BY(month, table.Scores.PlayerID).
+				ORDER_BY(orderBy(scoreRankColumns.recordKeys(ranking.ByScore))...),
+		).AS("player_rn"),
 	).FROM(
 		table.Scores.
 			INNER_JOIN(table.Players, table.Players.ID.EQ(table.Scores.PlayerID)).
-			LEFT_JOIN(table.BannedPlayers, table.BannedPlayers.PlayerID.EQ(table.Scores.PlayerID)),
+			LEFT_JOIN(table.BannedPlayers, activeBanOf(table.Scores.PlayerID)),
 	).WHERE(AND(
-		table.BannedPlayers.ID.IS_NULL(),
+		visibleScore(),
 		table.Scores.GameMode.EQ(modeExpr),
+		table.Scores.Ruleset.EQ(Int16(ruleset)),
 		table.Scores.Score.GT(Int(0)),
 		table.Scores.CreatedAt.GT_EQ(TimestampzT(earliest)),
 		table.Scores.CreatedAt.LT(TimestampzT(before)),
-	)).GROUP_BY(
-		table.Players.OpenplanetID,
-		table.Players.DisplayName,
-		month,
+	)).AsTable("player_months")
+
+	// Rank the best runs within each month. RANK gives tied runs the same
+	// place and skips the places after them, so a tie for gold means no silver.
+	best := scoreRankColumns.From(playerMonths)
+	monthly := SELECT(
+		table.Players.OpenplanetID.From(playerMonths),
+		table.Players.DisplayName.From(playerMonths),
+		best.Score.AS("best_score"),
+		RANK().OVER(
+			PARTITION_BY(TimestampzColumn("month").From(playerMonths)).
+				ORDER_BY(orderBy(best.rankKeys(ranking.ByScore))...),
+		).AS("rn"),
+	).FROM(
+		playerMonths,
+	).WHERE(
+		IntegerColumn("player_rn").From(playerMonths).EQ(Int(1)),
 	).AsTable("monthly")
 
 	mOpenplanetID := table.Players.OpenplanetID.From(monthly)
@@ -0,0 +1,127 @@
+package db
+
+import (
+	"database/sql"
+	"time"
+
+	. "github.com/go-jet/jet/v2/postgres"
+	"github.com/google/uuid"
+
+	"rmpc-server/db/.gen/rmpc/public/model"
+	"rmpc-server/db/.gen/rmpc/public/table"
+)
+
+// IdempotencyKey is an earlier use of an Idempotency-Key header.
+type IdempotencyKey struct {
+	model.IdempotencyKeys
+}
+
+// ReserveIdempotencyKey claims key for the player before a request is
+// processed. It returns nil if the key was free (or its previous use is older
+// than ttl, or was reserved more than abandonAfter ago and never stored a
+// score) and the key now belongs to this request; otherwise it returns the
+// earlier use, whose Response is nil while that request is still in flight or
+// if it stored its score but not the response.
+func ReserveIdempotencyKey(db *sql.DB, playerID uuid.UUID, key, requestHash string, ttl, abandonAfter time.Duration) (*IdempotencyKey, error) {
+	// Clear out this player's expired keys so they can be reused
+	cleanup := table.IdempotencyKeys.DELETE().WHERE(AND(
+		table.IdempotencyKeys.PlayerID.EQ(UUID(playerID)),
+		table.IdempotencyKeys.CreatedAt.LT(TimestampzT(time.Now().Add(-ttl))),
+	))
+	if _, err := cleanup.Exec(db); err != nil {
+		return nil, err
+	}
+
+	insert := table.IdempotencyKeys.INSERT(
+		table.IdempotencyKeys.PlayerID,
+		table.IdempotencyKeys.Key,
+		table.IdempotencyKeys.RequestHash,
+	).VALUES(
+		playerID,
+		key,
+		requestHash,
+	).ON_CONFLICT(
+		table.IdempotencyKeys.PlayerID,
+		table.IdempotencyKeys.Key,
+	).DO_NOTHING()
+
+	res, err := insert.Exec(db)
+	if err != nil {
+		return nil, err
+	}
+	if n, err := res.RowsAffected(); err != nil || n > 0 {
+		return nil, err
+	}
+
+	// Take over a reservation whose request was killed before it could
+	// answer or release the key
+	takeover := table.IdempotencyKeys.UPDATE(
+		table.IdempotencyKeys.RequestHash,
+		table.IdempotencyKeys.ReservedAt,
+	).SET(
+		String(requestHash),
+		NOW(),
+	).WHERE(AND(
+		table.IdempotencyKeys.PlayerID.EQ(UUID(playerID)),
+		table.IdempotencyKeys.Key.EQ(String(key)),
+		table.IdempotencyKeys.Response.IS_NULL(),
+		table.IdempotencyKeys.ScoreID.IS_NULL(),
+		table.IdempotencyKeys.ReservedAt.LT(TimestampzT(time.Now().Add(-abandonAfter))),
+	))
+
+	res, err = takeover.Exec(db)
+	if err != nil {
+		return nil, err
+	}
+	if n, err := res.RowsAffected(); err != nil || n > 0 {
+		return nil, err
+	}
+
+	stmt := SELECT(
+		table.IdempotencyKeys.AllColumns,
+	).FROM(
+		table.IdempotencyKeys,
+	).WHERE(AND(
+		table.IdempotencyKeys.PlayerID.EQ(UUID(playerID)),
+		table.IdempotencyKeys.Key.EQ(String(key)),
+	))
+
+	var dest IdempotencyKey
+	if err := stmt.Query(db, &dest); err != nil {
+		return nil, err
+	}
+	return &dest, nil
+}
+
+// CompleteIdempotencyKey stores the response of the request holding key.
+func CompleteIdempotencyKey(db *sql.DB, playerID uuid.UUID, key, response string) error {
+	// Column-list form so the value is sent as an untyped parameter and
+	// Postgres casts it to JSONB
+	stmt := table.IdempotencyKeys.UPDATE(
+		table.IdempotencyKeys.Response,
+	).SET(
+		response,
+	).WHERE(AND(
+		table.IdempotencyKeys.PlayerID.EQ(UUID(playerID)),
+		table.IdempotencyKeys.Key.EQ(String(key)),
+	))
+
+	_, err := stmt.Exec(db)
+	return err
+}
+
+// ReleaseIdempotencyKey frees a key whose request failed, so a retry runs it
+// again instead of waiting on a response that will never be stored. A key
+// whose score was stored is kept, since running the retry would store it
+// twice.
+func ReleaseIdempotencyKey(db *sql.DB, playerID uuid.UUID, key string) error {
+	stmt := table.IdempotencyKeys.DELETE().WHERE(AND(
+		table.IdempotencyKeys.PlayerID.EQ(UUID(playerID)),
+		table.IdempotencyKeys.Key.EQ(String(key)),
+		table.IdempotencyKeys.Response.IS_NULL(),
+		table.IdempotencyKeys.ScoreID.IS_NULL(),
+	))
+
+	_, err := stmt.Exec(db)
+	return err
+}
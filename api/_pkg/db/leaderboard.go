@@ -8,6 +8,7 @@ import (
 	. "github.com/go-jet/jet/v2/postgres"
 	"github.com/google/uuid"
 
+	"rmpc-server/api/_pkg/ranking"
 	"rmpc-server/db/.gen/rmpc/public/enum"
 	"rmpc-server/db/.gen/rmpc/public/model"
 	"rmpc-server/db/.gen/rmpc/public/table"
@@ -16,10 +17,12 @@ import (
 var gameModeExpression = map[string]StringExpression{
 	"author": enum.GameMode.Author,
 	"gold":   enum.GameMode.Gold,
+	"custom": enum.GameMode.Custom,
 }
 
 type LeaderboardEntry struct {
-	Rank          int            `sql:"-"`
+	Rank          int            `alias:"ranked.rank"`
+	Position      int            `alias:"ranked.position"` // in listing order, unlike Rank never shared
 	PlayerID      uuid.UUID      `alias:"scores.player_id"`
 	OpenplanetID  string         `alias:"players.openplanet_id"`
 	DisplayName   string         `alias:"players.display_name"`
@@ -29,25 +32,275 @@ type LeaderboardEntry struct {
 	DurationMs    int32          `alias:"scores.duration_ms"`
 	GameMode      model.GameMode `alias:"scores.game_mode"`
 	CreatedAt     *time.Time     `alias:"scores.created_at"`
+	Runs          int32          `alias:"ranked.runs"` // the player's runs on the leaderboard
 }
 
+// LeaderboardParams select a leaderboard. An empty GameMode combines author
+// and gold; custom scores are only ranked within one preset, so custom needs
+// SettingsHash. A zero Ruleset mixes all rulesets. Players are ranked by the
+// RankBy metric of package ranking, score if empty; ranking.ByFewestSkips
+// only counts runs scoring at least MinScore.
 type LeaderboardParams struct {
-	GameMode  string
-	StartTime *time.Time
-	EndTime   *time.Time
+	GameMode     string
+	SettingsHash string
+	Ruleset      int16
+	StartTime    *time.Time
+	EndTime      *time.Time
+	RankBy       string
+	MinScore     int32
 }
 
-func GetLeaderboard(db *sql.DB, params LeaderboardParams) ([]LeaderboardEntry, error) {
-	condition := table.BannedPlayers.ID.IS_NULL().AND(
-		table.Scores.Score.GT(Int(0)),
+// metric returns the metric the leaderboard ranks by.
+func (p LeaderboardParams) metric() string {
+	if p.RankBy == "" {
+		return ranking.ByScore
+	}
+	return p.RankBy
+}
+
+// Cursor returns the position of e in the order entries are listed in, for
+// the page after it.
+func (e LeaderboardEntry) Cursor() RankedRun {
+	c := RankedRun{
+		Score:         e.Score,
+		MapsCompleted: e.MapsCompleted,
+		MapsSkipped:   e.MapsSkipped,
+		DurationMs:    e.DurationMs,
+		Runs:          e.Runs,
+		PlayerID:      e.PlayerID,
+	}
+	if e.CreatedAt != nil {
+		c.CreatedAt = *e.CreatedAt
+	}
+	return c
+}
+
+// GetLeaderboard returns up to limit entries of the leaderboard selected by
+// params, listed after the cursor if there is one. Ranks are competition
+// ranks on the whole leaderboard, not positions within the page.
+func GetLeaderboard(db *sql.DB, params LeaderboardParams, after *RankedRun, limit int64) ([]LeaderboardEntry, error) {
+	condition, err := leaderboardCondition(params)
+	if err != nil {
+		return nil, err
+	}
+	ranked := rankedScoresTable(condition, params.metric())
+
+	where := Bool(true)
+	if after != nil {
+		where = sortsAfter(scoreRankColumns.From(ranked).listKeys(params.metric()), *after)
+	}
+	return queryLeaderboard(db, ranked, where, limit)
+}
+
+// GetLeaderboardAround returns the player's entry on the leaderboard selected
+// by params with up to span entries above and below it. Returns nil if the
+// player has no score on it.
+func GetLeaderboardAround(db *sql.DB, params LeaderboardParams, playerID uuid.UUID, span int) ([]LeaderboardEntry, error) {
+	standing, err := GetStanding(db, params, playerID)
+	if err != nil || standing == nil {
+		return nil, err
+	}
+
+	condition, err := leaderboardCondition(params)
+	if err != nil {
+		return nil, err
+	}
+	ranked := rankedScoresTable(condition, params.metric())
+	position := IntegerColumn("position").From(ranked)
+
+	where := position.BETWEEN(Int(int64(standing.Position-span)), Int(int64(standing.Position+span)))
+	return queryLeaderboard(db, ranked, where, int64(2*span+1))
+}
+
+// queryLeaderboard reads entries from rankedScoresTable in rank order.
+func queryLeaderboard(db *sql.DB, ranked SelectTable, where BoolExpression, limit int64) ([]LeaderboardEntry, error) {
+	stmt := SELECT(
+		IntegerColumn("rank").From(ranked).AS("ranked.rank"),
+		IntegerColumn("position").From(ranked).AS("ranked.position"),
+		table.Scores.PlayerID.From(ranked),
+		table.Players.OpenplanetID.From(ranked),
+		table.Players.DisplayName.From(ranked),
+		table.Scores.Score.From(ranked),
+		table.Scores.MapsCompleted.From(ranked),
+		table.Scores.MapsSkipped.From(ranked),
+		table.Scores.DurationMs.From(ranked),
+		table.Scores.GameMode.From(ranked),
+		table.Scores.CreatedAt.From(ranked),
+		IntegerColumn("runs").From(ranked).AS("ranked.runs"),
+	).FROM(
+		ranked,
+	).WHERE(
+		where,
+	).ORDER_BY(
+		IntegerColumn("position").From(ranked).ASC(),
+	).LIMIT(limit)
+
+	entries := []LeaderboardEntry{}
+	if err := stmt.Query(db, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// CountRankedPlayers returns how many players have a score on the leaderboard
+// selected by params.
+func CountRankedPlayers(db *sql.DB, params LeaderboardParams) (int64, error) {
+	condition, err := leaderboardCondition(params)
+	if err != nil {
+		return 0, err
+	}
+
+	stmt := SELECT(
+		COUNT(DISTINCT(table.Scores.PlayerID)),
+	).FROM(
+		table.Scores.
+			LEFT_JOIN(table.BannedPlayers, activeBanOf(table.Scores.PlayerID)),
+	).WHERE(
+		condition,
 	)
 
+	var dest struct {
+		Count int64
+	}
+	if err := stmt.Query(db, &dest); err != nil {
+		return 0, err
+	}
+	return dest.Count, nil
+}
+
+// Standing is where a player's best score places on a leaderboard.
+type Standing struct {
+	ScoreID  uuid.UUID `alias:"standing.score_id"`
+	Score    int32     `alias:"standing.score"`
+	Rank     int       `alias:"standing.rank"`
+	Position int       `alias:"standing.position"` // in listing order, unlike Rank never shared
+}
+
+// GetStanding ranks the player's best score on the leaderboard selected by
+// params exactly as GetLeaderboard would. Returns nil if the player has no
+// score on it.
+func GetStanding(db *sql.DB, params LeaderboardParams, playerID uuid.UUID) (*Standing, error) {
+	condition, err := leaderboardCondition(params)
+	if err != nil {
+		return nil, err
+	}
+	ranked := rankedScoresTable(condition, params.metric())
+
+	stmt := SELECT(
+		table.Scores.ID.From(ranked).AS("standing.score_id"),
+		table.Scores.Score.From(ranked).AS("standing.score"),
+		IntegerColumn("rank").From(ranked).AS("standing.rank"),
+		IntegerColumn("position").From(ranked).AS("standing.position"),
+	).FROM(
+		ranked,
+	).WHERE(
+		table.Scores.PlayerID.From(ranked).EQ(UUID(playerID)),
+	)
+
+	var dest Standing
+	if err := stmt.Query(db, &dest); err != nil {
+		if err.Error() == "jet: sql: no rows in result set" {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &dest, nil
+}
+
+// GetPersonalBest returns the player's best score on the leaderboard selected
+// by params other than excludeID, or nil if there is none.
+func GetPersonalBest(db *sql.DB, params LeaderboardParams, playerID, excludeID uuid.UUID) (*int32, error) {
+	condition, err := leaderboardCondition(params)
+	if err != nil {
+		return nil, err
+	}
+
+	stmt := SELECT(
+		MAX(table.Scores.Score).AS("best.score"),
+	).FROM(
+		table.Scores.
+			LEFT_JOIN(table.BannedPlayers, activeBanOf(table.Scores.PlayerID)),
+	).WHERE(AND(
+		condition,
+		table.Scores.PlayerID.EQ(UUID(playerID)),
+		table.Scores.ID.NOT_EQ(UUID(excludeID)),
+	))
+
+	var dest struct {
+		Score *int32 `alias:"best.score"`
+	}
+	if err := stmt.Query(db, &dest); err != nil {
+		return nil, err
+	}
+	return dest.Score, nil
+}
+
+// GetProjectedRank returns the rank run would take on the leaderboard selected
+// by params if it became the player's best.
+func GetProjectedRank(db *sql.DB, params LeaderboardParams, playerID uuid.UUID, run RankedRun) (int, error) {
+	condition, err := leaderboardCondition(params)
+	if err != nil {
+		return 0, err
+	}
+	bestScores := bestScoresTable(condition.AND(
+		table.Scores.PlayerID.NOT_EQ(UUID(playerID)),
+	), params.metric())
+
+	stmt := SELECT(
+		COUNT(STAR),
+	).FROM(
+		bestScores,
+	).WHERE(
+		sortsBefore(scoreRankColumns.From(bestScores).rankKeys(params.metric()), run),
+	)
+
+	var dest struct {
+		Count int
+	}
+	if err := stmt.Query(db, &dest); err != nil {
+		return 0, err
+	}
+	return dest.Count + 1, nil
+}
+
+// leaderboardCondition selects the scores that count on the leaderboard
+// described by params. Every run counts towards ranking.ByRuns, but only runs
+// that scored towards the other metrics, and only runs with a duration towards
+// ranking.ByScorePerHour.
+func leaderboardCondition(params LeaderboardParams) (BoolExpression, error) {
+	condition := visibleScore()
+	switch params.metric() {
+	case ranking.ByRuns:
+	case ranking.ByFewestSkips:
+		if params.MinScore <= 0 {
+			return nil, fmt.Errorf("fewest skips leaderboard needs a minimum score")
+		}
+		condition = condition.AND(table.Scores.Score.GT_EQ(Int32(params.MinScore)))
+	case ranking.ByScorePerHour:
+		condition = AND(condition, table.Scores.Score.GT(Int(0)), table.Scores.DurationMs.GT(Int(0)))
+	case ranking.ByScore, ranking.ByMapsCompleted:
+		condition = condition.AND(table.Scores.Score.GT(Int(0)))
+	default:
+		return nil, fmt.Errorf("invalid rank metric: %s", params.RankBy)
+	}
+
 	if params.GameMode != "" {
 		expr, ok := gameModeExpression[params.GameMode]
 		if !ok {
 			return nil, fmt.Errorf("invalid game mode: %s", params.GameMode)
 		}
 		condition = condition.AND(table.Scores.GameMode.EQ(expr))
+	} else {
+		condition = condition.AND(table.Scores.GameMode.IN(enum.GameMode.Author, enum.GameMode.Gold))
+	}
+	if params.GameMode == "custom" {
+		if params.SettingsHash == "" {
+			return nil, fmt.Errorf("custom leaderboard needs a settings hash")
+		}
+		condition = condition.AND(table.Scores.SettingsHash.EQ(String(params.SettingsHash)))
+	}
+	if params.Ruleset != 0 {
+		condition = condition.AND(table.Scores.Ruleset.EQ(Int16(params.Ruleset)))
 	}
 	if params.StartTime != nil {
 		condition = condition.AND(table.Scores.CreatedAt.GT_EQ(TimestampzT(*params.StartTime)))
@@ -55,9 +308,33 @@ func GetLeaderboard(db *sql.DB, params LeaderboardParams) ([]LeaderboardEntry, e
 	if params.EndTime != nil {
 		condition = condition.AND(table.Scores.CreatedAt.LT(TimestampzT(*params.EndTime)))
 	}
+	return condition, nil
+}
 
-	// Best score per player using DISTINCT ON
-	bestScores := SELECT(
+// rankedScoresTable is bestScoresTable with each row's competition rank by
+// metric in a "rank" column and its place in listing order in "position".
+func rankedScoresTable(condition BoolExpression, metric string) SelectTable {
+	bestScores := bestScoresTable(condition, metric)
+	columns := scoreRankColumns.From(bestScores)
+	return SELECT(
+		bestScores.AllColumns(),
+		RANK().OVER(ORDER_BY(orderBy(columns.rankKeys(metric))...)).AS("rank"),
+		ROW_NUMBER().OVER(ORDER_BY(orderBy(columns.listKeys(metric))...)).AS("position"),
+	).FROM(
+		bestScores,
+	).AsTable("ranked")
+}
+
+// bestScoresTable is each player's best run by metric matching condition,
+// using DISTINCT ON: the first in ranking order, and of equal runs the
+// earliest. The player's count of runs matching condition is in "runs"; as
+// that is the same for all of them, ranking.ByRuns picks the best by score.
+func bestScoresTable(condition BoolExpression, metric string) SelectTable {
+	if metric == ranking.ByRuns {
+		metric = ranking.ByScore
+	}
+	return SELECT(
+		table.Scores.ID,
 		table.Scores.PlayerID,
 		table.Scores.Score,
 		table.Scores.MapsCompleted,
@@ -67,55 +344,16 @@ func GetLeaderboard(db *sql.DB, params LeaderboardParams) ([]LeaderboardEntry, e
 		table.Scores.CreatedAt,
 		table.Players.OpenplanetID,
 		table.Players.DisplayName,
+		COUNT(STAR).OVER(PARTITION_BY(table.Scores.PlayerID)).AS("runs"),
 	).DISTINCT(
 		table.Scores.PlayerID,
 	).FROM(
 		table.Scores.
 			INNER_JOIN(table.Players, table.Players.ID.EQ(table.Scores.PlayerID)).
-			LEFT_JOIN(table.BannedPlayers, table.BannedPlayers.PlayerID.EQ(table.Scores.PlayerID)),
+			LEFT_JOIN(table.BannedPlayers, activeBanOf(table.Scores.PlayerID)),
 	).WHERE(
 		condition,
 	).ORDER_BY(
-		table.Scores.PlayerID,
-		table.Scores.Score.DESC(),
+		append([]OrderByClause{table.Scores.PlayerID}, orderBy(scoreRankColumns.recordKeys(metric))...)...,
 	).AsTable("best_scores")
-
-	// Columns from the CTE
-	bsPlayerID := table.Scores.PlayerID.From(bestScores)
-	bsOpenplanetID := table.Players.OpenplanetID.From(bestScores)
-	bsDisplayName := table.Players.DisplayName.From(bestScores)
-	bsScore := table.Scores.Score.From(bestScores)
-	bsMapsCompleted := table.Scores.MapsCompleted.From(bestScores)
-	bsMapsSkipped := table.Scores.MapsSkipped.From(bestScores)
-	bsDurationMs := table.Scores.DurationMs.From(bestScores)
-	bsGameMode := table.Scores.GameMode.From(bestScores)
-	bsCreatedAt := table.Scores.CreatedAt.From(bestScores)
-
-	stmt := SELECT(
-		bsPlayerID,
-		bsOpenplanetID,
-		bsDisplayName,
-		bsScore,
-		bsMapsCompleted,
-		bsMapsSkipped,
-		bsDurationMs,
-		bsGameMode,
-		bsCreatedAt,
-	).FROM(
-		bestScores,
-	).ORDER_BY(
-		bsScore.DESC(),
-	).LIMIT(50)
-
-	var entries []LeaderboardEntry
-	err := stmt.Query(db, &entries)
-	if err != nil {
-		return nil, err
-	}
-
-	for i := range entries {
-		entries[i].Rank = i + 1
-	}
-
-	return entries, nil
 }
@@ -0,0 +1,104 @@
+package db
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/go-jet/jet/v2/postgres"
+
+	"rmpc-server/api/_pkg/ranking"
+)
+
+func TestLeaderboardCondition(t *testing.T) {
+	const visible = "( banned_players.id IS NULL AND scores.removed_at IS NULL AND (scores.review_status = 'accepted') )"
+
+	tests := []struct {
+		name    string
+		params  LeaderboardParams
+		want    string // a part of the condition, or of the error
+		exclude string
+		wantErr bool
+	}{
+		{name: "score", params: LeaderboardParams{}, want: "(scores.score > 0)"},
+		{name: "maps completed", params: LeaderboardParams{RankBy: ranking.ByMapsCompleted}, want: "(scores.score > 0)"},
+		{
+			name:   "score per hour leaves out runs without a duration",
+			params: LeaderboardParams{RankBy: ranking.ByScorePerHour},
+			want:   "(scores.score > 0) AND (scores.duration_ms > 0)",
+		},
+		{
+			name:    "fewest skips among runs reaching the minimum",
+			params:  LeaderboardParams{RankBy: ranking.ByFewestSkips, MinScore: 600000},
+			want:    "(scores.score >= 600000::integer)",
+			exclude: "(scores.score > 0)",
+		},
+		{
+			name:    "fewest skips needs a minimum",
+			params:  LeaderboardParams{RankBy: ranking.ByFewestSkips},
+			want:    "needs a minimum score",
+			wantErr: true,
+		},
+		{
+			name:    "runs counts runs without a score",
+			params:  LeaderboardParams{RankBy: ranking.ByRuns},
+			want:    visible,
+			exclude: "scores.score",
+		},
+		{name: "unknown metric", params: LeaderboardParams{RankBy: "most_medals"}, want: "invalid rank metric", wantErr: true},
+		{name: "ruleset", params: LeaderboardParams{Ruleset: 2}, want: "(scores.ruleset = 2::smallint)"},
+		{name: "custom needs a preset", params: LeaderboardParams{GameMode: "custom"}, want: "settings hash", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			condition, err := leaderboardCondition(tt.params)
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), tt.want) {
+					t.Fatalf("leaderboardCondition() error = %v, want one containing %q", err, tt.want)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("leaderboardCondition() error = %v", err)
+			}
+			got := whereSQL(condition)
+			if !strings.Contains(got, tt.want) {
+				t.Errorf("leaderboardCondition() = %q, want it to contain %q", got, tt.want)
+			}
+			if tt.exclude != "" && strings.Contains(got, tt.exclude) {
+				t.Errorf("leaderboardCondition() = %q, want it not to contain %q", got, tt.exclude)
+			}
+		})
+	}
+}
+
+// Each player's runs on the board are counted before DISTINCT ON keeps one
+// row of theirs; ranking.ByRuns keeps the best by score.
+func TestBestScoresTable(t *testing.T) {
+	const runs = `COUNT(*) OVER (PARTITION BY scores.player_id) AS "runs"`
+
+	tests := []struct {
+		metric string
+		order  string
+	}{
+		{ranking.ByScore, "ORDER BY scores.player_id, scores.score DESC,"},
+		{ranking.ByMapsCompleted, "ORDER BY scores.player_id, scores.maps_completed DESC, scores.score DESC,"},
+		{ranking.ByRuns, "ORDER BY scores.player_id, scores.score DESC,"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.metric, func(t *testing.T) {
+			best := bestScoresTable(Bool(true), tt.metric)
+			sql := strings.Join(strings.Fields(SELECT(STAR).FROM(best).DebugSql()), " ")
+			if !strings.Contains(sql, "SELECT DISTINCT ON (scores.player_id)") {
+				t.Errorf("bestScoresTable() = %q, want DISTINCT ON player", sql)
+			}
+			if !strings.Contains(sql, runs) {
+				t.Errorf("bestScoresTable() = %q, want it to count runs", sql)
+			}
+			if !strings.Contains(sql, tt.order) {
+				t.Errorf("bestScoresTable() = %q, want it to contain %q", sql, tt.order)
+			}
+		})
+	}
+}
@@ -0,0 +1,359 @@
+package db
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+
+	. "github.com/go-jet/jet/v2/postgres"
+	"github.com/google/uuid"
+
+	"rmpc-server/db/.gen/rmpc/public/enum"
+	"rmpc-server/db/.gen/rmpc/public/model"
+	"rmpc-server/db/.gen/rmpc/public/table"
+)
+
+type BanInput struct {
+	PlayerID  uuid.UUID
+	BannedBy  uuid.UUID
+	Reason    *string
+	ExpiresAt *time.Time // nil for a permanent ban
+}
+
+// supersededReason is recorded on a ban that was replaced by a newer one.
+const supersededReason = "superseded by a new ban"
+
+// BanPlayer bans a player. A ban already in force is lifted and replaced, so
+// the history keeps both.
+func BanPlayer(db *sql.DB, input BanInput) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// An expired ban is still open until something closes it, and only one
+	// open ban is allowed per player. Close it as of its expiry.
+	closeExpired := table.BannedPlayers.UPDATE().SET(
+		table.BannedPlayers.LiftedAt.SET(table.BannedPlayers.ExpiresAt),
+	).WHERE(AND(
+		table.BannedPlayers.PlayerID.EQ(UUID(input.PlayerID)),
+		table.BannedPlayers.LiftedAt.IS_NULL(),
+		table.BannedPlayers.ExpiresAt.LT_EQ(NOW()),
+	))
+	if _, err := closeExpired.Exec(tx); err != nil {
+		return err
+	}
+
+	supersede := table.BannedPlayers.UPDATE().SET(
+		table.BannedPlayers.LiftedAt.SET(TimestampzExpression(NOW())),
+		table.BannedPlayers.LiftedBy.SET(UUID(input.BannedBy)),
+		table.BannedPlayers.LiftReason.SET(String(supersededReason)),
+	).WHERE(AND(
+		table.BannedPlayers.PlayerID.EQ(UUID(input.PlayerID)),
+		table.BannedPlayers.LiftedAt.IS_NULL(),
+	))
+	if _, err := supersede.Exec(tx); err != nil {
+		return err
+	}
+
+	insert := table.BannedPlayers.INSERT(
+		table.BannedPlayers.PlayerID,
+		table.BannedPlayers.Reason,
+		table.BannedPlayers.ExpiresAt,
+		table.BannedPlayers.BannedBy,
+	).VALUES(
+		input.PlayerID,
+		input.Reason,
+		input.ExpiresAt,
+		input.BannedBy,
+	)
+	if _, err := insert.Exec(tx); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// UnbanPlayer lifts the ban in force, keeping it in the history. Reports
+// whether the player was banned.
+func UnbanPlayer(db *sql.DB, playerID, liftedBy uuid.UUID, reason *string) (bool, error) {
+	stmt := table.BannedPlayers.UPDATE().SET(
+		table.BannedPlayers.LiftedAt.SET(TimestampzExpression(NOW())),
+		table.BannedPlayers.LiftedBy.SET(UUID(liftedBy)),
+		table.BannedPlayers.LiftReason.SET(reasonExpression(reason)),
+	).WHERE(AND(
+		table.BannedPlayers.PlayerID.EQ(UUID(playerID)),
+		activeBan(),
+	))
+
+	res, err := stmt.Exec(db)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+type BanRow struct {
+	ID           uuid.UUID  `alias:"banned_players.id"`
+	OpenplanetID string     `alias:"players.openplanet_id"`
+	DisplayName  string     `alias:"players.display_name"`
+	Reason       *string    `alias:"banned_players.reason"`
+	BannedAt     *time.Time `alias:"banned_players.banned_at"`
+	ExpiresAt    *time.Time `alias:"banned_players.expires_at"`
+	BannedBy     *string    `alias:"banned_by.display_name"`
+	LiftedAt     *time.Time `alias:"banned_players.lifted_at"`
+	LiftedBy     *string    `alias:"lifted_by.display_name"`
+	LiftReason   *string    `alias:"banned_players.lift_reason"`
+}
+
+// ListBans returns the bans in force, most recent first.
+func ListBans(db *sql.DB) ([]BanRow, error) {
+	return queryBans(db, activeBan())
+}
+
+// ListPlayerBans returns a player's full ban history, most recent first,
+// including lifted and expired bans.
+func ListPlayerBans(db *sql.DB, playerID uuid.UUID) ([]BanRow, error) {
+	return queryBans(db, table.BannedPlayers.PlayerID.EQ(UUID(playerID)))
+}
+
+func queryBans(db *sql.DB, condition BoolExpression) ([]BanRow, error) {
+	bannedBy := table.Players.AS("banned_by")
+	liftedBy := table.Players.AS("lifted_by")
+
+	stmt := SELECT(
+		table.BannedPlayers.ID,
+		table.Players.OpenplanetID,
+		table.Players.DisplayName,
+		table.BannedPlayers.Reason,
+		table.BannedPlayers.BannedAt,
+		table.BannedPlayers.ExpiresAt,
+		bannedBy.DisplayName,
+		table.BannedPlayers.LiftedAt,
+		liftedBy.DisplayName,
+		table.BannedPlayers.LiftReason,
+	).FROM(
+		table.BannedPlayers.
+			INNER_JOIN(table.Players, table.Players.ID.EQ(table.BannedPlayers.PlayerID)).
+			LEFT_JOIN(bannedBy, bannedBy.ID.EQ(table.BannedPlayers.BannedBy)).
+			LEFT_JOIN(liftedBy, liftedBy.ID.EQ(table.BannedPlayers.LiftedBy)),
+	).WHERE(
+		condition,
+	).ORDER_BY(
+		table.BannedPlayers.BannedAt.DESC(),
+	)
+
+	rows := []BanRow{}
+	if err := stmt.Query(db, &rows); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// RemoveScore soft-deletes a score so it disappears from every public read.
+// Reports whether a visible score was found.
+func RemoveScore(db *sql.DB, scoreID, removedBy uuid.UUID, reason *string) (bool, error) {
+	stmt := table.Scores.UPDATE().SET(
+		table.Scores.RemovedAt.SET(TimestampzExpression(NOW())),
+		table.Scores.RemovedBy.SET(UUID(removedBy)),
+		table.Scores.RemovedReason.SET(reasonExpression(reason)),
+	).WHERE(
+		table.Scores.ID.EQ(UUID(scoreID)).
+			AND(table.Scores.RemovedAt.IS_NULL()),
+	)
+
+	res, err := stmt.Exec(db)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// RestoreScore undoes RemoveScore. Reports whether a removed score was found.
+func RestoreScore(db *sql.DB, scoreID uuid.UUID) (bool, error) {
+	stmt := table.Scores.UPDATE().SET(
+		table.Scores.RemovedAt.SET(TimestampzExp(NULL)),
+		table.Scores.RemovedBy.SET(StringExp(NULL)),
+		table.Scores.RemovedReason.SET(StringExp(NULL)),
+	).WHERE(
+		table.Scores.ID.EQ(UUID(scoreID)).
+			AND(table.Scores.RemovedAt.IS_NOT_NULL()),
+	)
+
+	res, err := stmt.Exec(db)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+func reasonExpression(reason *string) StringExpression {
+	if reason == nil {
+		return StringExp(NULL)
+	}
+	return String(*reason)
+}
+
+type PlayerLookupRow struct {
+	ID           uuid.UUID        `alias:"players.id"`
+	OpenplanetID string           `alias:"players.openplanet_id"`
+	DisplayName  string           `alias:"players.display_name"`
+	Role         model.PlayerRole `alias:"players.role"`
+	CreatedAt    *time.Time       `alias:"players.created_at"`
+	BanReason    *string          `alias:"banned_players.reason"`
+	BannedAt     *time.Time       `alias:"banned_players.banned_at"`
+	BanExpiresAt *time.Time       `alias:"banned_players.expires_at"`
+}
+
+// FindPlayers looks players up by exact Openplanet ID or, when that is empty,
+// by case-insensitive display name substring. At most limit rows are returned.
+func FindPlayers(db *sql.DB, openplanetID, name string, limit int64) ([]PlayerLookupRow, error) {
+	var condition BoolExpression
+	if openplanetID != "" {
+		condition = table.Players.OpenplanetID.EQ(String(openplanetID))
+	} else {
+		condition = LOWER(table.Players.DisplayName).LIKE(String("%" + escapeLike(name) + "%"))
+	}
+
+	stmt := SELECT(
+		table.Players.ID,
+		table.Players.OpenplanetID,
+		table.Players.DisplayName,
+		table.Players.Role,
+		table.Players.CreatedAt,
+		table.BannedPlayers.Reason,
+		table.BannedPlayers.BannedAt,
+		table.BannedPlayers.ExpiresAt,
+	).FROM(
+		table.Players.
+			LEFT_JOIN(table.BannedPlayers, activeBanOf(table.Players.ID)),
+	).WHERE(
+		condition,
+	).ORDER_BY(
+		LOWER(table.Players.DisplayName).ASC(),
+	).LIMIT(limit)
+
+	rows := []PlayerLookupRow{}
+	if err := stmt.Query(db, &rows); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// FindPlayerID resolves an Openplanet ID to the internal player ID. Returns
+// uuid.Nil when no such player exists.
+func FindPlayerID(db *sql.DB, openplanetID string) (uuid.UUID, error) {
+	stmt := SELECT(
+		table.Players.ID,
+	).FROM(
+		table.Players,
+	).WHERE(
+		table.Players.OpenplanetID.EQ(String(openplanetID)),
+	)
+
+	var dest model.Players
+	err := stmt.Query(db, &dest)
+	if err != nil {
+		if err.Error() == "jet: sql: no rows in result set" {
+			return uuid.Nil, nil
+		}
+		return uuid.Nil, err
+	}
+	return dest.ID, nil
+}
+
+// escapeLike lowercases s and escapes LIKE wildcards so user input only ever
+// matches literally.
+func escapeLike(s string) string {
+	var out []rune
+	for _, r := range strings.ToLower(s) {
+		if r == '%' || r == '_' || r == '\\' {
+			out = append(out, '\\')
+		}
+		out = append(out, r)
+	}
+	return string(out)
+}
+
+type ReviewRow struct {
+	ID            uuid.UUID      `alias:"scores.id"`
+	OpenplanetID  string         `alias:"players.openplanet_id"`
+	DisplayName   string         `alias:"players.display_name"`
+	GameMode      model.GameMode `alias:"scores.game_mode"`
+	Score         int32          `alias:"scores.score"`
+	MapsCompleted int32          `alias:"scores.maps_completed"`
+	MapsSkipped   int32          `alias:"scores.maps_skipped"`
+	DurationMs    int32          `alias:"scores.duration_ms"`
+	RunID         *uuid.UUID     `alias:"scores.run_id"`
+	FlagReason    *string        `alias:"scores.flag_reason"`
+	CreatedAt     *time.Time     `alias:"scores.created_at"`
+	Proofs        int64          `alias:"scores.proofs"`
+}
+
+// ListPendingReviews returns scores waiting for review, oldest first, with
+// how many proofs each has. Removed scores are left out; they are already off
+// the boards for good.
+func ListPendingReviews(db *sql.DB, limit int64) ([]ReviewRow, error) {
+	stmt := SELECT(
+		table.Scores.ID,
+		table.Players.OpenplanetID,
+		table.Players.DisplayName,
+		table.Scores.GameMode,
+		table.Scores.Score,
+		table.Scores.MapsCompleted,
+		table.Scores.MapsSkipped,
+		table.Scores.DurationMs,
+		table.Scores.RunID,
+		table.Scores.FlagReason,
+		table.Scores.CreatedAt,
+		COUNT(table.ScoreProofs.ID).AS("scores.proofs"),
+	).FROM(
+		table.Scores.
+			INNER_JOIN(table.Players, table.Players.ID.EQ(table.Scores.PlayerID)).
+			LEFT_JOIN(table.ScoreProofs, table.ScoreProofs.ScoreID.EQ(table.Scores.ID)),
+	).WHERE(AND(
+		table.Scores.ReviewStatus.EQ(enum.ReviewStatus.PendingReview),
+		table.Scores.RemovedAt.IS_NULL(),
+	)).GROUP_BY(
+		table.Scores.ID,
+		table.Players.ID,
+	).ORDER_BY(
+		table.Scores.CreatedAt.ASC(),
+	).LIMIT(limit)
+
+	rows := []ReviewRow{}
+	if err := stmt.Query(db, &rows); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// ReviewScore settles a pending score: accepted scores appear on the public
+// boards, rejected ones never do. Reports whether a pending score was found.
+func ReviewScore(db *sql.DB, scoreID, reviewerID uuid.UUID, accept bool, note *string) (bool, error) {
+	status := enum.ReviewStatus.Rejected
+	if accept {
+		status = enum.ReviewStatus.Accepted
+	}
+
+	stmt := table.Scores.UPDATE().SET(
+		table.Scores.ReviewStatus.SET(status),
+		table.Scores.ReviewedAt.SET(TimestampzExpression(NOW())),
+		table.Scores.ReviewedBy.SET(UUID(reviewerID)),
+		table.Scores.ReviewNote.SET(reasonExpression(note)),
+	).WHERE(AND(
+		table.Scores.ID.EQ(UUID(scoreID)),
+		table.Scores.ReviewStatus.EQ(enum.ReviewStatus.PendingReview),
+	))
+
+	res, err := stmt.Exec(db)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
@@ -38,6 +38,7 @@ type PlayerScoreRow struct {
 	MapsSkipped   int32          `alias:"scores.maps_skipped"`
 	DurationMs    int32          `alias:"scores.duration_ms"`
 	CreatedAt     *time.Time     `alias:"scores.created_at"`
+	SettingsHash  *string        `alias:"scores.settings_hash"`
 }
 
 type PlayerDetail struct {
@@ -46,9 +47,10 @@ type PlayerDetail struct {
 	Scores       []PlayerScoreRow
 }
 
-// GetPlayerDetail returns a player and all their author/gold scores ordered
-// newest first. Returns (nil, nil) when the player doesn't exist, is banned,
-// or has no scores in these modes.
+// GetPlayerDetail returns a player and all their author/gold scores and
+// custom scores with a preset, ordered newest first, leaving out removed and
+// unreviewed scores. Returns (nil, nil) when the player doesn't exist, is
+// banned, or has no such scores.
 func GetPlayerDetail(db *sql.DB, openplanetID string) (*PlayerDetail, error) {
 	stmt := SELECT(
 		table.Players.OpenplanetID,
@@ -59,14 +61,18 @@ func GetPlayerDetail(db *sql.DB, openplanetID string) (*PlayerDetail, error) {
 		table.Scores.MapsSkipped,
 		table.Scores.DurationMs,
 		table.Scores.CreatedAt,
+		table.Scores.SettingsHash,
 	).FROM(
 		table.Players.
 			INNER_JOIN(table.Scores, table.Scores.PlayerID.EQ(table.Players.ID)).
-			LEFT_JOIN(table.BannedPlayers, table.BannedPlayers.PlayerID.EQ(table.Players.ID)),
+			LEFT_JOIN(table.BannedPlayers, activeBanOf(table.Players.ID)),
 	).WHERE(AND(
 		table.Players.OpenplanetID.EQ(String(openplanetID)),
-		table.BannedPlayers.ID.IS_NULL(),
-		table.Scores.GameMode.IN(enum.GameMode.Author, enum.GameMode.Gold),
+		visibleScore(),
+		OR(
+			table.Scores.GameMode.IN(enum.GameMode.Author, enum.GameMode.Gold),
+			table.Scores.SettingsHash.IS_NOT_NULL(),
+		),
 		table.Scores.Score.GT(Int(0)),
 	)).ORDER_BY(
 		table.Scores.CreatedAt.DESC(),
@@ -94,3 +100,19 @@ func GetPlayerDetail(db *sql.DB, openplanetID string) (*PlayerDetail, error) {
 	}
 	return detail, nil
 }
+
+func GetPlayerRole(db *sql.DB, playerID uuid.UUID) (model.PlayerRole, error) {
+	stmt := SELECT(
+		table.Players.Role,
+	).FROM(
+		table.Players,
+	).WHERE(
+		table.Players.ID.EQ(UUID(playerID)),
+	)
+
+	var dest model.Players
+	if err := stmt.Query(db, &dest); err != nil {
+		return "", err
+	}
+	return dest.Role, nil
+}
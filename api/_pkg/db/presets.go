@@ -0,0 +1,88 @@
+package db
+
+import (
+	"database/sql"
+	"time"
+
+	. "github.com/go-jet/jet/v2/postgres"
+
+	"rmpc-server/db/.gen/rmpc/public/enum"
+	"rmpc-server/db/.gen/rmpc/public/model"
+	"rmpc-server/db/.gen/rmpc/public/table"
+)
+
+type PresetRow struct {
+	Hash     string `alias:"custom_presets.hash"`
+	Settings string `alias:"custom_presets.settings"`
+	Players  int64  `alias:"presets.players"`
+	Runs     int64  `alias:"presets.runs"`
+}
+
+// ListPopularPresets returns the custom presets with the most players in
+// [startTime, endTime), counting only scores that would show on their
+// leaderboard: under ruleset, or any ruleset if it is zero. Nil times leave
+// that end open.
+func ListPopularPresets(db *sql.DB, ruleset int16, startTime, endTime *time.Time, limit int64) ([]PresetRow, error) {
+	condition := visibleScore().AND(
+		table.Scores.GameMode.EQ(enum.GameMode.Custom),
+	).AND(
+		table.Scores.Score.GT(Int(0)),
+	)
+	if ruleset != 0 {
+		condition = condition.AND(table.Scores.Ruleset.EQ(Int16(ruleset)))
+	}
+	if startTime != nil {
+		condition = condition.AND(table.Scores.CreatedAt.GT_EQ(TimestampzT(*startTime)))
+	}
+	if endTime != nil {
+		condition = condition.AND(table.Scores.CreatedAt.LT(TimestampzT(*endTime)))
+	}
+
+	players := COUNT(DISTINCT(table.Scores.PlayerID))
+	runs := COUNT(STAR)
+
+	stmt := SELECT(
+		table.CustomPresets.Hash,
+		table.CustomPresets.Settings,
+		players.AS("presets.players"),
+		runs.AS("presets.runs"),
+	).FROM(
+		table.Scores.
+			INNER_JOIN(table.CustomPresets, table.CustomPresets.Hash.EQ(table.Scores.SettingsHash)).
+			LEFT_JOIN(table.BannedPlayers, activeBanOf(table.Scores.PlayerID)),
+	).WHERE(
+		condition,
+	).GROUP_BY(
+		table.CustomPresets.Hash,
+	).ORDER_BY(
+		players.DESC(),
+		runs.DESC(),
+		table.CustomPresets.Hash,
+	).LIMIT(limit)
+
+	rows := []PresetRow{}
+	if err := stmt.Query(db, &rows); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// GetPreset returns the custom preset with hash, or nil if there is none.
+func GetPreset(db *sql.DB, hash string) (*model.CustomPresets, error) {
+	stmt := SELECT(
+		table.CustomPresets.AllColumns,
+	).FROM(
+		table.CustomPresets,
+	).WHERE(
+		table.CustomPresets.Hash.EQ(String(hash)),
+	)
+
+	var dest model.CustomPresets
+	if err := stmt.Query(db, &dest); err != nil {
+		if err.Error() == "jet: sql: no rows in result set" {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &dest, nil
+}
@@ -0,0 +1,147 @@
+package db
+
+import (
+	"database/sql"
+
+	. "github.com/go-jet/jet/v2/postgres"
+	"github.com/google/uuid"
+
+	"rmpc-server/db/.gen/rmpc/public/enum"
+	"rmpc-server/db/.gen/rmpc/public/model"
+	"rmpc-server/db/.gen/rmpc/public/table"
+)
+
+type ProofInput struct {
+	ID          uuid.UUID
+	ScoreID     uuid.UUID
+	UploadedBy  uuid.UUID
+	BlobKey     string
+	Filename    string
+	ContentType string
+	SizeBytes   int64
+	Sha256      string
+}
+
+// proofFlagReason is recorded on a score that was held for a proof and is now
+// waiting for a moderator to check it.
+const proofFlagReason = "top run with a proof attached"
+
+// AttachProof records a proof file stored under input.BlobKey. A score held
+// for proof moves to the review queue in the same transaction, so a moderator
+// checks the proof before it reaches the boards; the returned bool reports
+// whether that happened.
+func AttachProof(db *sql.DB, input ProofInput) (model.ScoreProofs, bool, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return model.ScoreProofs{}, false, err
+	}
+	defer tx.Rollback()
+
+	insert := table.ScoreProofs.INSERT(
+		table.ScoreProofs.ID,
+		table.ScoreProofs.ScoreID,
+		table.ScoreProofs.UploadedBy,
+		table.ScoreProofs.BlobKey,
+		table.ScoreProofs.Filename,
+		table.ScoreProofs.ContentType,
+		table.ScoreProofs.SizeBytes,
+		table.ScoreProofs.Sha256,
+	).VALUES(
+		input.ID,
+		input.ScoreID,
+		input.UploadedBy,
+		input.BlobKey,
+		input.Filename,
+		input.ContentType,
+		input.SizeBytes,
+		input.Sha256,
+	).RETURNING(
+		table.ScoreProofs.AllColumns,
+	)
+
+	var dest model.ScoreProofs
+	if err := insert.Query(tx, &dest); err != nil {
+		return model.ScoreProofs{}, false, err
+	}
+
+	queue := table.Scores.UPDATE().SET(
+		table.Scores.ReviewStatus.SET(enum.ReviewStatus.PendingReview),
+		table.Scores.FlagReason.SET(String(proofFlagReason)),
+	).WHERE(AND(
+		table.Scores.ID.EQ(UUID(input.ScoreID)),
+		table.Scores.ReviewStatus.EQ(enum.ReviewStatus.PendingProof),
+	))
+	res, err := queue.Exec(tx)
+	if err != nil {
+		return model.ScoreProofs{}, false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return model.ScoreProofs{}, false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return model.ScoreProofs{}, false, err
+	}
+	return dest, n > 0, nil
+}
+
+// CountProofs returns how many proof files are attached to a score.
+func CountProofs(db *sql.DB, scoreID uuid.UUID) (int64, error) {
+	stmt := SELECT(
+		COUNT(STAR),
+	).FROM(
+		table.ScoreProofs,
+	).WHERE(
+		table.ScoreProofs.ScoreID.EQ(UUID(scoreID)),
+	)
+
+	var dest struct {
+		Count int64
+	}
+	if err := stmt.Query(db, &dest); err != nil {
+		return 0, err
+	}
+	return dest.Count, nil
+}
+
+// ListProofs returns the proofs of a score, oldest first. Only scores that are
+// publicly visible have their proofs listed, unless hidden is set for a
+// moderator checking scores held off the boards.
+func ListProofs(db *sql.DB, scoreID uuid.UUID, hidden bool) ([]model.ScoreProofs, error) {
+	return queryProofs(db, table.ScoreProofs.ScoreID.EQ(UUID(scoreID)), hidden)
+}
+
+// GetProof returns a proof of a publicly visible score, or of any score if
+// hidden is set, or nil if there is none.
+func GetProof(db *sql.DB, proofID uuid.UUID, hidden bool) (*model.ScoreProofs, error) {
+	rows, err := queryProofs(db, table.ScoreProofs.ID.EQ(UUID(proofID)), hidden)
+	if err != nil || len(rows) == 0 {
+		return nil, err
+	}
+	return &rows[0], nil
+}
+
+func queryProofs(db *sql.DB, condition BoolExpression, hidden bool) ([]model.ScoreProofs, error) {
+	if !hidden {
+		condition = AND(condition, visibleScore())
+	}
+
+	stmt := SELECT(
+		table.ScoreProofs.AllColumns,
+	).FROM(
+		table.ScoreProofs.
+			INNER_JOIN(table.Scores, table.Scores.ID.EQ(table.ScoreProofs.ScoreID)).
+			LEFT_JOIN(table.BannedPlayers, activeBanOf(table.Scores.PlayerID)),
+	).WHERE(
+		condition,
+	).ORDER_BY(
+		table.ScoreProofs.CreatedAt.ASC(),
+	)
+
+	rows := []model.ScoreProofs{}
+	if err := stmt.Query(db, &rows); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
@@ -0,0 +1,216 @@
+package db
+
+import (
+	"slices"
+	"time"
+
+	. "github.com/go-jet/jet/v2/postgres"
+	"github.com/google/uuid"
+
+	"rmpc-server/api/_pkg/ranking"
+	"rmpc-server/db/.gen/rmpc/public/table"
+)
+
+// RankedRun holds the values a run is ranked and listed by.
+type RankedRun struct {
+	Score         int32
+	MapsCompleted int32
+	MapsSkipped   int32
+	DurationMs    int32
+	Runs          int32 // the player's runs on the board, for ranking.ByRuns
+	CreatedAt     time.Time
+	PlayerID      uuid.UUID
+}
+
+// rankColumns are the columns of a run that rankings read, from scores itself
+// or from a subquery selecting them.
+type rankColumns struct {
+	Score         ColumnInteger
+	MapsCompleted ColumnInteger
+	MapsSkipped   ColumnInteger
+	DurationMs    ColumnInteger
+	Runs          ColumnInteger // only in bestScoresTable and tables built on it
+	CreatedAt     ColumnTimestampz
+	PlayerID      ColumnString
+}
+
+var scoreRankColumns = rankColumns{
+	Score:         table.Scores.Score,
+	MapsCompleted: table.Scores.MapsCompleted,
+	MapsSkipped:   table.Scores.MapsSkipped,
+	DurationMs:    table.Scores.DurationMs,
+	Runs:          IntegerColumn("runs"),
+	CreatedAt:     table.Scores.CreatedAt,
+	PlayerID:      table.Scores.PlayerID,
+}
+
+func (c rankColumns) From(subQuery SelectTable) rankColumns {
+	return rankColumns{
+		Score:         c.Score.From(subQuery),
+		MapsCompleted: c.MapsCompleted.From(subQuery),
+		MapsSkipped:   c.MapsSkipped.From(subQuery),
+		DurationMs:    c.DurationMs.From(subQuery),
+		Runs:          c.Runs.From(subQuery),
+		CreatedAt:     c.CreatedAt.From(subQuery),
+		PlayerID:      c.PlayerID.From(subQuery),
+	}
+}
+
+// sortKey is one column of an ordering, with how rows compare to a run on it.
+type sortKey struct {
+	order  OrderByClause
+	before func(RankedRun) BoolExpression // sorts strictly before the run
+	after  func(RankedRun) BoolExpression // sorts strictly after the run
+	equal  func(RankedRun) BoolExpression
+}
+
+func intKey(col ColumnInteger, desc bool, value func(RankedRun) int32) sortKey {
+	order, lt, gt := col.ASC(), col.LT, col.GT
+	if desc {
+		order, lt, gt = col.DESC(), col.GT, col.LT
+	}
+	return sortKey{
+		order:  order,
+		before: func(r RankedRun) BoolExpression { return lt(Int32(value(r))) },
+		after:  func(r RankedRun) BoolExpression { return gt(Int32(value(r))) },
+		equal:  func(r RankedRun) BoolExpression { return col.EQ(Int32(value(r))) },
+	}
+}
+
+const msPerHour = 3600000
+
+// scorePerHourKey orders by score per hour, highest first. Rows compare to a
+// run by cross-multiplying, which is exact; the double precision ratio rows
+// are sorted by never rounds two different ratios out of order or together.
+func (c rankColumns) scorePerHourKey() sortKey {
+	perHour := CAST(c.Score).AS_DOUBLE().MUL(Float(msPerHour)).DIV(CAST(c.DurationMs).AS_DOUBLE())
+	// the row's score times the run's duration, and the run's score times the
+	// row's duration: the row's rate is higher when the first is greater
+	cross := func(r RankedRun) (IntegerExpression, IntegerExpression) {
+		return c.Score.MUL(Int64(int64(r.DurationMs))), c.DurationMs.MUL(Int64(int64(r.Score)))
+	}
+	return sortKey{
+		order: perHour.DESC(),
+		before: func(r RankedRun) BoolExpression {
+			row, run := cross(r)
+			return row.GT(run)
+		},
+		after: func(r RankedRun) BoolExpression {
+			row, run := cross(r)
+			return row.LT(run)
+		},
+		equal: func(r RankedRun) BoolExpression {
+			row, run := cross(r)
+			return row.EQ(run)
+		},
+	}
+}
+
+// metricKey orders by metric, best first.
+func (c rankColumns) metricKey(metric string) sortKey {
+	switch metric {
+	case ranking.ByMapsCompleted:
+		return intKey(c.MapsCompleted, true, func(r RankedRun) int32 { return r.MapsCompleted })
+	case ranking.ByScorePerHour:
+		return c.scorePerHourKey()
+	case ranking.ByFewestSkips:
+		return intKey(c.MapsSkipped, false, func(r RankedRun) int32 { return r.MapsSkipped })
+	case ranking.ByRuns:
+		return intKey(c.Runs, true, func(r RankedRun) int32 { return r.Runs })
+	}
+	return intKey(c.Score, true, func(r RankedRun) int32 { return r.Score })
+}
+
+// rankKeys are the metric, score if the metric isn't score, and the
+// configured tie-breakers: runs equal on all of them share a rank.
+func (c rankColumns) rankKeys(metric string) []sortKey {
+	return c.rankKeysFor(metric, ranking.Chain())
+}
+
+// rankKeysFor are rankKeys with the given tie-breaker chain.
+func (c rankColumns) rankKeysFor(metric string, chain []string) []sortKey {
+	keys := []sortKey{c.metricKey(metric)}
+	if metric != ranking.ByScore {
+		keys = append(keys, c.metricKey(ranking.ByScore))
+	}
+	for _, name := range chain {
+		switch name {
+		case ranking.FewestSkips:
+			if metric != ranking.ByFewestSkips {
+				keys = append(keys, c.metricKey(ranking.ByFewestSkips))
+			}
+		case ranking.ShortestDuration:
+			keys = append(keys, intKey(c.DurationMs, false, func(r RankedRun) int32 { return r.DurationMs }))
+		case ranking.EarliestSubmission:
+			keys = append(keys, c.createdKey())
+		}
+	}
+	return keys
+}
+
+// createdKey orders by submission, earliest first.
+func (c rankColumns) createdKey() sortKey {
+	return sortKey{
+		order:  c.CreatedAt.ASC(),
+		before: func(r RankedRun) BoolExpression { return c.CreatedAt.LT(TimestampzT(r.CreatedAt)) },
+		after:  func(r RankedRun) BoolExpression { return c.CreatedAt.GT(TimestampzT(r.CreatedAt)) },
+		equal:  func(r RankedRun) BoolExpression { return c.CreatedAt.EQ(TimestampzT(r.CreatedAt)) },
+	}
+}
+
+// recordKeys extend rankKeys so that of runs sharing a rank the earliest
+// comes first, unless the chain already ranks by submission. It is the
+// player's best run, and holds a record.
+func (c rankColumns) recordKeys(metric string) []sortKey {
+	return c.recordKeysFor(metric, ranking.Chain())
+}
+
+// recordKeysFor are recordKeys with the given tie-breaker chain.
+func (c rankColumns) recordKeysFor(metric string, chain []string) []sortKey {
+	keys := c.rankKeysFor(metric, chain)
+	if slices.Contains(chain, ranking.EarliestSubmission) {
+		return keys
+	}
+	return append(keys, c.createdKey())
+}
+
+// listKeys extend recordKeys with the player ID to a total order of players'
+// runs, for stable pages.
+func (c rankColumns) listKeys(metric string) []sortKey {
+	return append(c.recordKeys(metric), sortKey{
+		order:  c.PlayerID.ASC(),
+		before: func(r RankedRun) BoolExpression { return c.PlayerID.LT(UUID(r.PlayerID)) },
+		after:  func(r RankedRun) BoolExpression { return c.PlayerID.GT(UUID(r.PlayerID)) },
+		equal:  func(r RankedRun) BoolExpression { return c.PlayerID.EQ(UUID(r.PlayerID)) },
+	})
+}
+
+func orderBy(keys []sortKey) []OrderByClause {
+	clauses := make([]OrderByClause, len(keys))
+	for i, k := range keys {
+		clauses[i] = k.order
+	}
+	return clauses
+}
+
+// sortsBefore matches rows ordered strictly before r by keys.
+func sortsBefore(keys []sortKey, r RankedRun) BoolExpression {
+	return compareKeys(keys, r, func(k sortKey) BoolExpression { return k.before(r) })
+}
+
+// sortsAfter matches rows ordered strictly after r by keys.
+func sortsAfter(keys []sortKey, r RankedRun) BoolExpression {
+	return compareKeys(keys, r, func(k sortKey) BoolExpression { return k.after(r) })
+}
+
+// compareKeys builds a lexicographic comparison: the row differs from r on
+// some key as differs says, and equals r on every key before that one.
+func compareKeys(keys []sortKey, r RankedRun, differs func(sortKey) BoolExpression) BoolExpression {
+	var alternatives []BoolExpression
+	var equalSoFar []BoolExpression
+	for _, k := range keys {
+		alternatives = append(alternatives, AND(append(equalSoFar, differs(k))...))
+		equalSoFar = append(equalSoFar, k.equal(r))
+	}
+	return OR(alternatives...)
+}
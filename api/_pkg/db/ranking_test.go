@@ -0,0 +1,198 @@
+package db
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/go-jet/jet/v2/postgres"
+	"github.com/google/uuid"
+
+	"rmpc-server/api/_pkg/ranking"
+	"rmpc-server/db/.gen/rmpc/public/table"
+)
+
+// orderSQL renders the ORDER BY of keys.
+func orderSQL(keys []sortKey) string {
+	sql := SELECT(table.Scores.ID).FROM(table.Scores).ORDER_BY(orderBy(keys)...).DebugSql()
+	_, order, _ := strings.Cut(strings.Join(strings.Fields(sql), " "), "ORDER BY ")
+	return strings.TrimSuffix(order, ";")
+}
+
+// whereSQL renders condition.
+func whereSQL(condition BoolExpression) string {
+	sql := SELECT(table.Scores.ID).FROM(table.Scores).WHERE(condition).DebugSql()
+	_, where, _ := strings.Cut(strings.Join(strings.Fields(sql), " "), "WHERE ")
+	return strings.TrimSuffix(where, ";")
+}
+
+func TestRankKeysFor(t *testing.T) {
+	tests := []struct {
+		name  string
+		chain []string
+		want  string
+	}{
+		{
+			name:  "default chain",
+			chain: ranking.DefaultChain,
+			want:  "scores.score DESC, scores.maps_skipped ASC, scores.duration_ms ASC, scores.created_at ASC",
+		},
+		{
+			name:  "reordered chain",
+			chain: []string{ranking.ShortestDuration, ranking.FewestSkips},
+			want:  "scores.score DESC, scores.duration_ms ASC, scores.maps_skipped ASC",
+		},
+		{"no tie-breakers", []string{}, "scores.score DESC"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := orderSQL(scoreRankColumns.rankKeysFor(ranking.ByScore, tt.chain)); got != tt.want {
+				t.Errorf("rankKeysFor() orders by %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecordKeysFor(t *testing.T) {
+	tests := []struct {
+		name  string
+		chain []string
+		want  string
+	}{
+		{
+			name:  "chain ranks by submission",
+			chain: ranking.DefaultChain,
+			want:  "scores.score DESC, scores.maps_skipped ASC, scores.duration_ms ASC, scores.created_at ASC",
+		},
+		{
+			name:  "earliest listed first",
+			chain: []string{ranking.FewestSkips},
+			want:  "scores.score DESC, scores.maps_skipped ASC, scores.created_at ASC",
+		},
+		{"no tie-breakers", []string{}, "scores.score DESC, scores.created_at ASC"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := orderSQL(scoreRankColumns.recordKeysFor(ranking.ByScore, tt.chain)); got != tt.want {
+				t.Errorf("recordKeysFor() orders by %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestListKeys(t *testing.T) {
+	want := "scores.score DESC, scores.maps_skipped ASC, scores.duration_ms ASC, scores.created_at ASC, scores.player_id ASC"
+	if got := orderSQL(scoreRankColumns.listKeys(ranking.ByScore)); got != want {
+		t.Errorf("listKeys() orders by %q, want %q", got, want)
+	}
+}
+
+func TestCompareKeys(t *testing.T) {
+	keys := scoreRankColumns.rankKeysFor(ranking.ByScore, []string{ranking.FewestSkips})
+	run := RankedRun{Score: 90000, MapsSkipped: 2}
+
+	tests := []struct {
+		name string
+		got  BoolExpression
+		want string
+	}{
+		{
+			name: "before",
+			got:  sortsBefore(keys, run),
+			want: "( (scores.score > 90000::integer) OR ( (scores.score = 90000::integer) AND (scores.maps_skipped < 2::integer) ) )",
+		},
+		{
+			name: "after",
+			got:  sortsAfter(keys, run),
+			want: "( (scores.score < 90000::integer) OR ( (scores.score = 90000::integer) AND (scores.maps_skipped > 2::integer) ) )",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := whereSQL(tt.got); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// The cursor of a page continues strictly after its last entry on every
+// listing key, so equal runs of different players are neither skipped nor
+// repeated.
+func TestCursorContinuesAfterEntry(t *testing.T) {
+	createdAt := time.Date(2026, 1, 10, 12, 0, 0, 0, time.UTC)
+	playerID := uuid.MustParse("6f1c1f9e-0000-4000-8000-000000000001")
+	entry := LeaderboardEntry{
+		Score:       90000,
+		MapsSkipped: 2,
+		DurationMs:  3600000,
+		PlayerID:    playerID,
+		CreatedAt:   &createdAt,
+	}
+
+	want := "( (scores.score < 90000::integer)" +
+		" OR ( (scores.score = 90000::integer) AND (scores.maps_skipped > 2::integer) )" +
+		" OR ( (scores.score = 90000::integer) AND (scores.maps_skipped = 2::integer)" +
+		" AND (scores.duration_ms > 3600000::integer) )" +
+		" OR ( (scores.score = 90000::integer) AND (scores.maps_skipped = 2::integer)" +
+		" AND (scores.duration_ms = 3600000::integer)" +
+		" AND (scores.created_at > '2026-01-10 12:00:00Z'::timestamp with time zone) )" +
+		" OR ( (scores.score = 90000::integer) AND (scores.maps_skipped = 2::integer)" +
+		" AND (scores.duration_ms = 3600000::integer)" +
+		" AND (scores.created_at = '2026-01-10 12:00:00Z'::timestamp with time zone)" +
+		" AND (scores.player_id > '6f1c1f9e-0000-4000-8000-000000000001'::uuid) ) )"
+	if got := whereSQL(sortsAfter(scoreRankColumns.listKeys(ranking.ByScore), entry.Cursor())); got != want {
+		t.Errorf("sortsAfter(cursor) = %q, want %q", got, want)
+	}
+}
+
+func TestRankKeysByMetric(t *testing.T) {
+	tests := []struct {
+		metric string
+		want   string
+	}{
+		{ranking.ByScore, "scores.score DESC, scores.maps_skipped ASC, scores.duration_ms ASC, scores.created_at ASC"},
+		{ranking.ByMapsCompleted, "scores.maps_completed DESC, scores.score DESC, scores.maps_skipped ASC, scores.duration_ms ASC, scores.created_at ASC"},
+		{ranking.ByScorePerHour, "(scores.score::double precision * 3600000) / scores.duration_ms::double precision DESC, scores.score DESC, scores.maps_skipped ASC, scores.duration_ms ASC, scores.created_at ASC"},
+		// Skips aren't repeated as a tie-breaker
+		{ranking.ByFewestSkips, "scores.maps_skipped ASC, scores.score DESC, scores.duration_ms ASC, scores.created_at ASC"},
+		{ranking.ByRuns, "runs DESC, scores.score DESC, scores.maps_skipped ASC, scores.duration_ms ASC, scores.created_at ASC"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.metric, func(t *testing.T) {
+			if got := orderSQL(scoreRankColumns.rankKeysFor(tt.metric, ranking.DefaultChain)); got != tt.want {
+				t.Errorf("rankKeysFor(%q) orders by %q, want %q", tt.metric, got, tt.want)
+			}
+		})
+	}
+}
+
+// Rows compare to a run on score per hour by cross-multiplying in bigint, so
+// no ratio is rounded and no product overflows an integer.
+func TestScorePerHourKey(t *testing.T) {
+	key := scoreRankColumns.scorePerHourKey()
+	run := RankedRun{Score: 600000, DurationMs: 3600000}
+	row, other := "(scores.score * 3600000::bigint)", "(scores.duration_ms * 600000::bigint)"
+
+	tests := []struct {
+		name string
+		got  BoolExpression
+		want string
+	}{
+		{"before", key.before(run), row + " > " + other},
+		{"after", key.after(run), row + " < " + other},
+		{"equal", key.equal(run), row + " = " + other},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := whereSQL(tt.got); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
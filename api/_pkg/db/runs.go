@@ -0,0 +1,179 @@
+package db
+
+import (
+	"database/sql"
+	"time"
+
+	. "github.com/go-jet/jet/v2/postgres"
+	"github.com/google/uuid"
+
+	"rmpc-server/db/.gen/rmpc/public/model"
+	"rmpc-server/db/.gen/rmpc/public/table"
+)
+
+// CreateRun records the start of a run. The player's unfinished runs older
+// than maxAge can no longer be finished and are deleted on the way, and once
+// the player has more than maxOpen unfinished runs the oldest are dropped.
+func CreateRun(db *sql.DB, playerID uuid.UUID, gameMode string, maxAge time.Duration, maxOpen int) (uuid.UUID, time.Time, error) {
+	cleanup := table.Runs.DELETE().WHERE(AND(
+		table.Runs.PlayerID.EQ(UUID(playerID)),
+		table.Runs.FinishedAt.IS_NULL(),
+		table.Runs.StartedAt.LT(TimestampzT(time.Now().Add(-maxAge))),
+	))
+	if _, err := cleanup.Exec(db); err != nil {
+		return uuid.Nil, time.Time{}, err
+	}
+
+	stmt := table.Runs.INSERT(
+		table.Runs.PlayerID,
+		table.Runs.GameMode,
+	).VALUES(
+		playerID,
+		gameMode,
+	).RETURNING(
+		table.Runs.ID,
+		table.Runs.StartedAt,
+	)
+
+	var dest model.Runs
+	if err := stmt.Query(db, &dest); err != nil {
+		return uuid.Nil, time.Time{}, err
+	}
+
+	// Keep the newest maxOpen unfinished runs; the one just inserted is
+	// always among them.
+	surplus := SELECT(
+		table.Runs.ID,
+	).FROM(
+		table.Runs,
+	).WHERE(AND(
+		table.Runs.PlayerID.EQ(UUID(playerID)),
+		table.Runs.FinishedAt.IS_NULL(),
+	)).ORDER_BY(
+		table.Runs.StartedAt.DESC(),
+	).OFFSET(int64(maxOpen))
+
+	delSurplus := table.Runs.DELETE().WHERE(
+		table.Runs.ID.IN(surplus),
+	)
+	if _, err := delSurplus.Exec(db); err != nil {
+		return uuid.Nil, time.Time{}, err
+	}
+
+	return dest.ID, dest.StartedAt, nil
+}
+
+// FindRun returns the player's run with the given ID, or nil if there is none.
+func FindRun(db *sql.DB, runID, playerID uuid.UUID) (*model.Runs, error) {
+	stmt := SELECT(
+		table.Runs.AllColumns,
+	).FROM(
+		table.Runs,
+	).WHERE(AND(
+		table.Runs.ID.EQ(UUID(runID)),
+		table.Runs.PlayerID.EQ(UUID(playerID)),
+	))
+
+	var dest model.Runs
+	err := stmt.Query(db, &dest)
+	if err != nil {
+		if err.Error() == "jet: sql: no rows in result set" {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &dest, nil
+}
+
+type RunMapInput struct {
+	Seq          int32
+	MapUID       string
+	Medal        string
+	TimeMs       *int32
+	TargetTimeMs int32
+	Skipped      bool
+	Broken       bool
+}
+
+// UpsertRunMaps stores map results for a run. A result for a seq that was
+// already reported replaces the earlier one, so the plugin can resend freely.
+func UpsertRunMaps(db *sql.DB, runID uuid.UUID, maps []RunMapInput) error {
+	stmt := table.RunMaps.INSERT(
+		table.RunMaps.RunID,
+		table.RunMaps.Seq,
+		table.RunMaps.MapUID,
+		table.RunMaps.Medal,
+		table.RunMaps.TimeMs,
+		table.RunMaps.TargetTimeMs,
+		table.RunMaps.Skipped,
+		table.RunMaps.Broken,
+	)
+	for _, m := range maps {
+		stmt = stmt.VALUES(
+			runID,
+			m.Seq,
+			m.MapUID,
+			m.Medal,
+			m.TimeMs,
+			m.TargetTimeMs,
+			m.Skipped,
+			m.Broken,
+		)
+	}
+	stmt = stmt.ON_CONFLICT(table.RunMaps.RunID, table.RunMaps.Seq).DO_UPDATE(
+		SET(
+			table.RunMaps.MapUID.SET(table.RunMaps.EXCLUDED.MapUID),
+			table.RunMaps.Medal.SET(table.RunMaps.EXCLUDED.Medal),
+			table.RunMaps.TimeMs.SET(table.RunMaps.EXCLUDED.TimeMs),
+			table.RunMaps.TargetTimeMs.SET(table.RunMaps.EXCLUDED.TargetTimeMs),
+			table.RunMaps.Skipped.SET(table.RunMaps.EXCLUDED.Skipped),
+			table.RunMaps.Broken.SET(table.RunMaps.EXCLUDED.Broken),
+		),
+	)
+
+	_, err := stmt.Exec(db)
+	return err
+}
+
+// ListRunMaps returns a run's map results in play order.
+func ListRunMaps(db *sql.DB, runID uuid.UUID) ([]model.RunMaps, error) {
+	stmt := SELECT(
+		table.RunMaps.AllColumns,
+	).FROM(
+		table.RunMaps,
+	).WHERE(
+		table.RunMaps.RunID.EQ(UUID(runID)),
+	).ORDER_BY(
+		table.RunMaps.Seq.ASC(),
+	)
+
+	rows := []model.RunMaps{}
+	if err := stmt.Query(db, &rows); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// GetScoreRunMaps returns the map results behind a publicly visible score, in
+// play order. Scores without a run, hidden scores and unknown IDs all yield
+// no rows.
+func GetScoreRunMaps(db *sql.DB, scoreID uuid.UUID) ([]model.RunMaps, error) {
+	stmt := SELECT(
+		table.RunMaps.AllColumns,
+	).FROM(
+		table.Scores.
+			INNER_JOIN(table.RunMaps, table.RunMaps.RunID.EQ(table.Scores.RunID)).
+			LEFT_JOIN(table.BannedPlayers, activeBanOf(table.Scores.PlayerID)),
+	).WHERE(AND(
+		table.Scores.ID.EQ(UUID(scoreID)),
+		visibleScore(),
+	)).ORDER_BY(
+		table.RunMaps.Seq.ASC(),
+	)
+
+	rows := []model.RunMaps{}
+	if err := stmt.Query(db, &rows); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
@@ -2,11 +2,15 @@ package db
 
 import (
 	"database/sql"
+	"errors"
 	"time"
 
 	. "github.com/go-jet/jet/v2/postgres"
+	"github.com/go-jet/jet/v2/qrm"
 	"github.com/google/uuid"
 
+	"rmpc-server/api/_pkg/config"
+	"rmpc-server/api/_pkg/runs"
 	"rmpc-server/db/.gen/rmpc/public/model"
 	"rmpc-server/db/.gen/rmpc/public/table"
 )
@@ -19,9 +23,87 @@ type ScoreInput struct {
 	MapsSkipped   int32
 	DurationMs    int32
 	Metadata      *string
+	// Promoted from versioned metadata, nil for legacy metadata
+	MetadataVersion *int16
+	PluginVersion   *string
+	GameVersion     *string
+	// Custom preset of the run: its hash and canonical settings JSON
+	SettingsHash  *string
+	Settings      *string
+	Ruleset       int16      // scoring ruleset of the plugin that sent the run
+	RunID         *uuid.UUID // server-tracked run the score finishes, if any
+	FlagReason    *string    // why the submission looks wrong, if it does
+	PendingReview bool       // hold the score off the public boards for review
+	PendingProof  bool       // hold the score off the public boards until a proof is attached
+	// Set for runs uploaded in a batch after they were played
+	CreatedAt      *time.Time // when the run finished; nil means now
+	BatchSessionID *uuid.UUID
+	// Idempotency-Key reserved by the request storing the score, if any
+	IdempotencyKey *string
 }
 
+var (
+	// ErrRunFinished is returned by InsertScore when the run already has a score.
+	ErrRunFinished = errors.New("run already finished")
+	// ErrBatchLimit is returned by InsertScore for a batched score over the
+	// player's BATCH_PLAYER_DAILY_LIMIT.
+	ErrBatchLimit = errors.New("daily batch upload limit reached")
+	// ErrRunOverlaps is returned by InsertScore for a batched score played
+	// during another run of the player.
+	ErrRunOverlaps = errors.New("run overlaps another run of the player")
+)
+
+// InsertScore stores a score. With a RunID the run is marked finished in the
+// same transaction, so a run can never yield two scores, and so is an
+// IdempotencyKey, so a retry can never store it again. Batched scores are
+// checked against the player's daily limit and other runs under a lock on the
+// player, so concurrent batches can't both slip through.
 func InsertScore(db *sql.DB, input ScoreInput) (uuid.UUID, time.Time, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return uuid.Nil, time.Time{}, err
+	}
+	defer tx.Rollback()
+
+	if input.BatchSessionID != nil {
+		if err := checkBatched(tx, input); err != nil {
+			return uuid.Nil, time.Time{}, err
+		}
+	}
+
+	if input.RunID != nil {
+		finish := table.Runs.UPDATE().SET(
+			table.Runs.FinishedAt.SET(TimestampzExpression(NOW())),
+		).WHERE(AND(
+			table.Runs.ID.EQ(UUID(*input.RunID)),
+			table.Runs.FinishedAt.IS_NULL(),
+		))
+		res, err := finish.Exec(tx)
+		if err != nil {
+			return uuid.Nil, time.Time{}, err
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return uuid.Nil, time.Time{}, err
+		}
+		if n == 0 {
+			return uuid.Nil, time.Time{}, ErrRunFinished
+		}
+	}
+
+	if input.SettingsHash != nil {
+		preset := table.CustomPresets.INSERT(
+			table.CustomPresets.Hash,
+			table.CustomPresets.Settings,
+		).VALUES(
+			*input.SettingsHash,
+			input.Settings,
+		).ON_CONFLICT(table.CustomPresets.Hash).DO_NOTHING()
+		if _, err := preset.Exec(tx); err != nil {
+			return uuid.Nil, time.Time{}, err
+		}
+	}
+
 	stmt := table.Scores.INSERT(
 		table.Scores.PlayerID,
 		table.Scores.GameMode,
@@ -30,6 +112,17 @@ func InsertScore(db *sql.DB, input ScoreInput) (uuid.UUID, time.Time, error) {
 		table.Scores.MapsSkipped,
 		table.Scores.DurationMs,
 		table.Scores.Metadata,
+		table.Scores.MetadataVersion,
+		table.Scores.PluginVersion,
+		table.Scores.GameVersion,
+		table.Scores.SettingsHash,
+		table.Scores.Ruleset,
+		table.Scores.RunID,
+		table.Scores.FlagReason,
+		table.Scores.ReviewStatus,
+		table.Scores.CreatedAt,
+		table.Scores.BatchSessionID,
+		table.Scores.Batched,
 	).VALUES(
 		input.PlayerID,
 		input.GameMode,
@@ -38,14 +131,40 @@ func InsertScore(db *sql.DB, input ScoreInput) (uuid.UUID, time.Time, error) {
 		input.MapsSkipped,
 		input.DurationMs,
 		input.Metadata,
+		input.MetadataVersion,
+		input.PluginVersion,
+		input.GameVersion,
+		input.SettingsHash,
+		input.Ruleset,
+		input.RunID,
+		input.FlagReason,
+		reviewStatus(input),
+		createdAt(input.CreatedAt),
+		input.BatchSessionID,
+		input.BatchSessionID != nil,
 	).RETURNING(
 		table.Scores.ID,
 		table.Scores.CreatedAt,
 	)
 
 	var dest model.Scores
-	err := stmt.Query(db, &dest)
-	if err != nil {
+	if err := stmt.Query(tx, &dest); err != nil {
+		return uuid.Nil, time.Time{}, err
+	}
+
+	if input.IdempotencyKey != nil {
+		link := table.IdempotencyKeys.UPDATE().SET(
+			table.IdempotencyKeys.ScoreID.SET(UUID(dest.ID)),
+		).WHERE(AND(
+			table.IdempotencyKeys.PlayerID.EQ(UUID(input.PlayerID)),
+			table.IdempotencyKeys.Key.EQ(String(*input.IdempotencyKey)),
+		))
+		if _, err := link.Exec(tx); err != nil {
+			return uuid.Nil, time.Time{}, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
 		return uuid.Nil, time.Time{}, err
 	}
 
@@ -56,6 +175,89 @@ func InsertScore(db *sql.DB, input ScoreInput) (uuid.UUID, time.Time, error) {
 	return dest.ID, createdAt, nil
 }
 
+// checkBatched locks the player until tx ends and checks that a batched score
+// still fits the daily limit and overlaps none of the player's runs.
+func checkBatched(tx *sql.Tx, input ScoreInput) error {
+	lock := SELECT(
+		table.Players.ID,
+	).FROM(
+		table.Players,
+	).WHERE(
+		table.Players.ID.EQ(UUID(input.PlayerID)),
+	).FOR(UPDATE())
+	var player model.Players
+	if err := lock.Query(tx, &player); err != nil {
+		return err
+	}
+
+	used, err := countBatchScores(tx, input.PlayerID, time.Now().Add(-24*time.Hour))
+	if err != nil {
+		return err
+	}
+	if used >= int64(config.Env.BatchPlayerDailyLimit) {
+		return ErrBatchLimit
+	}
+
+	span := runs.Span{
+		FinishedAt: *input.CreatedAt,
+		Duration:   time.Duration(input.DurationMs) * time.Millisecond,
+	}
+	// Runs last at most two hours, so later scores can't overlap this one
+	rows, err := listPlayerScoreSpans(tx, input.PlayerID, span.StartedAt(), span.FinishedAt.Add(2*time.Hour))
+	if err != nil {
+		return err
+	}
+	for _, row := range rows {
+		other := runs.Span{
+			FinishedAt: row.CreatedAt,
+			Duration:   time.Duration(row.DurationMs) * time.Millisecond,
+		}
+		if span.Overlaps(other) {
+			return ErrRunOverlaps
+		}
+	}
+	return nil
+}
+
+func createdAt(t *time.Time) any {
+	if t == nil {
+		return DEFAULT
+	}
+	return *t
+}
+
+// reviewStatus puts a score that needs both review and a proof in review; a
+// proof attached meanwhile doesn't release it.
+func reviewStatus(input ScoreInput) string {
+	switch {
+	case input.PendingReview:
+		return model.ReviewStatus_PendingReview.String()
+	case input.PendingProof:
+		return model.ReviewStatus_PendingProof.String()
+	}
+	return model.ReviewStatus_Accepted.String()
+}
+
+// FindScore returns a score by ID, or nil if there is none.
+func FindScore(db *sql.DB, scoreID uuid.UUID) (*model.Scores, error) {
+	stmt := SELECT(
+		table.Scores.AllColumns,
+	).FROM(
+		table.Scores,
+	).WHERE(
+		table.Scores.ID.EQ(UUID(scoreID)),
+	)
+
+	var dest model.Scores
+	if err := stmt.Query(db, &dest); err != nil {
+		if err.Error() == "jet: sql: no rows in result set" {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &dest, nil
+}
+
 func CanSubmitScore(db *sql.DB, playerID uuid.UUID, cooldown time.Duration) (bool, error) {
 	stmt := SELECT(
 		COUNT(STAR),
@@ -78,14 +280,73 @@ func CanSubmitScore(db *sql.DB, playerID uuid.UUID, cooldown time.Duration) (boo
 	return dest.Count == 0, nil
 }
 
+type ScoreSpanRow struct {
+	CreatedAt  time.Time `alias:"scores.created_at"`
+	DurationMs int32     `alias:"scores.duration_ms"`
+}
+
+// ListPlayerScoreSpans returns when each of the player's scores that finished
+// in [from, to) was played, including removed and unreviewed ones.
+func ListPlayerScoreSpans(db *sql.DB, playerID uuid.UUID, from, to time.Time) ([]ScoreSpanRow, error) {
+	return listPlayerScoreSpans(db, playerID, from, to)
+}
+
+func listPlayerScoreSpans(db qrm.Queryable, playerID uuid.UUID, from, to time.Time) ([]ScoreSpanRow, error) {
+	stmt := SELECT(
+		table.Scores.CreatedAt,
+		table.Scores.DurationMs,
+	).FROM(
+		table.Scores,
+	).WHERE(AND(
+		table.Scores.PlayerID.EQ(UUID(playerID)),
+		table.Scores.CreatedAt.GT_EQ(TimestampzT(from)),
+		table.Scores.CreatedAt.LT(TimestampzT(to)),
+	))
+
+	rows := []ScoreSpanRow{}
+	if err := stmt.Query(db, &rows); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// CountBatchScores returns how many scores the player has uploaded in
+// batches since the given time, from any session.
+func CountBatchScores(db *sql.DB, playerID uuid.UUID, since time.Time) (int64, error) {
+	return countBatchScores(db, playerID, since)
+}
+
+func countBatchScores(db qrm.Queryable, playerID uuid.UUID, since time.Time) (int64, error) {
+	stmt := SELECT(
+		COUNT(STAR),
+	).FROM(
+		table.Scores,
+	).WHERE(AND(
+		table.Scores.PlayerID.EQ(UUID(playerID)),
+		table.Scores.Batched.IS_TRUE(),
+		table.Scores.SubmittedAt.GT_EQ(TimestampzT(since)),
+	))
+
+	var dest struct {
+		Count int64
+	}
+	if err := stmt.Query(db, &dest); err != nil {
+		return 0, err
+	}
+	return dest.Count, nil
+}
+
+// IsPlayerBanned reports whether the player has a ban in force. Lifted and
+// expired bans don't count.
 func IsPlayerBanned(db *sql.DB, playerID uuid.UUID) (bool, error) {
 	stmt := SELECT(
 		COUNT(STAR),
 	).FROM(
 		table.BannedPlayers,
-	).WHERE(
+	).WHERE(AND(
 		table.BannedPlayers.PlayerID.EQ(UUID(playerID)),
-	)
+		activeBan(),
+	))
 
 	var dest struct {
 		Count int64
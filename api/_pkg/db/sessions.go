@@ -11,40 +11,104 @@ import (
 	"rmpc-server/db/.gen/rmpc/public/table"
 )
 
-func CreateSession(db *sql.DB, playerID uuid.UUID, tokenHash string, expiresAt time.Time) error {
-	// Delete existing sessions for this player (one session per player)
-	delStmt := table.Sessions.DELETE().WHERE(
-		table.Sessions.PlayerID.EQ(UUID(playerID)),
+type SessionInput struct {
+	PlayerID    uuid.UUID
+	TokenHash   string
+	DeviceLabel *string
+	ExpiresAt   time.Time
+}
+
+// CreateSession stores a new session next to the player's existing ones.
+// Expired sessions are cleaned up, and once the player holds more than
+// maxSessions the least recently used ones are dropped.
+func CreateSession(db *sql.DB, input SessionInput, maxSessions int) (uuid.UUID, error) {
+	delExpired := table.Sessions.DELETE().WHERE(
+		table.Sessions.PlayerID.EQ(UUID(input.PlayerID)).
+			AND(table.Sessions.ExpiresAt.LT(TimestampzExpression(NOW()))),
 	)
-	if _, err := delStmt.Exec(db); err != nil {
-		return err
+	if _, err := delExpired.Exec(db); err != nil {
+		return uuid.Nil, err
 	}
 
 	stmt := table.Sessions.INSERT(
 		table.Sessions.PlayerID,
 		table.Sessions.TokenHash,
+		table.Sessions.DeviceLabel,
 		table.Sessions.ExpiresAt,
 	).VALUES(
-		playerID,
-		tokenHash,
-		expiresAt,
+		input.PlayerID,
+		input.TokenHash,
+		input.DeviceLabel,
+		input.ExpiresAt,
+	).RETURNING(
+		table.Sessions.ID,
 	)
 
-	_, err := stmt.Exec(db)
-	return err
+	var dest model.Sessions
+	if err := stmt.Query(db, &dest); err != nil {
+		return uuid.Nil, err
+	}
+
+	// Keep the newest maxSessions by last use; the one just inserted is
+	// always among them.
+	surplus := SELECT(
+		table.Sessions.ID,
+	).FROM(
+		table.Sessions,
+	).WHERE(
+		table.Sessions.PlayerID.EQ(UUID(input.PlayerID)),
+	).ORDER_BY(
+		table.Sessions.LastUsedAt.DESC().NULLS_LAST(),
+		table.Sessions.CreatedAt.DESC(),
+	).OFFSET(int64(maxSessions))
+
+	delSurplus := table.Sessions.DELETE().WHERE(
+		table.Sessions.ID.IN(surplus),
+	)
+	if _, err := delSurplus.Exec(db); err != nil {
+		return uuid.Nil, err
+	}
+
+	return dest.ID, nil
 }
 
 type Session struct {
-	ID        uuid.UUID
-	PlayerID  uuid.UUID
-	ExpiresAt time.Time
+	ID          uuid.UUID
+	PlayerID    uuid.UUID
+	DeviceLabel *string
+	CreatedAt   time.Time
+	LastUsedAt  time.Time
+	ExpiresAt   time.Time
+}
+
+func sessionFromModel(m model.Sessions) Session {
+	s := Session{
+		ID:          m.ID,
+		PlayerID:    m.PlayerID,
+		DeviceLabel: m.DeviceLabel,
+		ExpiresAt:   m.ExpiresAt,
+	}
+	if m.CreatedAt != nil {
+		s.CreatedAt = *m.CreatedAt
+	}
+	if m.LastUsedAt != nil {
+		s.LastUsedAt = *m.LastUsedAt
+	}
+	return s
+}
+
+var sessionColumns = ProjectionList{
+	table.Sessions.ID,
+	table.Sessions.PlayerID,
+	table.Sessions.DeviceLabel,
+	table.Sessions.CreatedAt,
+	table.Sessions.LastUsedAt,
+	table.Sessions.ExpiresAt,
 }
 
 func FindSessionByTokenHash(db *sql.DB, tokenHash string) (*Session, error) {
 	stmt := SELECT(
-		table.Sessions.ID,
-		table.Sessions.PlayerID,
-		table.Sessions.ExpiresAt,
+		sessionColumns,
 	).FROM(
 		table.Sessions,
 	).WHERE(
@@ -59,9 +123,129 @@ func FindSessionByTokenHash(db *sql.DB, tokenHash string) (*Session, error) {
 		}
 		return nil, err
 	}
-	return &Session{
-		ID:        dest.ID,
-		PlayerID:  dest.PlayerID,
-		ExpiresAt: dest.ExpiresAt,
-	}, nil
+	session := sessionFromModel(dest)
+	return &session, nil
+}
+
+// ListSessions returns the player's unexpired sessions, most recently used first.
+func ListSessions(db *sql.DB, playerID uuid.UUID) ([]Session, error) {
+	stmt := SELECT(
+		sessionColumns,
+	).FROM(
+		table.Sessions,
+	).WHERE(
+		table.Sessions.PlayerID.EQ(UUID(playerID)).
+			AND(table.Sessions.ExpiresAt.GT(TimestampzExpression(NOW()))),
+	).ORDER_BY(
+		table.Sessions.LastUsedAt.DESC().NULLS_LAST(),
+		table.Sessions.CreatedAt.DESC(),
+	)
+
+	var dest []model.Sessions
+	if err := stmt.Query(db, &dest); err != nil {
+		return nil, err
+	}
+
+	sessions := make([]Session, len(dest))
+	for i, m := range dest {
+		sessions[i] = sessionFromModel(m)
+	}
+	return sessions, nil
+}
+
+// TouchSession bumps last_used_at, skipping the write when the session was
+// already marked as used within the last interval. A non-nil expiresAt moves
+// the session's expiry along with it (sliding expiry).
+func TouchSession(db *sql.DB, sessionID uuid.UUID, interval time.Duration, expiresAt *time.Time) error {
+	var newExpiry TimestampzExpression = table.Sessions.ExpiresAt
+	if expiresAt != nil {
+		newExpiry = TimestampzT(*expiresAt)
+	}
+
+	stmt := table.Sessions.UPDATE().SET(
+		table.Sessions.LastUsedAt.SET(TimestampzExpression(NOW())),
+		table.Sessions.ExpiresAt.SET(newExpiry),
+	).WHERE(
+		table.Sessions.ID.EQ(UUID(sessionID)).AND(
+			table.Sessions.LastUsedAt.IS_NULL().OR(
+				table.Sessions.LastUsedAt.LT(TimestampzExpression(NOW().SUB(INTERVALd(interval)))),
+			),
+		),
+	)
+
+	_, err := stmt.Exec(db)
+	return err
+}
+
+// RotateSession replaces a session's token hash and expiry in place, so the
+// old token stops working immediately. Matching on the old hash makes two
+// concurrent rotations of the same token fail instead of both succeeding.
+// Reports whether the session was rotated.
+func RotateSession(db *sql.DB, sessionID uuid.UUID, oldHash, newHash string, expiresAt time.Time) (bool, error) {
+	stmt := table.Sessions.UPDATE().SET(
+		table.Sessions.TokenHash.SET(String(newHash)),
+		table.Sessions.ExpiresAt.SET(TimestampzT(expiresAt)),
+		table.Sessions.LastUsedAt.SET(TimestampzExpression(NOW())),
+	).WHERE(
+		table.Sessions.ID.EQ(UUID(sessionID)).
+			AND(table.Sessions.TokenHash.EQ(String(oldHash))),
+	)
+
+	res, err := stmt.Exec(db)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// DeleteSession revokes a single session. The player ID is part of the match
+// so a player can only revoke their own sessions. Reports whether a row was
+// deleted.
+func DeleteSession(db *sql.DB, playerID, sessionID uuid.UUID) (bool, error) {
+	stmt := table.Sessions.DELETE().WHERE(
+		table.Sessions.ID.EQ(UUID(sessionID)).
+			AND(table.Sessions.PlayerID.EQ(UUID(playerID))),
+	)
+
+	res, err := stmt.Exec(db)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// DeleteSessionByTokenHash revokes the session a token belongs to and returns
+// it, or nil if there was none.
+func DeleteSessionByTokenHash(db *sql.DB, tokenHash string) (*model.Sessions, error) {
+	stmt := table.Sessions.DELETE().WHERE(
+		table.Sessions.TokenHash.EQ(String(tokenHash)),
+	).RETURNING(
+		table.Sessions.ID,
+		table.Sessions.PlayerID,
+	)
+
+	var dest model.Sessions
+	if err := stmt.Query(db, &dest); err != nil {
+		if err.Error() == "jet: sql: no rows in result set" {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &dest, nil
+}
+
+// DeleteSessions revokes every session of the player and returns how many
+// were removed.
+func DeleteSessions(db *sql.DB, playerID uuid.UUID) (int64, error) {
+	stmt := table.Sessions.DELETE().WHERE(
+		table.Sessions.PlayerID.EQ(UUID(playerID)),
+	)
+
+	res, err := stmt.Exec(db)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
 }
@@ -6,6 +6,7 @@ import (
 
 	. "github.com/go-jet/jet/v2/postgres"
 
+	"rmpc-server/api/_pkg/ranking"
 	"rmpc-server/db/.gen/rmpc/public/enum"
 	"rmpc-server/db/.gen/rmpc/public/table"
 )
@@ -18,17 +19,23 @@ type WorldRecord struct {
 	CreatedAt   *time.Time `alias:"scores.created_at"`
 }
 
+// WorldRecordParams select the scores records are taken from. A zero Ruleset
+// mixes all rulesets.
 type WorldRecordParams struct {
+	Ruleset   int16
 	StartTime *time.Time
 	EndTime   *time.Time
 }
 
 func GetWorldRecords(db *sql.DB, params WorldRecordParams) ([]WorldRecord, error) {
-	condition := table.BannedPlayers.ID.IS_NULL().AND(
+	condition := visibleScore().AND(
 		table.Scores.GameMode.IN(enum.GameMode.Author, enum.GameMode.Gold),
 	).AND(
 		table.Scores.Score.GT(Int(0)),
 	)
+	if params.Ruleset != 0 {
+		condition = condition.AND(table.Scores.Ruleset.EQ(Int16(params.Ruleset)))
+	}
 	if params.StartTime != nil {
 		condition = condition.AND(table.Scores.CreatedAt.GT_EQ(TimestampzT(*params.StartTime)))
 	}
@@ -36,7 +43,8 @@ func GetWorldRecords(db *sql.DB, params WorldRecordParams) ([]WorldRecord, error
 		condition = condition.AND(table.Scores.CreatedAt.LT(TimestampzT(*params.EndTime)))
 	}
 
-	// Best score per game_mode using DISTINCT ON, excluding banned players
+	// Best run per game_mode using DISTINCT ON, excluding banned players and removed or unreviewed scores.
+	// Runs are ordered as on the leaderboard, so of runs sharing rank 1 the earliest holds the record.
 	stmt := SELECT(
 		table.Scores.GameMode,
 		table.Scores.Score,
@@ -48,12 +56,11 @@ func GetWorldRecords(db *sql.DB, params WorldRecordParams) ([]WorldRecord, error
 	).FROM(
 		table.Scores.
 			INNER_JOIN(table.Players, table.Players.ID.EQ(table.Scores.PlayerID)).
-			LEFT_JOIN(table.BannedPlayers, table.BannedPlayers.PlayerID.EQ(table.Scores.PlayerID)),
+			LEFT_JOIN(table.BannedPlayers, activeBanOf(table.Scores.PlayerID)),
 	).WHERE(
 		condition,
 	).ORDER_BY(
-		table.Scores.GameMode,
-		table.Scores.Score.DESC(),
+		append([]OrderByClause{table.Scores.GameMode}, orderBy(scoreRankColumns.recordKeys(ranking.ByScore))...)...,
 	)
 
 	var records []WorldRecord
@@ -0,0 +1,94 @@
+package metadata
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"rmpc-server/api/_pkg/validate"
+)
+
+// CurrentVersion is the newest metadata schema version the server accepts.
+const CurrentVersion = 1
+
+// Limits for metadata without a "version" key, as sent by older plugins.
+const (
+	MaxSize       = 256 * 1024
+	maxLegacyKeys = 10
+)
+
+// V1 is version 1 of the run metadata the plugin attaches to a score.
+type V1 struct {
+	Version       int        `json:"version"`
+	PluginVersion string     `json:"plugin_version" validate:"required,max=32"`
+	GameVersion   string     `json:"game_version"   validate:"required,max=64"`
+	MapFilters    MapFilters `json:"map_filters"`
+	Settings      Settings   `json:"settings"`
+	Maps          []Map      `json:"maps"           validate:"max=500,dive"`
+}
+
+// MapFilters restrict which maps the random map picker may serve.
+type MapFilters struct {
+	MinLengthMs  int32    `json:"min_length_ms" validate:"gte=0"`
+	MaxLengthMs  int32    `json:"max_length_ms" validate:"gte=0"`
+	Tags         []string `json:"tags"          validate:"max=20,dive,required,max=32"`
+	ExcludedTags []string `json:"excluded_tags" validate:"max=20,dive,required,max=32"`
+	Difficulty   string   `json:"difficulty"    validate:"omitempty,max=32"`
+}
+
+// Settings are the run options chosen in the plugin.
+type Settings struct {
+	TimeLimitMs int32 `json:"time_limit_ms" validate:"gte=0,lte=7200000"`
+	FreeSkips   int32 `json:"free_skips"    validate:"gte=0,lte=100"`
+	SkipPenalty bool  `json:"skip_penalty"`
+}
+
+// Map is a map as listed in the metadata, in play order.
+type Map struct {
+	MapUID  string `json:"map_uid" validate:"required,max=64"`
+	Name    string `json:"name"    validate:"max=128"`
+	Medal   string `json:"medal"   validate:"omitempty,oneof=none bronze silver gold author"`
+	TimeMs  *int32 `json:"time_ms" validate:"omitempty,gte=1"`
+	Skipped bool   `json:"skipped"`
+}
+
+// Parse validates raw run metadata. Versioned metadata is decoded strictly
+// against its schema and returned; legacy metadata without a "version" key is
+// only checked for size and key count, and yields nil.
+func Parse(raw json.RawMessage) (*V1, error) {
+	if len(raw) > MaxSize {
+		return nil, fmt.Errorf("metadata must not exceed 256KB")
+	}
+
+	var header map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &header); err != nil || header == nil {
+		return nil, fmt.Errorf("metadata must be a JSON object")
+	}
+
+	version, ok := header["version"]
+	if !ok {
+		if len(header) > maxLegacyKeys {
+			return nil, fmt.Errorf("metadata must not have more than 10 keys")
+		}
+		return nil, nil
+	}
+
+	var v int
+	if err := json.Unmarshal(version, &v); err != nil || v != CurrentVersion {
+		return nil, fmt.Errorf("unsupported metadata version %s", version)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	var m V1
+	if err := dec.Decode(&m); err != nil {
+		return nil, fmt.Errorf("invalid metadata: %v", err)
+	}
+	if err := validate.Struct(m); err != nil {
+		return nil, fmt.Errorf("invalid metadata: %s", validate.FormatError(err))
+	}
+	if m.MapFilters.MaxLengthMs > 0 && m.MapFilters.MaxLengthMs < m.MapFilters.MinLengthMs {
+		return nil, fmt.Errorf("invalid metadata: map_filters.max_length_ms must not be below min_length_ms")
+	}
+	return &m, nil
+}
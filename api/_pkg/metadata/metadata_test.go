@@ -0,0 +1,85 @@
+package metadata
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	valid := `{
+		"version": 1,
+		"plugin_version": "1.4.0",
+		"game_version": "2026-05-12_17_00",
+		"map_filters": {"min_length_ms": 15000, "max_length_ms": 180000, "tags": ["Tech", "Dirt"]},
+		"settings": {"time_limit_ms": 3600000, "free_skips": 1},
+		"maps": [{"map_uid": "abc123", "name": "Some Map", "medal": "author", "time_ms": 41234}]
+	}`
+
+	tests := []struct {
+		name        string
+		raw         string
+		wantVersion bool
+		wantErr     string
+	}{
+		{name: "valid v1", raw: valid, wantVersion: true},
+		{name: "legacy object", raw: `{"anything": "goes", "n": 3}`},
+		{name: "legacy too many keys", raw: `{"a":1,"b":2,"c":3,"d":4,"e":5,"f":6,"g":7,"h":8,"i":9,"j":10,"k":11}`, wantErr: "more than 10 keys"},
+		{name: "not an object", raw: `[1, 2]`, wantErr: "must be a JSON object"},
+		{name: "null", raw: `null`, wantErr: "must be a JSON object"},
+		{name: "unknown version", raw: `{"version": 7}`, wantErr: "unsupported metadata version 7"},
+		{name: "non-numeric version", raw: `{"version": "1"}`, wantErr: "unsupported metadata version"},
+		{name: "missing required fields", raw: `{"version": 1}`, wantErr: "plugin_version is required; game_version is required"},
+		{name: "unknown field", raw: `{"version": 1, "plugin_version": "1.4.0", "game_version": "x", "extra": true}`, wantErr: `unknown field "extra"`},
+		{
+			name:    "nested field error has its path",
+			raw:     `{"version": 1, "plugin_version": "1.4.0", "game_version": "x", "maps": [{"map_uid": "a"}, {"medal": "platinum"}]}`,
+			wantErr: "maps[1].map_uid is required; maps[1].medal must be",
+		},
+		{
+			name:    "tag too long",
+			raw:     `{"version": 1, "plugin_version": "1.4.0", "game_version": "x", "map_filters": {"tags": ["` + strings.Repeat("t", 33) + `"]}}`,
+			wantErr: "map_filters.tags[0] must not exceed 32 characters",
+		},
+		{
+			name:    "inverted length filter",
+			raw:     `{"version": 1, "plugin_version": "1.4.0", "game_version": "x", "map_filters": {"min_length_ms": 60000, "max_length_ms": 30000}}`,
+			wantErr: "max_length_ms must not be below min_length_ms",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := Parse(json.RawMessage(tt.raw))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Parse() error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if (m != nil) != tt.wantVersion {
+				t.Fatalf("Parse() = %+v, want versioned = %v", m, tt.wantVersion)
+			}
+		})
+	}
+}
+
+func TestParseValidFields(t *testing.T) {
+	m, err := Parse(json.RawMessage(`{"version": 1, "plugin_version": "1.4.0", "game_version": "2026-05-12", "settings": {"free_skips": 2}}`))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if m.PluginVersion != "1.4.0" || m.GameVersion != "2026-05-12" || m.Settings.FreeSkips != 2 {
+		t.Errorf("Parse() = %+v", m)
+	}
+}
+
+func TestParseTooLarge(t *testing.T) {
+	raw := `{"blob": "` + strings.Repeat("x", MaxSize) + `"}`
+	if _, err := Parse(json.RawMessage(raw)); err == nil || !strings.Contains(err.Error(), "256KB") {
+		t.Errorf("Parse() error = %v, want size error", err)
+	}
+}
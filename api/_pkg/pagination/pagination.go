@@ -0,0 +1,47 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// ErrInvalidCursor is returned by Decode for a cursor it didn't produce.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Encode turns a cursor value into an opaque URL-safe token.
+func Encode(v any) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		// Cursors are plain structs; this only fails on a programming error
+		panic(fmt.Sprintf("pagination: encode cursor: %v", err))
+	}
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// Decode reads a token produced by Encode into v.
+func Decode(token string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return ErrInvalidCursor
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return ErrInvalidCursor
+	}
+	return nil
+}
+
+// Limit parses a page size parameter: empty means def, anything else must be
+// between 1 and max.
+func Limit(s string, def, max int) (int, error) {
+	if s == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 1 || n > max {
+		return 0, fmt.Errorf("limit must be between 1 and %d", max)
+	}
+	return n, nil
+}
@@ -0,0 +1,69 @@
+package pagination
+
+import (
+	"testing"
+	"time"
+)
+
+type testCursor struct {
+	Score     int32     `json:"s"`
+	CreatedAt time.Time `json:"t"`
+}
+
+func TestEncodeDecode(t *testing.T) {
+	want := testCursor{Score: 1234, CreatedAt: time.Date(2026, 3, 1, 12, 30, 0, 123456000, time.UTC)}
+
+	var got testCursor
+	if err := Decode(Encode(want), &got); err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if got.Score != want.Score || !got.CreatedAt.Equal(want.CreatedAt) {
+		t.Errorf("Decode() = %+v, want %+v", got, want)
+	}
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	tests := []string{
+		"",
+		"not base64!",
+		Encode("a string, not an object"),
+		Encode(map[string]any{"s": "high"}),
+	}
+
+	for _, token := range tests {
+		t.Run(token, func(t *testing.T) {
+			var c testCursor
+			if err := Decode(token, &c); err != ErrInvalidCursor {
+				t.Errorf("Decode(%q) error = %v, want ErrInvalidCursor", token, err)
+			}
+		})
+	}
+}
+
+func TestLimit(t *testing.T) {
+	tests := []struct {
+		s       string
+		want    int
+		wantErr bool
+	}{
+		{"", 50, false},
+		{"1", 1, false},
+		{"200", 200, false},
+		{"0", 0, true},
+		{"-5", 0, true},
+		{"201", 0, true},
+		{"ten", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			got, err := Limit(tt.s, 50, 200)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Limit(%q) error = %v, wantErr %v", tt.s, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Limit(%q) = %d, want %d", tt.s, got, tt.want)
+			}
+		})
+	}
+}
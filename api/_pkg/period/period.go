@@ -0,0 +1,89 @@
+// Package period resolves the time range a leaderboard covers. Ranges are
+// half-open, [Start, End), in UTC.
+package period
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Period kinds. A week starts on Monday; a season is a calendar quarter.
+const (
+	Day    = "day"
+	Week   = "week"
+	Month  = "month"
+	Season = "season"
+	All    = "all"
+	Custom = "custom" // explicit from/to range
+)
+
+// DateLayout is how anchors and from/to dates are written.
+const DateLayout = "2006-01-02"
+
+// Range is a resolved period. Start and End are nil for All.
+type Range struct {
+	Kind  string
+	Start *time.Time
+	End   *time.Time
+}
+
+// Resolve returns the period of the given kind containing anchor.
+func Resolve(kind string, anchor time.Time) (Range, error) {
+	t := anchor.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+
+	var start, end time.Time
+	switch kind {
+	case Day:
+		start, end = day, day.AddDate(0, 0, 1)
+	case Week:
+		// time.Weekday counts from Sunday
+		start = day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+		end = start.AddDate(0, 0, 7)
+	case Month:
+		start = time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+		end = start.AddDate(0, 1, 0)
+	case Season:
+		first := time.Month((int(t.Month())-1)/3*3 + 1)
+		start = time.Date(t.Year(), first, 1, 0, 0, 0, 0, time.UTC)
+		end = start.AddDate(0, 3, 0)
+	case All:
+		return Range{Kind: All}, nil
+	default:
+		return Range{}, fmt.Errorf("unknown period %q", kind)
+	}
+	return Range{Kind: kind, Start: &start, End: &end}, nil
+}
+
+// Errors returned by Between.
+var (
+	ErrEmptyRange    = errors.New("to must not be before from")
+	ErrRangeTooLarge = errors.New("range is too large")
+)
+
+// Between is the custom period from the start of day from to the end of day
+// to, both inclusive. It may span at most maxDays days.
+func Between(from, to time.Time, maxDays int) (Range, error) {
+	f, t := from.UTC(), to.UTC()
+	start := time.Date(f.Year(), f.Month(), f.Day(), 0, 0, 0, 0, time.UTC)
+	end := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, 1)
+	if !end.After(start) {
+		return Range{}, ErrEmptyRange
+	}
+	if end.After(start.AddDate(0, 0, maxDays)) {
+		return Range{}, ErrRangeTooLarge
+	}
+	return Range{Kind: Custom, Start: &start, End: &end}, nil
+}
+
+// Overlaps reports whether any part of r lies in [earliest, latest).
+func (r Range) Overlaps(earliest, latest time.Time) bool {
+	if r.End != nil && !r.End.After(earliest) {
+		return false
+	}
+	if r.Start != nil && !r.Start.Before(latest) {
+		return false
+	}
+	return true
+}
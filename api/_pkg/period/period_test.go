@@ -0,0 +1,116 @@
+package period
+
+import (
+	"testing"
+	"time"
+)
+
+func date(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestResolve(t *testing.T) {
+	tests := []struct {
+		name      string
+		kind      string
+		anchor    time.Time
+		wantStart time.Time
+		wantEnd   time.Time
+	}{
+		{"day", Day, time.Date(2026, 3, 4, 15, 30, 0, 0, time.UTC), date(2026, 3, 4), date(2026, 3, 5)},
+		{"day in another zone", Day, time.Date(2026, 3, 4, 23, 30, 0, 0, time.FixedZone("UTC-2", -2*3600)), date(2026, 3, 5), date(2026, 3, 6)},
+		{"week from wednesday", Week, date(2026, 3, 4), date(2026, 3, 2), date(2026, 3, 9)},
+		{"week from monday", Week, date(2026, 3, 2), date(2026, 3, 2), date(2026, 3, 9)},
+		{"week from sunday", Week, date(2026, 3, 8), date(2026, 3, 2), date(2026, 3, 9)},
+		{"week across years", Week, date(2026, 1, 1), date(2025, 12, 29), date(2026, 1, 5)},
+		{"month", Month, date(2026, 2, 14), date(2026, 2, 1), date(2026, 3, 1)},
+		{"december", Month, date(2025, 12, 31), date(2025, 12, 1), date(2026, 1, 1)},
+		{"first season", Season, date(2026, 3, 31), date(2026, 1, 1), date(2026, 4, 1)},
+		{"second season", Season, date(2026, 4, 1), date(2026, 4, 1), date(2026, 7, 1)},
+		{"last season", Season, date(2026, 11, 15), date(2026, 10, 1), date(2027, 1, 1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := Resolve(tt.kind, tt.anchor)
+			if err != nil {
+				t.Fatalf("Resolve() error = %v", err)
+			}
+			if r.Kind != tt.kind || r.Start == nil || r.End == nil {
+				t.Fatalf("Resolve() = %+v", r)
+			}
+			if !r.Start.Equal(tt.wantStart) || !r.End.Equal(tt.wantEnd) {
+				t.Errorf("Resolve() = [%v, %v), want [%v, %v)", r.Start, r.End, tt.wantStart, tt.wantEnd)
+			}
+		})
+	}
+}
+
+func TestResolveAll(t *testing.T) {
+	r, err := Resolve(All, date(2026, 3, 4))
+	if err != nil || r.Kind != All || r.Start != nil || r.End != nil {
+		t.Errorf("Resolve(All) = %+v, %v", r, err)
+	}
+	if _, err := Resolve("year", date(2026, 3, 4)); err == nil {
+		t.Error("Resolve(year) succeeded")
+	}
+}
+
+func TestBetween(t *testing.T) {
+	tests := []struct {
+		name      string
+		from, to  time.Time
+		wantStart time.Time
+		wantEnd   time.Time
+		wantErr   error
+	}{
+		{"one day", date(2026, 3, 4), date(2026, 3, 4), date(2026, 3, 4), date(2026, 3, 5), nil},
+		{"inclusive end", date(2026, 2, 27), date(2026, 3, 1), date(2026, 2, 27), date(2026, 3, 2), nil},
+		{"at max span", date(2026, 1, 1), date(2026, 1, 10), date(2026, 1, 1), date(2026, 1, 11), nil},
+		{"over max span", date(2026, 1, 1), date(2026, 1, 11), time.Time{}, time.Time{}, ErrRangeTooLarge},
+		{"reversed", date(2026, 3, 5), date(2026, 3, 4), time.Time{}, time.Time{}, ErrEmptyRange},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := Between(tt.from, tt.to, 10)
+			if err != tt.wantErr {
+				t.Fatalf("Between() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if r.Kind != Custom || !r.Start.Equal(tt.wantStart) || !r.End.Equal(tt.wantEnd) {
+				t.Errorf("Between() = %+v, want [%v, %v)", r, tt.wantStart, tt.wantEnd)
+			}
+		})
+	}
+}
+
+func TestOverlaps(t *testing.T) {
+	earliest, latest := date(2025, 11, 1), date(2026, 3, 5)
+	month := func(y int, m time.Month) Range {
+		r, _ := Resolve(Month, date(y, m, 1))
+		return r
+	}
+
+	tests := []struct {
+		name string
+		r    Range
+		want bool
+	}{
+		{"all", Range{Kind: All}, true},
+		{"first month", month(2025, 11), true},
+		{"before earliest", month(2025, 10), false},
+		{"current month", month(2026, 3), true},
+		{"future", month(2026, 4), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.r.Overlaps(earliest, latest); got != tt.want {
+				t.Errorf("Overlaps() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
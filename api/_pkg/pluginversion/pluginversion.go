@@ -0,0 +1,148 @@
+package pluginversion
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"rmpc-server/api/_pkg/config"
+)
+
+// Version is a plugin version. Missing MINOR or PATCH parts are zero.
+type Version struct {
+	Major, Minor, Patch int
+}
+
+// Unknown stands in for clients that don't send a version, which predate the
+// X-Plugin-Version header.
+var Unknown = Version{}
+
+// Parse parses MAJOR.MINOR[.PATCH], with an optional leading "v".
+func Parse(s string) (Version, error) {
+	parts, err := parse(s)
+	if err != nil {
+		return Version{}, err
+	}
+	if len(parts) < 2 {
+		return Version{}, fmt.Errorf("invalid version %q", s)
+	}
+	return fromParts(parts), nil
+}
+
+func parse(s string) ([]int, error) {
+	fields := strings.Split(strings.TrimPrefix(s, "v"), ".")
+	if len(fields) > 3 {
+		return nil, fmt.Errorf("invalid version %q", s)
+	}
+	parts := make([]int, len(fields))
+	for i, f := range fields {
+		// Digits only: no signs, spaces or pre-release suffixes
+		if f == "" || len(f) > 9 || strings.Trim(f, "0123456789") != "" {
+			return nil, fmt.Errorf("invalid version %q", s)
+		}
+		parts[i], _ = strconv.Atoi(f)
+	}
+	return parts, nil
+}
+
+func fromParts(parts []int) Version {
+	var v Version
+	fields := []*int{&v.Major, &v.Minor, &v.Patch}
+	for i, p := range parts {
+		*fields[i] = p
+	}
+	return v
+}
+
+func (v Version) String() string {
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
+
+// Compare returns -1, 0 or 1 as v is older than, equal to or newer than o.
+func (v Version) Compare(o Version) int {
+	a := [3]int{v.Major, v.Minor, v.Patch}
+	b := [3]int{o.Major, o.Minor, o.Patch}
+	for i := range a {
+		if a[i] != b[i] {
+			if a[i] < b[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// Matches reports whether v is pattern. A pattern with fewer than three parts
+// matches every version it is a prefix of, so "1.3" matches 1.3.0 and 1.3.7.
+func (v Version) Matches(pattern string) bool {
+	parts, err := parse(pattern)
+	if err != nil {
+		return false
+	}
+	have := [3]int{v.Major, v.Minor, v.Patch}
+	for i, p := range parts {
+		if have[i] != p {
+			return false
+		}
+	}
+	return true
+}
+
+// ErrUnsupported is returned by Check for versions that may not submit scores.
+var ErrUnsupported = errors.New("unsupported plugin version")
+
+// UnsupportedError is how Check turns a version away. It wraps
+// ErrUnsupported and names the rule of plugin.yaml that matched.
+type UnsupportedError struct {
+	Rule    string // "min_version 1.2" or "blocked_versions 1.3"
+	message string
+}
+
+func (e *UnsupportedError) Error() string {
+	return ErrUnsupported.Error() + ": " + e.message
+}
+
+func (e *UnsupportedError) Unwrap() error {
+	return ErrUnsupported
+}
+
+// Check returns an *UnsupportedError, with a message fit for the player, if
+// rules don't let plugin version v submit scores. Other errors mean the rules
+// themselves are invalid.
+func Check(rules *config.PluginRules, v Version) error {
+	if rules.MinVersion != "" {
+		min, err := Parse(rules.MinVersion)
+		if err != nil {
+			return fmt.Errorf("min_version: %w", err)
+		}
+		if v.Compare(min) < 0 {
+			rule := "min_version " + rules.MinVersion
+			if v == Unknown {
+				return &UnsupportedError{rule, fmt.Sprintf("please update the plugin to %s or later", min)}
+			}
+			return &UnsupportedError{rule, fmt.Sprintf("%s is no longer supported, please update the plugin to %s or later", v, min)}
+		}
+	}
+	for _, blocked := range rules.BlockedVersions {
+		if v.Matches(blocked) {
+			return &UnsupportedError{"blocked_versions " + blocked, fmt.Sprintf("%s has a known scoring problem, please update the plugin", v)}
+		}
+	}
+	return nil
+}
+
+// Ruleset returns the ruleset that runs from plugin version v count under:
+// the last one whose min_version v meets. Rulesets with an invalid
+// min_version are skipped.
+func Ruleset(rules *config.PluginRules, v Version) int16 {
+	ruleset := int16(1)
+	for _, rs := range rules.Rulesets {
+		min, err := Parse(rs.MinVersion)
+		if err == nil && v.Compare(min) >= 0 {
+			ruleset = rs.ID
+		}
+	}
+	return ruleset
+}
@@ -0,0 +1,181 @@
+package pluginversion
+
+import (
+	"errors"
+	"testing"
+
+	"rmpc-server/api/_pkg/config"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Version
+		wantErr bool
+	}{
+		{in: "1.4.2", want: Version{1, 4, 2}},
+		{in: "v1.4.2", want: Version{1, 4, 2}},
+		{in: "1.4", want: Version{1, 4, 0}},
+		{in: "0.0.0", want: Version{}},
+		{in: "10.20.30", want: Version{10, 20, 30}},
+		{in: "1", wantErr: true},
+		{in: "", wantErr: true},
+		{in: "1.4.2.1", wantErr: true},
+		{in: "1.4.2-beta", wantErr: true},
+		{in: "1..2", wantErr: true},
+		{in: "1.-4.2", wantErr: true},
+		{in: "1.+4.2", wantErr: true},
+		{in: " 1.4.2", wantErr: true},
+		{in: "1.4.9999999999", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Parse(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Parse(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		a, b Version
+		want int
+	}{
+		{Version{1, 4, 2}, Version{1, 4, 2}, 0},
+		{Version{1, 4, 2}, Version{1, 4, 3}, -1},
+		{Version{1, 10, 0}, Version{1, 9, 9}, 1},
+		{Version{2, 0, 0}, Version{1, 99, 99}, 1},
+		{Unknown, Version{0, 0, 1}, -1},
+	}
+	for _, tt := range tests {
+		if got := tt.a.Compare(tt.b); got != tt.want {
+			t.Errorf("%v.Compare(%v) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestMatches(t *testing.T) {
+	tests := []struct {
+		v       Version
+		pattern string
+		want    bool
+	}{
+		{Version{1, 3, 2}, "1.3.2", true},
+		{Version{1, 3, 2}, "1.3.1", false},
+		{Version{1, 3, 2}, "1.3", true},
+		{Version{1, 30, 0}, "1.3", false},
+		{Version{1, 3, 2}, "1", true},
+		{Version{2, 3, 2}, "1", false},
+		{Version{1, 3, 0}, "garbage", false},
+	}
+	for _, tt := range tests {
+		if got := tt.v.Matches(tt.pattern); got != tt.want {
+			t.Errorf("%v.Matches(%q) = %v, want %v", tt.v, tt.pattern, got, tt.want)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	rules := &config.PluginRules{
+		MinVersion:      "1.2",
+		BlockedVersions: []string{"1.3", "1.5.1"},
+	}
+	tests := []struct {
+		v    Version
+		rule string // "" if allowed
+	}{
+		{Unknown, "min_version 1.2"},
+		{Version{1, 1, 9}, "min_version 1.2"},
+		{Version{1, 2, 0}, ""},
+		{Version{1, 3, 0}, "blocked_versions 1.3"},
+		{Version{1, 3, 4}, "blocked_versions 1.3"},
+		{Version{1, 4, 0}, ""},
+		{Version{1, 5, 0}, ""},
+		{Version{1, 5, 1}, "blocked_versions 1.5.1"},
+		{Version{1, 5, 2}, ""},
+	}
+	for _, tt := range tests {
+		err := Check(rules, tt.v)
+		if tt.rule == "" {
+			if err != nil {
+				t.Errorf("Check(%v) = %v, want nil", tt.v, err)
+			}
+			continue
+		}
+		var unsupported *UnsupportedError
+		if !errors.Is(err, ErrUnsupported) || !errors.As(err, &unsupported) || unsupported.Rule != tt.rule {
+			t.Errorf("Check(%v) = %v, want ErrUnsupported by %q", tt.v, err, tt.rule)
+		}
+	}
+
+	if err := Check(&config.PluginRules{}, Unknown); err != nil {
+		t.Errorf("Check() with no rules = %v, want nil", err)
+	}
+	err := Check(&config.PluginRules{MinVersion: "latest"}, Version{1, 0, 0})
+	if err == nil || errors.Is(err, ErrUnsupported) {
+		t.Errorf("Check() with invalid min_version = %v, want a config error", err)
+	}
+}
+
+func TestRuleset(t *testing.T) {
+	rules := &config.PluginRules{Rulesets: []config.Ruleset{
+		{ID: 1, MinVersion: "0.0.0"},
+		{ID: 2, MinVersion: "1.4"},
+		{ID: 3, MinVersion: "2.0.0"},
+	}}
+	tests := []struct {
+		v    Version
+		want int16
+	}{
+		{Unknown, 1},
+		{Version{1, 3, 9}, 1},
+		{Version{1, 4, 0}, 2},
+		{Version{1, 99, 0}, 2},
+		{Version{2, 0, 0}, 3},
+	}
+	for _, tt := range tests {
+		if got := Ruleset(rules, tt.v); got != tt.want {
+			t.Errorf("Ruleset(%v) = %d, want %d", tt.v, got, tt.want)
+		}
+	}
+	if got := rules.CurrentRuleset(); got != 3 {
+		t.Errorf("CurrentRuleset() = %d, want 3", got)
+	}
+}
+
+func TestPluginConfig(t *testing.T) {
+	// Depends on loading config/plugin.yaml
+	rules, err := config.Plugin()
+	if err != nil {
+		t.Fatalf("config.Plugin() error = %v", err)
+	}
+	if rules.MinVersion != "" {
+		if _, err := Parse(rules.MinVersion); err != nil {
+			t.Errorf("min_version: %v", err)
+		}
+	}
+	for _, blocked := range rules.BlockedVersions {
+		if _, err := parse(blocked); err != nil {
+			t.Errorf("blocked_versions: %v", err)
+		}
+	}
+	if len(rules.Rulesets) == 0 {
+		t.Fatal("no rulesets configured")
+	}
+	var prev Version
+	for i, rs := range rules.Rulesets {
+		v, err := Parse(rs.MinVersion)
+		if err != nil {
+			t.Errorf("ruleset %d: %v", rs.ID, err)
+			continue
+		}
+		if i > 0 && (rs.ID <= rules.Rulesets[i-1].ID || v.Compare(prev) <= 0) {
+			t.Errorf("ruleset %d is out of order", rs.ID)
+		}
+		prev = v
+	}
+}
@@ -0,0 +1,73 @@
+package preset
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"slices"
+	"strings"
+
+	"rmpc-server/api/_pkg/metadata"
+)
+
+// Settings describe a custom-mode run. Two runs are on the same leaderboard
+// exactly when their settings normalize to the same hash.
+type Settings struct {
+	TimeLimitMs int32               `json:"time_limit_ms" validate:"gte=60000,lte=7200000"`
+	TargetMedal string              `json:"target_medal"  validate:"required,oneof=author gold silver bronze"`
+	MapFilters  metadata.MapFilters `json:"map_filters"`
+}
+
+// Normalize returns s with everything that doesn't change the run made
+// uniform: tags are lowercased, sorted and deduplicated, a tag that is both
+// included and excluded is kept only as excluded, and empty lists are empty
+// rather than missing.
+func (s Settings) Normalize() Settings {
+	f := s.MapFilters
+	f.Difficulty = strings.ToLower(strings.TrimSpace(f.Difficulty))
+	f.ExcludedTags = normalizeTags(f.ExcludedTags)
+	f.Tags = slices.DeleteFunc(normalizeTags(f.Tags), func(tag string) bool {
+		_, found := slices.BinarySearch(f.ExcludedTags, tag)
+		return found
+	})
+	s.MapFilters = f
+	return s
+}
+
+func normalizeTags(tags []string) []string {
+	out := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		if tag = strings.ToLower(strings.TrimSpace(tag)); tag != "" {
+			out = append(out, tag)
+		}
+	}
+	slices.Sort(out)
+	return slices.Compact(out)
+}
+
+// Canonical returns the normalized settings as JSON, with fields in a fixed
+// order so equal settings always encode to the same bytes.
+func (s Settings) Canonical() string {
+	b, err := json.Marshal(s.Normalize())
+	if err != nil {
+		// Only plain strings, ints and slices; marshalling can't fail
+		panic(err)
+	}
+	return string(b)
+}
+
+// Hash identifies the preset on leaderboards: the first 64 bits of the
+// SHA-256 of the canonical settings, hex encoded.
+func (s Settings) Hash() string {
+	sum := sha256.Sum256([]byte(s.Canonical()))
+	return hex.EncodeToString(sum[:8])
+}
+
+// ValidHash reports whether h looks like a value returned by Hash.
+func ValidHash(h string) bool {
+	if len(h) != 16 {
+		return false
+	}
+	_, err := hex.DecodeString(h)
+	return err == nil && strings.ToLower(h) == h
+}
@@ -0,0 +1,80 @@
+package preset
+
+import (
+	"testing"
+
+	"rmpc-server/api/_pkg/metadata"
+)
+
+func TestHashIgnoresIrrelevantDifferences(t *testing.T) {
+	a := Settings{
+		TimeLimitMs: 3600000,
+		TargetMedal: "author",
+		MapFilters: metadata.MapFilters{
+			MaxLengthMs: 60000,
+			Tags:        []string{"Tech", "dirt", " tech "},
+			Difficulty:  "Intermediate",
+		},
+	}
+	b := Settings{
+		TimeLimitMs: 3600000,
+		TargetMedal: "author",
+		MapFilters: metadata.MapFilters{
+			MaxLengthMs:  60000,
+			Tags:         []string{"dirt", "tech"},
+			ExcludedTags: []string{},
+			Difficulty:   "intermediate",
+		},
+	}
+	if a.Hash() != b.Hash() {
+		t.Errorf("Hash() differs for equivalent settings:\n%s\n%s", a.Canonical(), b.Canonical())
+	}
+}
+
+func TestHashDistinguishesSettings(t *testing.T) {
+	base := Settings{TimeLimitMs: 3600000, TargetMedal: "author"}
+	variants := map[string]Settings{
+		"time limit": {TimeLimitMs: 1800000, TargetMedal: "author"},
+		"medal":      {TimeLimitMs: 3600000, TargetMedal: "gold"},
+		"tags":       {TimeLimitMs: 3600000, TargetMedal: "author", MapFilters: metadata.MapFilters{Tags: []string{"tech"}}},
+		"excluded":   {TimeLimitMs: 3600000, TargetMedal: "author", MapFilters: metadata.MapFilters{ExcludedTags: []string{"tech"}}},
+		"length":     {TimeLimitMs: 3600000, TargetMedal: "author", MapFilters: metadata.MapFilters{MinLengthMs: 30000}},
+	}
+	for name, v := range variants {
+		if v.Hash() == base.Hash() {
+			t.Errorf("%s: Hash() = base hash %s", name, base.Hash())
+		}
+	}
+}
+
+func TestNormalizeExcludedWins(t *testing.T) {
+	s := Settings{MapFilters: metadata.MapFilters{
+		Tags:         []string{"tech", "ice"},
+		ExcludedTags: []string{"Ice"},
+	}}.Normalize()
+	if len(s.MapFilters.Tags) != 1 || s.MapFilters.Tags[0] != "tech" {
+		t.Errorf("Tags = %v, want [tech]", s.MapFilters.Tags)
+	}
+	if len(s.MapFilters.ExcludedTags) != 1 || s.MapFilters.ExcludedTags[0] != "ice" {
+		t.Errorf("ExcludedTags = %v, want [ice]", s.MapFilters.ExcludedTags)
+	}
+}
+
+func TestValidHash(t *testing.T) {
+	h := Settings{TimeLimitMs: 3600000, TargetMedal: "author"}.Hash()
+	tests := []struct {
+		in   string
+		want bool
+	}{
+		{h, true},
+		{"", false},
+		{h[:15], false},
+		{"0123456789ABCDEF", false},
+		{"0123456789abcdeg", false},
+	}
+	for _, tt := range tests {
+		if got := ValidHash(tt.in); got != tt.want {
+			t.Errorf("ValidHash(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+// Package ranking defines how runs are ordered on every board: leaderboards,
+// the hall of fame and world records.
+//
+// Runs are ranked by a metric, score unless a leaderboard asks for another,
+// then by score if the metric isn't score, then by the tie-breakers of the
+// configured chain in order, by default fewer skips, shorter duration and
+// earlier submission. Runs equal on all of these share a rank and the next
+// rank is skipped (1, 2, 2, 4). Whatever the chain, the earliest submission
+// among runs sharing a rank is listed first and holds a record.
+package ranking
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"rmpc-server/api/_pkg/config"
+)
+
+// Metrics a leaderboard can rank by. Each player is ranked by their best run
+// on the metric, except for Runs, which counts the player's runs.
+const (
+	ByScore         = "score"
+	ByMapsCompleted = "maps_completed"
+	ByScorePerHour  = "score_per_hour" // score normalized by duration_ms
+	ByFewestSkips   = "fewest_skips"   // among runs reaching a minimum score
+	ByRuns          = "runs"
+)
+
+// Metrics lists every metric, the default first.
+var Metrics = []string{ByScore, ByMapsCompleted, ByScorePerHour, ByFewestSkips, ByRuns}
+
+// ValidMetric reports whether m names a metric.
+func ValidMetric(m string) bool {
+	return slices.Contains(Metrics, m)
+}
+
+// ScorePerHour is the score a run made per hour played, rounded to two
+// decimals, or 0 for a run without a duration.
+func ScorePerHour(score, durationMs int32) float64 {
+	if durationMs <= 0 {
+		return 0
+	}
+	perHour := float64(score) * float64(time.Hour/time.Millisecond) / float64(durationMs)
+	return math.Round(perHour*100) / 100
+}
+
+// Tie-breakers applied after score.
+const (
+	FewestSkips        = "fewest_skips"
+	ShortestDuration   = "shortest_duration"
+	EarliestSubmission = "earliest_submission"
+)
+
+// None configures an empty chain: only score decides the rank.
+const None = "none"
+
+// DefaultChain is used when RANK_TIE_BREAKERS is unset or invalid.
+var DefaultChain = []string{FewestSkips, ShortestDuration, EarliestSubmission}
+
+// ParseChain reads a comma-separated tie-breaker chain.
+func ParseChain(s string) ([]string, error) {
+	if strings.TrimSpace(s) == None {
+		return []string{}, nil
+	}
+	var chain []string
+	for _, name := range strings.Split(s, ",") {
+		name = strings.TrimSpace(name)
+		switch name {
+		case FewestSkips, ShortestDuration, EarliestSubmission:
+		default:
+			return nil, fmt.Errorf("unknown tie-breaker %q", name)
+		}
+		if slices.Contains(chain, name) {
+			return nil, fmt.Errorf("tie-breaker %q is repeated", name)
+		}
+		chain = append(chain, name)
+	}
+	return chain, nil
+}
+
+var (
+	chain     []string
+	chainOnce sync.Once
+)
+
+// Chain returns the configured tie-breaker chain.
+func Chain() []string {
+	chainOnce.Do(func() {
+		c, err := ParseChain(config.Env.RankTieBreakers)
+		if err != nil {
+			slog.Error("invalid RANK_TIE_BREAKERS, using default", "error", err)
+			c = DefaultChain
+		}
+		chain = c
+	})
+	return chain
+}
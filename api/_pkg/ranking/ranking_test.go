@@ -0,0 +1,74 @@
+package ranking
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseChain(t *testing.T) {
+	tests := []struct {
+		s       string
+		want    []string
+		wantErr bool
+	}{
+		{"fewest_skips,shortest_duration", []string{FewestSkips, ShortestDuration}, false},
+		{"fewest_skips,shortest_duration,earliest_submission", DefaultChain, false},
+		{"earliest_submission", []string{EarliestSubmission}, false},
+		{"shortest_duration, fewest_skips", []string{ShortestDuration, FewestSkips}, false},
+		{"fewest_skips", []string{FewestSkips}, false},
+		{"none", []string{}, false},
+		{"", nil, true},
+		{"fewest_skips,", nil, true},
+		{"most_maps", nil, true},
+		{"fewest_skips,fewest_skips", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			got, err := ParseChain(tt.s)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseChain(%q) error = %v, wantErr %v", tt.s, err, tt.wantErr)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("ParseChain(%q) = %v, want %v", tt.s, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScorePerHour(t *testing.T) {
+	tests := []struct {
+		name       string
+		score      int32
+		durationMs int32
+		want       float64
+	}{
+		{"one hour", 600000, 3600000, 600000},
+		{"half an hour", 300000, 1800000, 600000},
+		{"rounded", 100000, 7000000, 51428.57},
+		{"no score", 0, 3600000, 0},
+		{"zero duration", 600000, 0, 0},
+		{"negative duration", 600000, -1, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ScorePerHour(tt.score, tt.durationMs); got != tt.want {
+				t.Errorf("ScorePerHour(%d, %d) = %v, want %v", tt.score, tt.durationMs, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidMetric(t *testing.T) {
+	for _, m := range Metrics {
+		if !ValidMetric(m) {
+			t.Errorf("ValidMetric(%q) = false", m)
+		}
+	}
+	for _, m := range []string{"", "Score", "skips", "duration"} {
+		if ValidMetric(m) {
+			t.Errorf("ValidMetric(%q) = true", m)
+		}
+	}
+}
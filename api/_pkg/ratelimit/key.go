@@ -0,0 +1,23 @@
+package ratelimit
+
+import (
+	"net/netip"
+	"strings"
+)
+
+// Key maps a client IP to the bucket it is rate limited under. IPv6 clients
+// are routinely handed a whole /64, so limiting single addresses would let
+// them rotate through billions of fresh buckets; the /64 is the unit instead.
+// Anything that doesn't parse as an IP is used verbatim.
+func Key(ip string) string {
+	addr, err := netip.ParseAddr(strings.TrimSpace(ip))
+	if err != nil {
+		return ip
+	}
+	addr = addr.Unmap()
+	if addr.Is4() {
+		return addr.String()
+	}
+	prefix, _ := addr.WithZone("").Prefix(64)
+	return prefix.String()
+}
@@ -0,0 +1,44 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestKey(t *testing.T) {
+	tests := []struct {
+		name string
+		ip   string
+		want string
+	}{
+		{"ipv4", "203.0.113.7", "203.0.113.7"},
+		{"ipv4 with spaces", " 203.0.113.7 ", "203.0.113.7"},
+		{"ipv4-mapped ipv6", "::ffff:203.0.113.7", "203.0.113.7"},
+		{"ipv6", "2001:db8:1:2:3:4:5:6", "2001:db8:1:2::/64"},
+		{"ipv6 same /64", "2001:db8:1:2:ffff::1", "2001:db8:1:2::/64"},
+		{"ipv6 with zone", "fe80::1%eth0", "fe80::/64"},
+		{"not an ip", "unknown", "unknown"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Key(tt.ip); got != tt.want {
+				t.Errorf("Key(%q) = %q, want %q", tt.ip, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAllowSharesIPv6Prefix(t *testing.T) {
+	limiter := NewIPLimiter(1, time.Second)
+
+	if !limiter.Allow("2001:db8:1:2::1") {
+		t.Fatal("first address should be allowed")
+	}
+	if limiter.Allow("2001:db8:1:2::2") {
+		t.Fatal("second address in the same /64 should be denied")
+	}
+	if !limiter.Allow("2001:db8:1:3::1") {
+		t.Fatal("address in another /64 should be allowed")
+	}
+}
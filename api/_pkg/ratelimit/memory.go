@@ -26,7 +26,11 @@ func NewIPLimiter(limit int, window time.Duration) *IPLimiter {
 	return limiter
 }
 
+// Allow records a request from ip and reports whether it is within the limit.
+// IPv6 addresses share a budget per /64, see Key.
 func (l *IPLimiter) Allow(ip string) bool {
+	ip = Key(ip)
+
 	l.mu.Lock()
 	defer l.mu.Unlock()
 
@@ -0,0 +1,49 @@
+package runs
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrFinishedInFuture   = errors.New("finished_at is in the future")
+	ErrFinishedTooLongAgo = errors.New("finished_at is too long ago")
+	ErrFinishedMonthOver  = errors.New("finished_at is in a month whose leaderboards have closed")
+)
+
+// clockSkew is how far a client's clock may run ahead of the server's.
+const clockSkew = time.Minute
+
+// CheckFinishedAt checks the finish time a client reports for a run uploaded
+// after the fact: no later than now, give or take clock skew, no more than
+// maxAge before it, and in the current UTC month. Runs of earlier months would
+// change final standings and hall of fame podiums after the month closed.
+func CheckFinishedAt(finishedAt, now time.Time, maxAge time.Duration) error {
+	if finishedAt.After(now.Add(clockSkew)) {
+		return ErrFinishedInFuture
+	}
+	if now.Sub(finishedAt) > maxAge {
+		return ErrFinishedTooLongAgo
+	}
+	now = now.UTC()
+	if finishedAt.Before(time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)) {
+		return ErrFinishedMonthOver
+	}
+	return nil
+}
+
+// Span is the time during which a run was played.
+type Span struct {
+	FinishedAt time.Time
+	Duration   time.Duration
+}
+
+func (s Span) StartedAt() time.Time {
+	return s.FinishedAt.Add(-s.Duration)
+}
+
+// Overlaps reports whether s and o were played at the same time, which one
+// player can't do. Runs that merely touch don't overlap.
+func (s Span) Overlaps(o Span) bool {
+	return s.StartedAt().Before(o.FinishedAt) && o.StartedAt().Before(s.FinishedAt)
+}
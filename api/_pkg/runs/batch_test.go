@@ -0,0 +1,85 @@
+package runs
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCheckFinishedAt(t *testing.T) {
+	now := time.Date(2026, 1, 10, 12, 0, 0, 0, time.UTC)
+	maxAge := 72 * time.Hour
+
+	tests := []struct {
+		name       string
+		finishedAt time.Time
+		want       error
+	}{
+		{"just now", now, nil},
+		{"an hour ago", now.Add(-time.Hour), nil},
+		{"at max age", now.Add(-maxAge), nil},
+		{"client clock slightly ahead", now.Add(30 * time.Second), nil},
+		{"in the future", now.Add(2 * time.Minute), ErrFinishedInFuture},
+		{"too long ago", now.Add(-maxAge - time.Second), ErrFinishedTooLongAgo},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CheckFinishedAt(tt.finishedAt, now, maxAge); got != tt.want {
+				t.Errorf("CheckFinishedAt() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckFinishedAtMonthOver(t *testing.T) {
+	now := time.Date(2026, 2, 1, 6, 0, 0, 0, time.UTC)
+	maxAge := 72 * time.Hour
+
+	tests := []struct {
+		name       string
+		finishedAt time.Time
+		want       error
+	}{
+		{"start of month", time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC), nil},
+		{"last month", time.Date(2026, 1, 31, 23, 59, 0, 0, time.UTC), ErrFinishedMonthOver},
+		{"last month in another zone", time.Date(2026, 2, 1, 0, 30, 0, 0, time.FixedZone("CET", 3600)), ErrFinishedMonthOver},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CheckFinishedAt(tt.finishedAt, now, maxAge); got != tt.want {
+				t.Errorf("CheckFinishedAt() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSpanOverlaps(t *testing.T) {
+	base := time.Date(2026, 1, 10, 12, 0, 0, 0, time.UTC)
+	run := Span{FinishedAt: base, Duration: time.Hour} // 11:00-12:00
+
+	tests := []struct {
+		name  string
+		other Span
+		want  bool
+	}{
+		{"same run", run, true},
+		{"starts inside", Span{FinishedAt: base.Add(30 * time.Minute), Duration: time.Hour}, true},
+		{"ends inside", Span{FinishedAt: base.Add(-30 * time.Minute), Duration: time.Hour}, true},
+		{"contained", Span{FinishedAt: base.Add(-10 * time.Minute), Duration: 10 * time.Minute}, true},
+		{"right after", Span{FinishedAt: base.Add(time.Hour), Duration: time.Hour}, false},
+		{"right before", Span{FinishedAt: base.Add(-time.Hour), Duration: time.Hour}, false},
+		{"long before", Span{FinishedAt: base.Add(-5 * time.Hour), Duration: time.Hour}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := run.Overlaps(tt.other); got != tt.want {
+				t.Errorf("Overlaps() = %v, want %v", got, tt.want)
+			}
+			if got := tt.other.Overlaps(run); got != tt.want {
+				t.Errorf("Overlaps() reversed = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
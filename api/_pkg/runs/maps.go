@@ -0,0 +1,58 @@
+package runs
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MapResult is one map of a run as reported by the plugin.
+type MapResult struct {
+	TimeMs       *int32 // nil if the map was never finished
+	TargetTimeMs int32  // time of the medal the run is chasing on this map
+	Skipped      bool
+	Broken       bool // unplayable map the plugin replaced at no cost
+}
+
+// Totals are the numbers a score submission claims for a run.
+type Totals struct {
+	Score         int64
+	MapsCompleted int64
+	MapsSkipped   int64
+}
+
+// ComputeTotals derives a run's totals from its map results. A map is
+// completed when it was finished within its target time and not skipped;
+// broken maps count as neither completed nor skipped. The score is taken to be
+// the sum of the target times of completed maps. That is an assumption: the
+// plugin's scoring rule isn't documented, so the score is only compared with
+// MAP_RESULTS_SCORE_CHECK set.
+func ComputeTotals(maps []MapResult) Totals {
+	var t Totals
+	for _, m := range maps {
+		switch {
+		case m.Broken:
+		case m.Skipped:
+			t.MapsSkipped++
+		case m.TimeMs != nil && *m.TimeMs <= m.TargetTimeMs:
+			t.MapsCompleted++
+			t.Score += int64(m.TargetTimeMs)
+		}
+	}
+	return t
+}
+
+// Mismatch describes how reported differs from t, or returns "" if they agree.
+// The score is left out unless checkScore is set.
+func (t Totals) Mismatch(reported Totals, checkScore bool) string {
+	var diffs []string
+	if checkScore && reported.Score != t.Score {
+		diffs = append(diffs, fmt.Sprintf("score %d, maps say %d", reported.Score, t.Score))
+	}
+	if reported.MapsCompleted != t.MapsCompleted {
+		diffs = append(diffs, fmt.Sprintf("maps_completed %d, maps say %d", reported.MapsCompleted, t.MapsCompleted))
+	}
+	if reported.MapsSkipped != t.MapsSkipped {
+		diffs = append(diffs, fmt.Sprintf("maps_skipped %d, maps say %d", reported.MapsSkipped, t.MapsSkipped))
+	}
+	return strings.Join(diffs, "; ")
+}
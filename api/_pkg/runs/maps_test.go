@@ -0,0 +1,82 @@
+package runs
+
+import "testing"
+
+func ms(v int32) *int32 { return &v }
+
+func TestComputeTotals(t *testing.T) {
+	tests := []struct {
+		name string
+		maps []MapResult
+		want Totals
+	}{
+		{"no maps", nil, Totals{}},
+		{
+			name: "completed maps add their target time",
+			maps: []MapResult{
+				{TimeMs: ms(41000), TargetTimeMs: 45000},
+				{TimeMs: ms(30000), TargetTimeMs: 30000},
+			},
+			want: Totals{Score: 75000, MapsCompleted: 2},
+		},
+		{
+			name: "finished outside target time is not completed",
+			maps: []MapResult{{TimeMs: ms(46000), TargetTimeMs: 45000}},
+			want: Totals{},
+		},
+		{
+			name: "unfinished map is not completed",
+			maps: []MapResult{{TargetTimeMs: 45000}},
+			want: Totals{},
+		},
+		{
+			name: "skipped map counts as skipped even with a time",
+			maps: []MapResult{{TimeMs: ms(40000), TargetTimeMs: 45000, Skipped: true}},
+			want: Totals{MapsSkipped: 1},
+		},
+		{
+			name: "broken map counts as nothing",
+			maps: []MapResult{{TargetTimeMs: 45000, Skipped: true, Broken: true}},
+			want: Totals{},
+		},
+		{
+			name: "mixed run",
+			maps: []MapResult{
+				{TimeMs: ms(50000), TargetTimeMs: 52000},
+				{TargetTimeMs: 90000, Skipped: true},
+				{TargetTimeMs: 60000, Broken: true},
+				{TimeMs: ms(20000), TargetTimeMs: 25000},
+			},
+			want: Totals{Score: 77000, MapsCompleted: 2, MapsSkipped: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ComputeTotals(tt.maps); got != tt.want {
+				t.Errorf("ComputeTotals() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTotalsMismatch(t *testing.T) {
+	computed := Totals{Score: 77000, MapsCompleted: 2, MapsSkipped: 1}
+
+	if got := computed.Mismatch(computed, true); got != "" {
+		t.Errorf("Mismatch(equal) = %q, want empty", got)
+	}
+
+	reported := Totals{Score: 90000, MapsCompleted: 2, MapsSkipped: 0}
+	got := computed.Mismatch(reported, true)
+	want := "score 90000, maps say 77000; maps_skipped 0, maps say 1"
+	if got != want {
+		t.Errorf("Mismatch() = %q, want %q", got, want)
+	}
+
+	got = computed.Mismatch(reported, false)
+	want = "maps_skipped 0, maps say 1"
+	if got != want {
+		t.Errorf("Mismatch() without score = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,55 @@
+package runs
+
+import (
+	"fmt"
+
+	"rmpc-server/api/_pkg/config"
+)
+
+// Submission is what a score submission claims about a run.
+type Submission struct {
+	GameMode      string
+	Score         int32
+	MapsCompleted int32
+	MapsSkipped   int32
+	DurationMs    int32
+}
+
+// Implausible checks s against rules and returns a description of every rule
+// it breaks, or nil if it looks legitimate. A positive score without any
+// completed map is always implausible, whatever the rules say.
+func Implausible(rules *config.PlausibilityRules, s Submission) []string {
+	var reasons []string
+	maps := s.MapsCompleted + s.MapsSkipped
+
+	if rules.MaxMapsPerMinute > 0 && s.DurationMs > 0 {
+		perMinute := float64(maps) / (float64(s.DurationMs) / 60000)
+		if perMinute > rules.MaxMapsPerMinute {
+			reasons = append(reasons, fmt.Sprintf("%.1f maps per minute exceeds %.1f", perMinute, rules.MaxMapsPerMinute))
+		}
+	}
+
+	if rules.MaxSkipRatio > 0 && maps >= rules.MinMapsForSkipRatio && s.MapsSkipped > 0 {
+		if s.MapsCompleted == 0 {
+			reasons = append(reasons, fmt.Sprintf("%d maps skipped and none completed", s.MapsSkipped))
+		} else if ratio := float64(s.MapsSkipped) / float64(s.MapsCompleted); ratio > rules.MaxSkipRatio {
+			reasons = append(reasons, fmt.Sprintf("%.1f skips per completed map exceeds %.1f", ratio, rules.MaxSkipRatio))
+		}
+	}
+
+	if s.MapsCompleted == 0 {
+		if s.Score > 0 {
+			reasons = append(reasons, "score without completed maps")
+		}
+	} else if bounds, ok := rules.GameModes[s.GameMode]; ok {
+		perMap := s.Score / s.MapsCompleted
+		if bounds.MinMs > 0 && perMap < bounds.MinMs {
+			reasons = append(reasons, fmt.Sprintf("%d ms per map is below %d", perMap, bounds.MinMs))
+		}
+		if bounds.MaxMs > 0 && perMap > bounds.MaxMs {
+			reasons = append(reasons, fmt.Sprintf("%d ms per map exceeds %d", perMap, bounds.MaxMs))
+		}
+	}
+
+	return reasons
+}
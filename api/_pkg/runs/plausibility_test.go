@@ -0,0 +1,94 @@
+package runs
+
+import (
+	"reflect"
+	"testing"
+
+	"rmpc-server/api/_pkg/config"
+)
+
+func TestImplausible(t *testing.T) {
+	rules := &config.PlausibilityRules{
+		MaxMapsPerMinute:    3,
+		MaxSkipRatio:        4,
+		MinMapsForSkipRatio: 10,
+		GameModes: map[string]config.ScorePerMapBounds{
+			"author": {MinMs: 5000, MaxMs: 600000},
+		},
+	}
+	hour := int32(3600000)
+
+	tests := []struct {
+		name string
+		s    Submission
+		want []string
+	}{
+		{
+			name: "typical run",
+			s:    Submission{GameMode: "author", Score: 1200000, MapsCompleted: 25, MapsSkipped: 10, DurationMs: hour},
+		},
+		{
+			name: "empty run",
+			s:    Submission{GameMode: "author", DurationMs: hour},
+		},
+		{
+			name: "too many maps per minute",
+			s:    Submission{GameMode: "author", Score: 2000000, MapsCompleted: 200, MapsSkipped: 0, DurationMs: hour},
+			want: []string{"3.3 maps per minute exceeds 3.0"},
+		},
+		{
+			name: "skip ratio",
+			s:    Submission{GameMode: "author", Score: 100000, MapsCompleted: 2, MapsSkipped: 12, DurationMs: hour},
+			want: []string{"6.0 skips per completed map exceeds 4.0"},
+		},
+		{
+			name: "skip ratio below minimum map count is not checked",
+			s:    Submission{GameMode: "author", Score: 50000, MapsCompleted: 1, MapsSkipped: 8, DurationMs: hour},
+		},
+		{
+			name: "skips without completions",
+			s:    Submission{GameMode: "author", MapsSkipped: 15, DurationMs: hour},
+			want: []string{"15 maps skipped and none completed"},
+		},
+		{
+			name: "score without completed maps",
+			s:    Submission{GameMode: "author", Score: 40000, DurationMs: hour},
+			want: []string{"score without completed maps"},
+		},
+		{
+			name: "score per map too low",
+			s:    Submission{GameMode: "author", Score: 40000, MapsCompleted: 10, DurationMs: hour},
+			want: []string{"4000 ms per map is below 5000"},
+		},
+		{
+			name: "score per map too high",
+			s:    Submission{GameMode: "author", Score: 2000000, MapsCompleted: 2, DurationMs: hour},
+			want: []string{"1000000 ms per map exceeds 600000"},
+		},
+		{
+			name: "game mode without bounds",
+			s:    Submission{GameMode: "gold", Score: 2000000, MapsCompleted: 2, DurationMs: hour},
+		},
+		{
+			name: "several rules at once",
+			s:    Submission{GameMode: "author", Score: 10000000, MapsCompleted: 10, MapsSkipped: 190, DurationMs: hour},
+			want: []string{
+				"3.3 maps per minute exceeds 3.0",
+				"19.0 skips per completed map exceeds 4.0",
+				"1000000 ms per map exceeds 600000",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Implausible(rules, tt.s); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Implausible() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if got := Implausible(&config.PlausibilityRules{}, tests[len(tests)-1].s); got != nil {
+		t.Errorf("Implausible() with no rules = %q, want nil", got)
+	}
+}
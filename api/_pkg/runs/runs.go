@@ -0,0 +1,32 @@
+package runs
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrDurationTooLong  = errors.New("run duration is longer than the time since the run started")
+	ErrDurationTooShort = errors.New("run duration is shorter than the time since the run started")
+)
+
+// CheckDuration compares the duration the client reports for a run with the
+// wall-clock time between the server-recorded start and finish. Up to
+// tolerance of difference either way absorbs latency and clock granularity;
+// a larger gap means the client's timer can't be trusted.
+func CheckDuration(startedAt, finishedAt time.Time, duration, tolerance time.Duration) error {
+	elapsed := finishedAt.Sub(startedAt)
+	if duration > elapsed+tolerance {
+		return ErrDurationTooLong
+	}
+	if duration < elapsed-tolerance {
+		return ErrDurationTooShort
+	}
+	return nil
+}
+
+// Expired reports whether a run started at startedAt can no longer be
+// finished at now.
+func Expired(startedAt, now time.Time, maxAge time.Duration) bool {
+	return now.Sub(startedAt) > maxAge
+}
@@ -0,0 +1,47 @@
+package runs
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCheckDuration(t *testing.T) {
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	tolerance := 2 * time.Minute
+
+	tests := []struct {
+		name     string
+		elapsed  time.Duration
+		duration time.Duration
+		want     error
+	}{
+		{"exact", time.Hour, time.Hour, nil},
+		{"submitted a bit late", time.Hour + 90*time.Second, time.Hour, nil},
+		{"at lower bound", time.Hour + 2*time.Minute, time.Hour, nil},
+		{"at upper bound", time.Hour, time.Hour + 2*time.Minute, nil},
+		{"claims longer than elapsed", 30 * time.Minute, time.Hour, ErrDurationTooLong},
+		{"claims much shorter than elapsed", 2 * time.Hour, time.Hour, ErrDurationTooShort},
+		{"just over upper bound", time.Hour, time.Hour + 2*time.Minute + time.Millisecond, ErrDurationTooLong},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := CheckDuration(start, start.Add(tt.elapsed), tt.duration, tolerance)
+			if got != tt.want {
+				t.Errorf("CheckDuration(elapsed %v, duration %v) = %v, want %v", tt.elapsed, tt.duration, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpired(t *testing.T) {
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	maxAge := 3 * time.Hour
+
+	if Expired(start, start.Add(maxAge), maxAge) {
+		t.Error("run at exactly maxAge should not be expired")
+	}
+	if !Expired(start, start.Add(maxAge+time.Second), maxAge) {
+		t.Error("run past maxAge should be expired")
+	}
+}
@@ -0,0 +1,161 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"rmpc-server/api/_pkg/config"
+)
+
+// ErrNotFound is returned by Get for a key that holds no blob.
+var ErrNotFound = errors.New("blob not found")
+
+// ErrNotConfigured is returned by Open when BLOB_STORAGE_DIR is unset.
+var ErrNotConfigured = errors.New("blob storage is not configured: set BLOB_STORAGE_DIR")
+
+// BlobStore keeps opaque files under slash-separated keys. Implementations
+// must be safe for concurrent use.
+type BlobStore interface {
+	// Put stores everything read from r under key, replacing any blob there.
+	Put(ctx context.Context, key string, r io.Reader) error
+	// Get opens the blob under key. The caller closes it.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes the blob under key. Deleting a missing key is not an error.
+	Delete(ctx context.Context, key string) error
+}
+
+var (
+	store     BlobStore
+	storeOnce sync.Once
+	storeErr  error
+)
+
+// Open returns the store configured by BLOB_STORAGE_DIR. It fails when the
+// setting is missing or the directory isn't writable; the check runs once.
+func Open() (BlobStore, error) {
+	storeOnce.Do(func() {
+		store, storeErr = openFS(config.Env.BlobStorageDir)
+	})
+	return store, storeErr
+}
+
+// openFS creates dir if needed and checks that files can be written to it.
+func openFS(dir string) (*FS, error) {
+	if dir == "" {
+		return nil, ErrNotConfigured
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("blob storage: %w", err)
+	}
+	probe, err := os.CreateTemp(dir, ".probe-*")
+	if err != nil {
+		return nil, fmt.Errorf("blob storage %s is not writable: %w", dir, err)
+	}
+	probe.Close()
+	os.Remove(probe.Name())
+	return NewFS(dir), nil
+}
+
+var keyPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+(/[A-Za-z0-9_.-]+)*$`)
+
+// ValidKey reports whether key is safe to hand to any BlobStore: segments of
+// letters, digits, '_', '-' and '.', none of them "." or "..".
+func ValidKey(key string) bool {
+	if len(key) > 255 || !keyPattern.MatchString(key) {
+		return false
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "." || segment == ".." {
+			return false
+		}
+	}
+	return true
+}
+
+// FS is a BlobStore on the local filesystem, one file per key below a root
+// directory. Blobs last as long as the directory does, so it must be durable
+// storage shared by every instance, not a serverless function's own disk.
+type FS struct {
+	root string
+}
+
+func NewFS(root string) *FS {
+	return &FS{root: root}
+}
+
+func (s *FS) path(key string) (string, error) {
+	if !ValidKey(key) {
+		return "", fmt.Errorf("invalid blob key %q", key)
+	}
+	return filepath.Join(s.root, filepath.FromSlash(key)), nil
+}
+
+// Put writes to a temporary file first and renames it into place, so a failed
+// or concurrent upload never leaves a partial blob under key.
+func (s *FS) Put(ctx context.Context, key string, r io.Reader) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, contextReader{ctx, r}); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func (s *FS) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return f, err
+}
+
+func (s *FS) Delete(ctx context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// contextReader stops a copy once ctx is done, e.g. when the client hangs up.
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (c contextReader) Read(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return c.r.Read(p)
+}
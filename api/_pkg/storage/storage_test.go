@@ -0,0 +1,128 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidKey(t *testing.T) {
+	tests := []struct {
+		key  string
+		want bool
+	}{
+		{"proofs/0b6e/replay.gbx", true},
+		{"proofs/0b6e", true},
+		{"a", true},
+		{"", false},
+		{"/proofs/x", false},
+		{"proofs/x/", false},
+		{"proofs//x", false},
+		{"proofs/../x", false},
+		{"proofs/./x", false},
+		{"../x", false},
+		{".hidden", false},
+		{`proofs\x`, false},
+		{"proofs/x y", false},
+		{strings.Repeat("a", 256), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			if got := ValidKey(tt.key); got != tt.want {
+				t.Errorf("ValidKey(%q) = %v, want %v", tt.key, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOpenFS(t *testing.T) {
+	if _, err := openFS(""); !errors.Is(err, ErrNotConfigured) {
+		t.Errorf("openFS(\"\") error = %v, want ErrNotConfigured", err)
+	}
+
+	dir := t.TempDir()
+	if _, err := openFS(filepath.Join(dir, "blobs")); err != nil {
+		t.Errorf("openFS() error = %v", err)
+	}
+
+	file := filepath.Join(dir, "file")
+	if err := os.WriteFile(file, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := openFS(file); err == nil {
+		t.Error("openFS() on a file succeeded, want error")
+	}
+}
+
+func TestFS(t *testing.T) {
+	ctx := context.Background()
+	store := NewFS(t.TempDir())
+
+	if _, err := store.Get(ctx, "proofs/a/b"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Get() missing error = %v, want ErrNotFound", err)
+	}
+
+	if err := store.Put(ctx, "proofs/a/b", strings.NewReader("first")); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if err := store.Put(ctx, "proofs/a/b", strings.NewReader("second")); err != nil {
+		t.Fatalf("Put() overwrite error = %v", err)
+	}
+
+	rc, err := store.Get(ctx, "proofs/a/b")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	data, err := io.ReadAll(rc)
+	rc.Close()
+	if err != nil || string(data) != "second" {
+		t.Errorf("Get() = %q, %v; want %q", data, err, "second")
+	}
+
+	if err := store.Delete(ctx, "proofs/a/b"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if err := store.Delete(ctx, "proofs/a/b"); err != nil {
+		t.Errorf("Delete() missing error = %v, want nil", err)
+	}
+	if _, err := store.Get(ctx, "proofs/a/b"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get() after Delete error = %v, want ErrNotFound", err)
+	}
+
+	if err := store.Put(ctx, "../escape", strings.NewReader("x")); err == nil {
+		t.Error("Put() with invalid key succeeded")
+	}
+}
+
+func TestFSPutFailureKeepsOldBlob(t *testing.T) {
+	ctx := context.Background()
+	store := NewFS(t.TempDir())
+
+	if err := store.Put(ctx, "k", strings.NewReader("kept")); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	failing := io.MultiReader(strings.NewReader("partial"), errReader{})
+	if err := store.Put(ctx, "k", failing); err == nil {
+		t.Fatal("Put() with failing reader succeeded")
+	}
+
+	rc, err := store.Get(ctx, "k")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	defer rc.Close()
+	if data, _ := io.ReadAll(rc); string(data) != "kept" {
+		t.Errorf("Get() = %q, want %q", data, "kept")
+	}
+}
+
+type errReader struct{}
+
+func (errReader) Read([]byte) (int, error) {
+	return 0, errors.New("connection reset")
+}
@@ -0,0 +1,68 @@
+package submit
+
+import (
+	"net/http"
+	"time"
+
+	"rmpc-server/api/_pkg/config"
+	"rmpc-server/api/_pkg/runs"
+)
+
+// BatchRun is one run of a batch upload, played while the server was
+// unreachable.
+type BatchRun struct {
+	Request
+	FinishedAt time.Time `json:"finished_at"`
+}
+
+func (run *BatchRun) span() runs.Span {
+	return runs.Span{
+		FinishedAt: run.FinishedAt,
+		Duration:   time.Duration(run.DurationMs) * time.Millisecond,
+	}
+}
+
+// Batch is what one batch upload may still add: the player's runs around it,
+// including ones stored from the batch, and how many more runs fit in the
+// player's daily limit.
+type Batch struct {
+	Now       time.Time
+	Spans     []runs.Span
+	Remaining int64
+}
+
+// Check rejects a validated run the batch can't take: linked to a server
+// tracked run, finished in the future, before BATCH_MAX_AGE or the current
+// month, during another run of the player, or over the daily limit.
+//
+// A tracked run's duration is checked against the server's clock, which the
+// client's finished_at would replace, so those runs go to POST /api/scores.
+func (b *Batch) Check(run *BatchRun) *Error {
+	if run.RunID != "" {
+		return &Error{http.StatusBadRequest, "run_id is not allowed in batch uploads"}
+	}
+	if run.FinishedAt.IsZero() {
+		return &Error{http.StatusBadRequest, "finished_at is required"}
+	}
+	if err := runs.CheckFinishedAt(run.FinishedAt, b.Now, config.Env.BatchMaxAge); err != nil {
+		return &Error{http.StatusBadRequest, err.Error()}
+	}
+
+	span := run.span()
+	for _, other := range b.Spans {
+		if span.Overlaps(other) {
+			return &Error{http.StatusConflict, "run overlaps another run of the player"}
+		}
+	}
+
+	if b.Remaining <= 0 {
+		return &Error{http.StatusTooManyRequests, "daily batch upload limit reached"}
+	}
+	return nil
+}
+
+// Added counts a stored run against the batch.
+func (b *Batch) Added(run *BatchRun) {
+	b.Spans = append(b.Spans, run.span())
+	b.Remaining--
+}
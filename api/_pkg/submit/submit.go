@@ -0,0 +1,521 @@
+package submit
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"rmpc-server/api/_pkg/audit"
+	"rmpc-server/api/_pkg/config"
+	"rmpc-server/api/_pkg/db"
+	"rmpc-server/api/_pkg/metadata"
+	"rmpc-server/api/_pkg/period"
+	"rmpc-server/api/_pkg/pluginversion"
+	"rmpc-server/api/_pkg/preset"
+	"rmpc-server/api/_pkg/runs"
+	"rmpc-server/api/_pkg/storage"
+	"rmpc-server/api/_pkg/validate"
+	"rmpc-server/db/.gen/rmpc/public/model"
+)
+
+// Request is a score submission, sent on its own to POST /api/scores or as
+// part of a batch.
+type Request struct {
+	GameMode      string           `json:"game_mode"      validate:"required,oneof=author gold custom"`
+	Score         int32            `json:"score"           validate:"gte=0"`
+	MapsCompleted int32            `json:"maps_completed"  validate:"gte=0"`
+	MapsSkipped   int32            `json:"maps_skipped"    validate:"gte=0"`
+	DurationMs    int32            `json:"duration_ms"     validate:"gte=60000,lte=7200000"`
+	Metadata      json.RawMessage  `json:"metadata,omitempty"`
+	RunID         string           `json:"run_id,omitempty"`
+	Settings      *preset.Settings `json:"settings,omitempty"` // custom mode only
+
+	meta *metadata.V1 // parsed by Validate
+}
+
+// Response describes a stored score.
+type Response struct {
+	ID           string        `json:"id"`
+	CreatedAt    time.Time     `json:"created_at"`
+	ReviewStatus string        `json:"review_status,omitempty"`
+	Preset       string        `json:"preset,omitempty"`
+	PersonalBest *PersonalBest `json:"personal_best,omitempty"`
+	Standing     *Standing     `json:"standing,omitempty"`
+}
+
+// PersonalBest compares a run with the player's earlier scores on the same
+// leaderboard: same game mode, preset and ruleset. Previous bests are null
+// when there were none.
+type PersonalBest struct {
+	PreviousAllTime *int32 `json:"previous_all_time"`
+	PreviousMonth   *int32 `json:"previous_month"`
+	NewAllTime      bool   `json:"new_all_time"`
+	NewMonth        bool   `json:"new_month"`
+}
+
+// Standing is where the player places after a run, for the month the run
+// finished in and all time. A record is set when the run itself tops the
+// board. Left out for runs held for review or proof.
+type Standing struct {
+	MonthRank     int  `json:"month_rank"`
+	AllTimeRank   int  `json:"all_time_rank"`
+	MonthRecord   bool `json:"month_record"`
+	AllTimeRecord bool `json:"all_time_record"`
+}
+
+// Fake is what a banned player is told about a score that was thrown away.
+func Fake(createdAt time.Time) Response {
+	return Response{
+		ID:           uuid.New().String(),
+		CreatedAt:    createdAt,
+		ReviewStatus: "accepted",
+	}
+}
+
+// Error is a rejected submission, with the HTTP status and message to answer
+// with.
+type Error struct {
+	Status  int
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+var errUnavailable = &Error{http.StatusServiceUnavailable, "service unavailable"}
+
+// Client is the plugin a submission comes from.
+type Client struct {
+	Version *pluginversion.Version // nil if the plugin didn't send one
+	Ruleset int16
+}
+
+// CheckClient reads the X-Plugin-Version header, enforces the minimum and
+// blocked plugin versions, and picks the ruleset the client's runs count
+// under. Versions turned away are recorded in the audit log against the
+// player.
+func CheckClient(r *http.Request, playerID uuid.UUID) (Client, *Error) {
+	version := pluginversion.Unknown
+	var client Client
+	if h := r.Header.Get("X-Plugin-Version"); h != "" {
+		v, err := pluginversion.Parse(h)
+		if err != nil {
+			return Client{}, &Error{http.StatusBadRequest, "invalid X-Plugin-Version header"}
+		}
+		version, client.Version = v, &v
+	}
+
+	rules, err := config.Plugin()
+	if err == nil {
+		err = pluginversion.Check(rules, version)
+	}
+	var unsupported *pluginversion.UnsupportedError
+	if errors.As(err, &unsupported) {
+		audit.Record(r, audit.Entry{
+			Actor:      playerID,
+			Action:     audit.ActionScoreRejected,
+			TargetType: audit.TargetPlayer,
+			TargetID:   playerID.String(),
+			Details: map[string]any{
+				"reason":         "plugin_version",
+				"plugin_version": r.Header.Get("X-Plugin-Version"),
+				"rule":           unsupported.Rule,
+			},
+		})
+		return Client{}, &Error{http.StatusUpgradeRequired, err.Error()}
+	}
+	if err != nil {
+		slog.Error("plugin rules error", "error", err)
+		return Client{}, errUnavailable
+	}
+	client.Ruleset = pluginversion.Ruleset(rules, version)
+	return client, nil
+}
+
+// Validate checks req on its own, without looking at the database.
+func Validate(req *Request) *Error {
+	if err := validate.Struct(req); err != nil {
+		return &Error{http.StatusBadRequest, validate.FormatError(err)}
+	}
+	if (req.GameMode == "custom") != (req.Settings != nil) {
+		return &Error{http.StatusBadRequest, "settings are required for custom runs and not allowed otherwise"}
+	}
+
+	// Validate metadata against its schema version
+	if len(req.Metadata) > 0 {
+		meta, err := metadata.Parse(req.Metadata)
+		if err != nil {
+			return &Error{http.StatusBadRequest, err.Error()}
+		}
+		req.meta = meta
+	}
+
+	// The settings pick the board, so the metadata can't claim another limit
+	if req.Settings != nil && req.meta != nil && req.meta.Settings.TimeLimitMs != 0 &&
+		req.meta.Settings.TimeLimitMs != req.Settings.TimeLimitMs {
+		return &Error{http.StatusBadRequest, "settings.time_limit_ms does not match metadata.settings.time_limit_ms"}
+	}
+	return nil
+}
+
+// Prepare checks a validated req against its run and the plausibility rules
+// and returns the score to store. finishedAt is when the run ended: now for a
+// live submission, the client's word for one uploaded later. Anything
+// suspicious holds the score for review rather than rejecting it.
+func Prepare(r *http.Request, database *sql.DB, playerID uuid.UUID, client Client, req *Request, finishedAt time.Time) (*db.ScoreInput, *Error) {
+	var runID *uuid.UUID
+	var flags []string
+	if req.RunID != "" {
+		id, err := checkRun(r, database, playerID, req, finishedAt)
+		if err != nil {
+			return nil, err
+		}
+		runID = &id
+
+		mismatch, dbErr := runMismatch(database, id, req)
+		if dbErr != nil {
+			slog.Error("run maps lookup error", "error", dbErr)
+			return nil, errUnavailable
+		}
+		if mismatch != "" {
+			flags = append(flags, "totals disagree with map results: "+mismatch)
+		}
+	} else if config.Env.RequireRun {
+		return nil, &Error{http.StatusBadRequest, "run_id is required"}
+	}
+
+	rules, err := config.Plausibility()
+	if err != nil {
+		slog.Error("plausibility rules error", "error", err)
+	}
+	flags = append(flags, plausibilityFlags(rules, req)...)
+
+	input := &db.ScoreInput{
+		PlayerID:      playerID,
+		GameMode:      req.GameMode,
+		Score:         req.Score,
+		MapsCompleted: req.MapsCompleted,
+		MapsSkipped:   req.MapsSkipped,
+		DurationMs:    req.DurationMs,
+		Ruleset:       client.Ruleset,
+		RunID:         runID,
+	}
+	if len(flags) > 0 {
+		s := strings.Join(flags, "; ")
+		input.FlagReason = &s
+		input.PendingReview = true
+	}
+	// Convert metadata to *string for go-jet JSONB column
+	if len(req.Metadata) > 0 {
+		s := string(req.Metadata)
+		input.Metadata = &s
+	}
+	if req.Settings != nil {
+		hash, settings := req.Settings.Hash(), req.Settings.Canonical()
+		input.SettingsHash = &hash
+		input.Settings = &settings
+	}
+	if req.meta != nil {
+		version := int16(req.meta.Version)
+		input.MetadataVersion = &version
+		input.PluginVersion = &req.meta.PluginVersion
+		input.GameVersion = &req.meta.GameVersion
+	}
+	if client.Version != nil {
+		s := client.Version.String()
+		input.PluginVersion = &s
+	}
+
+	if !input.PendingReview {
+		needsProof, err := requiresProof(database, input, finishedAt)
+		if err != nil {
+			slog.Error("proof check error", "error", err)
+			return nil, errUnavailable
+		}
+		input.PendingProof = needsProof
+	}
+	return input, nil
+}
+
+// plausibilityFlags checks req against the plausibility rules. Without rules,
+// as when config/plausibility.yaml can't be loaded, nothing can be checked
+// and the run is held for review instead of published unchecked.
+func plausibilityFlags(rules *config.PlausibilityRules, req *Request) []string {
+	if rules == nil {
+		return []string{"plausibility rules unavailable"}
+	}
+	return runs.Implausible(rules, runs.Submission{
+		GameMode:      req.GameMode,
+		Score:         req.Score,
+		MapsCompleted: req.MapsCompleted,
+		MapsSkipped:   req.MapsSkipped,
+		DurationMs:    req.DurationMs,
+	})
+}
+
+// requiresProof reports whether the score would become the player's best in
+// the top PROOF_REQUIRED_TOP_N of its all-time leaderboard, as a run submitted
+// at finishedAt. Without a working blob store no proof could be attached, so
+// nothing requires one.
+func requiresProof(database *sql.DB, input *db.ScoreInput, finishedAt time.Time) (bool, error) {
+	topN := config.Env.ProofRequiredTopN
+	if topN == 0 || input.Score <= 0 {
+		return false, nil
+	}
+	if _, err := storage.Open(); err != nil {
+		slog.Error("proof gate disabled", "error", err)
+		return false, nil
+	}
+
+	params := leaderboardParams(input)
+	pb, err := db.GetPersonalBest(database, params, input.PlayerID, uuid.Nil)
+	if err != nil {
+		return false, err
+	}
+	// An equal score can still be a better run on the tie-breakers
+	if pb != nil && *pb > input.Score {
+		return false, nil
+	}
+	rank, err := db.GetProjectedRank(database, params, input.PlayerID, db.RankedRun{
+		Score:       input.Score,
+		MapsSkipped: input.MapsSkipped,
+		DurationMs:  input.DurationMs,
+		CreatedAt:   finishedAt,
+	})
+	if err != nil {
+		return false, err
+	}
+	return rank <= topN, nil
+}
+
+// leaderboardParams selects the all-time leaderboard the score counts on.
+func leaderboardParams(input *db.ScoreInput) db.LeaderboardParams {
+	params := db.LeaderboardParams{GameMode: input.GameMode, Ruleset: input.Ruleset}
+	if input.SettingsHash != nil {
+		params.SettingsHash = *input.SettingsHash
+	}
+	return params
+}
+
+// Store inserts a prepared score and records it in the audit log if it was
+// flagged.
+func Store(r *http.Request, database *sql.DB, input *db.ScoreInput) (*Response, *Error) {
+	id, createdAt, err := db.InsertScore(database, *input)
+	if errors.Is(err, db.ErrRunFinished) {
+		return nil, &Error{http.StatusConflict, "run has already been submitted"}
+	}
+	if errors.Is(err, db.ErrBatchLimit) {
+		return nil, &Error{http.StatusTooManyRequests, err.Error()}
+	}
+	if errors.Is(err, db.ErrRunOverlaps) {
+		return nil, &Error{http.StatusConflict, err.Error()}
+	}
+	if err != nil {
+		slog.Error("insert score error", "error", err)
+		return nil, errUnavailable
+	}
+
+	if input.FlagReason != nil {
+		audit.Record(r, audit.Entry{
+			Actor:      input.PlayerID,
+			Action:     audit.ActionScoreFlagged,
+			TargetType: audit.TargetScore,
+			TargetID:   id.String(),
+			Details:    map[string]any{"reason": *input.FlagReason},
+		})
+	}
+
+	resp := &Response{
+		ID:           id.String(),
+		CreatedAt:    createdAt,
+		ReviewStatus: "accepted",
+	}
+	if input.PendingReview {
+		resp.ReviewStatus = "pending_review"
+	} else if input.PendingProof {
+		resp.ReviewStatus = "pending_proof"
+	}
+	if input.SettingsHash != nil {
+		resp.Preset = *input.SettingsHash
+	}
+
+	// The score is stored either way, so this is best effort
+	if err := describe(database, input, id, createdAt, resp); err != nil {
+		slog.Error("score standing error", "error", err)
+	}
+	return resp, nil
+}
+
+// describe fills in how the stored score compares with the player's earlier
+// ones and where it places them, using the same rankings as the leaderboard.
+func describe(database *sql.DB, input *db.ScoreInput, scoreID uuid.UUID, createdAt time.Time, resp *Response) error {
+	allTime, month := describedBoards(input, createdAt)
+
+	previousAllTime, err := db.GetPersonalBest(database, allTime, input.PlayerID, scoreID)
+	if err != nil {
+		return err
+	}
+	previousMonth, err := db.GetPersonalBest(database, month, input.PlayerID, scoreID)
+	if err != nil {
+		return err
+	}
+
+	// Only accepted scores are on the leaderboard
+	ranked := !input.PendingReview && !input.PendingProof && input.Score > 0
+	pb := personalBest(input.Score, ranked, previousAllTime, previousMonth)
+	resp.PersonalBest = &pb
+	if !ranked {
+		return nil
+	}
+
+	monthStanding, err := db.GetStanding(database, month, input.PlayerID)
+	if err != nil {
+		return err
+	}
+	allTimeStanding, err := db.GetStanding(database, allTime, input.PlayerID)
+	if err != nil {
+		return err
+	}
+	resp.Standing = standing(scoreID, monthStanding, allTimeStanding)
+	return nil
+}
+
+// describedBoards are the leaderboards a score is described on: the all-time
+// one and the one of the UTC month it was created in, filtered by ruleset,
+// game mode and preset exactly as GET /api/leaderboard filters them.
+func describedBoards(input *db.ScoreInput, createdAt time.Time) (allTime, month db.LeaderboardParams) {
+	allTime = leaderboardParams(input)
+	month = allTime
+	r, _ := period.Resolve(period.Month, createdAt)
+	month.StartTime, month.EndTime = r.Start, r.End
+	return allTime, month
+}
+
+// personalBest compares a score with the player's previous bests, nil where
+// there were none. A score that isn't ranked is never a new best.
+func personalBest(score int32, ranked bool, previousAllTime, previousMonth *int32) PersonalBest {
+	return PersonalBest{
+		PreviousAllTime: previousAllTime,
+		PreviousMonth:   previousMonth,
+		NewAllTime:      ranked && beats(score, previousAllTime),
+		NewMonth:        ranked && beats(score, previousMonth),
+	}
+}
+
+func beats(score int32, previous *int32) bool {
+	return previous == nil || score > *previous
+}
+
+// standing reports the player's standings on the month and all-time boards
+// after the score was stored, or nil if either is missing. The score set a
+// record if it is itself the player's entry ranked first.
+func standing(scoreID uuid.UUID, month, allTime *db.Standing) *Standing {
+	if month == nil || allTime == nil {
+		return nil
+	}
+	return &Standing{
+		MonthRank:     month.Rank,
+		AllTimeRank:   allTime.Rank,
+		MonthRecord:   month.Rank == 1 && month.ScoreID == scoreID,
+		AllTimeRecord: allTime.Rank == 1 && allTime.ScoreID == scoreID,
+	}
+}
+
+// checkRun validates the run a submission claims to finish: it must be the
+// player's, not yet submitted, finished before it expired, and the reported
+// duration must match the time between its start and finishedAt.
+func checkRun(r *http.Request, database *sql.DB, playerID uuid.UUID, req *Request, finishedAt time.Time) (uuid.UUID, *Error) {
+	runID, err := uuid.Parse(req.RunID)
+	if err != nil {
+		return uuid.Nil, &Error{http.StatusBadRequest, "invalid run_id"}
+	}
+
+	run, err := db.FindRun(database, runID, playerID)
+	if err != nil {
+		slog.Error("run lookup error", "error", err)
+		return uuid.Nil, errUnavailable
+	}
+	if serr := runStateError(run, req, finishedAt); serr != nil {
+		return uuid.Nil, serr
+	}
+
+	duration := time.Duration(req.DurationMs) * time.Millisecond
+	if err := runs.CheckDuration(run.StartedAt, finishedAt, duration, config.Env.RunDurationTolerance); err != nil {
+		audit.Record(r, audit.Entry{
+			Actor:      playerID,
+			Action:     audit.ActionScoreRejected,
+			TargetType: audit.TargetPlayer,
+			TargetID:   playerID.String(),
+			Details: map[string]any{
+				"reason":      "run_duration",
+				"run_id":      runID,
+				"duration_ms": req.DurationMs,
+				"elapsed_ms":  finishedAt.Sub(run.StartedAt).Milliseconds(),
+			},
+		})
+		return uuid.Nil, &Error{http.StatusBadRequest, err.Error()}
+	}
+
+	return runID, nil
+}
+
+// runStateError checks the run looked up for a submission, nil if the player
+// has no such run: it must be unsubmitted, unexpired at finishedAt, and of the
+// submission's game mode.
+func runStateError(run *model.Runs, req *Request, finishedAt time.Time) *Error {
+	if run == nil {
+		return &Error{http.StatusNotFound, "run not found"}
+	}
+	if run.FinishedAt != nil {
+		return &Error{http.StatusConflict, "run has already been submitted"}
+	}
+	if runs.Expired(run.StartedAt, finishedAt, config.Env.RunMaxAge) {
+		return &Error{http.StatusGone, "run has expired"}
+	}
+	if run.GameMode.String() != req.GameMode {
+		return &Error{http.StatusBadRequest, "game_mode does not match the run"}
+	}
+	return nil
+}
+
+// runMismatch recomputes the submission's totals from the map results
+// reported for its run and describes any disagreement, leaving out the score
+// unless MAP_RESULTS_SCORE_CHECK is set. Runs without map results (older
+// plugin versions) can't be checked and pass.
+func runMismatch(database *sql.DB, runID uuid.UUID, req *Request) (string, error) {
+	rows, err := db.ListRunMaps(database, runID)
+	if err != nil {
+		return "", err
+	}
+	return mapsMismatch(rows, req), nil
+}
+
+// mapsMismatch is runMismatch on the run's map results.
+func mapsMismatch(rows []model.RunMaps, req *Request) string {
+	if len(rows) == 0 {
+		return ""
+	}
+
+	results := make([]runs.MapResult, len(rows))
+	for i, m := range rows {
+		results[i] = runs.MapResult{
+			TimeMs:       m.TimeMs,
+			TargetTimeMs: m.TargetTimeMs,
+			Skipped:      m.Skipped,
+			Broken:       m.Broken,
+		}
+	}
+
+	return runs.ComputeTotals(results).Mismatch(runs.Totals{
+		Score:         int64(req.Score),
+		MapsCompleted: int64(req.MapsCompleted),
+		MapsSkipped:   int64(req.MapsSkipped),
+	}, config.Env.MapResultsScoreCheck)
+}
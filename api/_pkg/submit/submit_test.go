@@ -0,0 +1,366 @@
+package submit
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"rmpc-server/api/_pkg/config"
+	"rmpc-server/api/_pkg/db"
+	"rmpc-server/api/_pkg/period"
+	"rmpc-server/api/_pkg/preset"
+	"rmpc-server/api/_pkg/runs"
+	"rmpc-server/db/.gen/rmpc/public/model"
+)
+
+func ms(v int32) *int32 { return &v }
+
+func TestValidate(t *testing.T) {
+	valid := Request{GameMode: "author", Score: 90000, MapsCompleted: 2, DurationMs: 3600000}
+	custom := func(r *Request, metadataLimit string) {
+		r.GameMode = "custom"
+		r.Settings = &preset.Settings{TimeLimitMs: 3600000, TargetMedal: "gold"}
+		r.Metadata = []byte(`{"version": 1, "plugin_version": "1.4.0", "game_version": "2026-05-12_17_00",
+			"settings": {"time_limit_ms": ` + metadataLimit + `}}`)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(*Request)
+		status int // 0 for valid
+	}{
+		{"valid", func(*Request) {}, 0},
+		{"unknown game mode", func(r *Request) { r.GameMode = "silver" }, http.StatusBadRequest},
+		{"negative score", func(r *Request) { r.Score = -1 }, http.StatusBadRequest},
+		{"too short", func(r *Request) { r.DurationMs = 59999 }, http.StatusBadRequest},
+		{"too long", func(r *Request) { r.DurationMs = 7200001 }, http.StatusBadRequest},
+		{"custom without settings", func(r *Request) { r.GameMode = "custom" }, http.StatusBadRequest},
+		{"invalid metadata", func(r *Request) { r.Metadata = []byte(`{"version":"x"}`) }, http.StatusBadRequest},
+		{"custom", func(r *Request) { custom(r, "3600000") }, 0},
+		{"custom without metadata time limit", func(r *Request) { custom(r, "0") }, 0},
+		{"custom with another time limit", func(r *Request) { custom(r, "1800000") }, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := valid
+			tt.modify(&req)
+			err := Validate(&req)
+			if tt.status == 0 {
+				if err != nil {
+					t.Errorf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || err.Status != tt.status {
+				t.Errorf("Validate() = %v, want status %d", err, tt.status)
+			}
+		})
+	}
+}
+
+func TestRunStateError(t *testing.T) {
+	finishedAt := time.Date(2026, 1, 10, 12, 0, 0, 0, time.UTC)
+	submitted := finishedAt.Add(-time.Minute)
+	run := model.Runs{
+		ID:        uuid.New(),
+		GameMode:  model.GameMode_Author,
+		StartedAt: finishedAt.Add(-time.Hour),
+	}
+
+	tests := []struct {
+		name   string
+		run    *model.Runs
+		modify func(*model.Runs)
+		mode   string
+		status int // 0 for no error
+	}{
+		{"open run", &run, nil, "author", 0},
+		{"no such run", nil, nil, "author", http.StatusNotFound},
+		{"already submitted", &run, func(r *model.Runs) { r.FinishedAt = &submitted }, "author", http.StatusConflict},
+		{"expired", &run, func(r *model.Runs) { r.StartedAt = finishedAt.Add(-4 * time.Hour) }, "author", http.StatusGone},
+		{"other game mode", &run, nil, "gold", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var r *model.Runs
+			if tt.run != nil {
+				copied := *tt.run
+				if tt.modify != nil {
+					tt.modify(&copied)
+				}
+				r = &copied
+			}
+			err := runStateError(r, &Request{GameMode: tt.mode}, finishedAt)
+			if tt.status == 0 {
+				if err != nil {
+					t.Errorf("runStateError() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || err.Status != tt.status {
+				t.Errorf("runStateError() = %v, want status %d", err, tt.status)
+			}
+		})
+	}
+}
+
+func TestPlausibilityFlags(t *testing.T) {
+	req := &Request{GameMode: "author", Score: 90000, MapsCompleted: 2, DurationMs: 3600000}
+
+	if got := plausibilityFlags(&config.PlausibilityRules{}, req); len(got) != 0 {
+		t.Errorf("plausibilityFlags() = %v, want none", got)
+	}
+	rules := &config.PlausibilityRules{MaxMapsPerMinute: 0.01}
+	if got := plausibilityFlags(rules, req); len(got) != 1 {
+		t.Errorf("plausibilityFlags() = %v, want one flag", got)
+	}
+
+	// Unloadable rules hold every run
+	want := []string{"plausibility rules unavailable"}
+	if got := plausibilityFlags(nil, req); !reflect.DeepEqual(got, want) {
+		t.Errorf("plausibilityFlags(nil) = %v, want %v", got, want)
+	}
+}
+
+func TestMapsMismatch(t *testing.T) {
+	maps := []model.RunMaps{
+		{TimeMs: ms(41000), TargetTimeMs: 45000},
+		{TimeMs: ms(29000), TargetTimeMs: 30000},
+		{TargetTimeMs: 50000, Skipped: true},
+	}
+
+	tests := []struct {
+		name       string
+		maps       []model.RunMaps
+		req        Request
+		checkScore bool
+		want       string
+	}{
+		{"agrees", maps, Request{Score: 75000, MapsCompleted: 2, MapsSkipped: 1}, true, ""},
+		{"no map results", nil, Request{Score: 75000, MapsCompleted: 2}, true, ""},
+		{"score", maps, Request{Score: 80000, MapsCompleted: 2, MapsSkipped: 1}, true, "score 80000, maps say 75000"},
+		{"score not checked", maps, Request{Score: 80000, MapsCompleted: 2, MapsSkipped: 1}, false, ""},
+		{
+			name: "completed and skipped",
+			maps: maps,
+			req:  Request{Score: 75000, MapsCompleted: 3, MapsSkipped: 0},
+			want: "maps_completed 3, maps say 2; maps_skipped 0, maps say 1",
+		},
+	}
+
+	defer func(v bool) { config.Env.MapResultsScoreCheck = v }(config.Env.MapResultsScoreCheck)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config.Env.MapResultsScoreCheck = tt.checkScore
+			if got := mapsMismatch(tt.maps, &tt.req); got != tt.want {
+				t.Errorf("mapsMismatch() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBatchCheck(t *testing.T) {
+	now := time.Date(2026, 1, 10, 12, 0, 0, 0, time.UTC)
+	earlier := runs.Span{FinishedAt: now.Add(-5 * time.Hour), Duration: time.Hour} // 06:00-07:00
+
+	run := func(finishedAt time.Time, duration time.Duration) *BatchRun {
+		return &BatchRun{
+			Request:    Request{GameMode: "author", DurationMs: int32(duration.Milliseconds())},
+			FinishedAt: finishedAt,
+		}
+	}
+
+	tests := []struct {
+		name      string
+		run       *BatchRun
+		remaining int64
+		status    int // 0 for accepted
+	}{
+		{"accepted", run(now.Add(-time.Hour), time.Hour), 1, 0},
+		{"right after another run", run(now.Add(-4*time.Hour), time.Hour), 1, 0},
+		{"no finish time", run(time.Time{}, time.Hour), 1, http.StatusBadRequest},
+		{"tracked run", &BatchRun{
+			Request:    Request{GameMode: "author", DurationMs: 3600000, RunID: uuid.NewString()},
+			FinishedAt: now.Add(-time.Hour),
+		}, 1, http.StatusBadRequest},
+		{"finished in the future", run(now.Add(time.Hour), time.Hour), 1, http.StatusBadRequest},
+		{"stale finish time", run(now.Add(-73*time.Hour), time.Hour), 1, http.StatusBadRequest},
+		{"finished last month", run(time.Date(2025, 12, 31, 23, 0, 0, 0, time.UTC), time.Hour), 1, http.StatusBadRequest},
+		{"overlaps another run", run(now.Add(-4*time.Hour-time.Minute), time.Hour), 1, http.StatusConflict},
+		{"daily limit reached", run(now.Add(-time.Hour), time.Hour), 0, http.StatusTooManyRequests},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := Batch{Now: now, Spans: []runs.Span{earlier}, Remaining: tt.remaining}
+			err := b.Check(tt.run)
+			if tt.status == 0 {
+				if err != nil {
+					t.Errorf("Check() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || err.Status != tt.status {
+				t.Errorf("Check() = %v, want status %d", err, tt.status)
+			}
+		})
+	}
+}
+
+func TestBatchAdded(t *testing.T) {
+	now := time.Date(2026, 1, 10, 12, 0, 0, 0, time.UTC)
+	b := Batch{Now: now, Remaining: 2}
+	first := &BatchRun{Request: Request{DurationMs: 3600000}, FinishedAt: now.Add(-2 * time.Hour)}
+
+	if err := b.Check(first); err != nil {
+		t.Fatalf("Check() = %v, want nil", err)
+	}
+	b.Added(first)
+	if b.Remaining != 1 {
+		t.Errorf("Remaining = %d, want 1", b.Remaining)
+	}
+
+	// The same run again overlaps the one just added
+	if err := b.Check(first); err == nil || err.Status != http.StatusConflict {
+		t.Errorf("Check() again = %v, want status %d", err, http.StatusConflict)
+	}
+}
+
+func TestDescribedBoards(t *testing.T) {
+	hash := "0123456789abcdef"
+	input := &db.ScoreInput{GameMode: "custom", SettingsHash: &hash, Ruleset: 3}
+	// Late on the last day of January in UTC-5 is already February in UTC
+	createdAt := time.Date(2026, 1, 31, 22, 0, 0, 0, time.FixedZone("EST", -5*3600))
+
+	allTime, month := describedBoards(input, createdAt)
+
+	// The all-time board of GET /api/leaderboard?game_mode=custom&preset=H&ruleset=3
+	want := db.LeaderboardParams{GameMode: "custom", SettingsHash: hash, Ruleset: 3}
+	if !reflect.DeepEqual(allTime, want) {
+		t.Errorf("all-time board = %+v, want %+v", allTime, want)
+	}
+
+	// ... and with &month=2026-02
+	feb, _ := period.Resolve(period.Month, time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC))
+	want.StartTime, want.EndTime = feb.Start, feb.End
+	if !reflect.DeepEqual(month, want) {
+		t.Errorf("month board = %+v, want %+v", month, want)
+	}
+}
+
+func TestPersonalBest(t *testing.T) {
+	score := func(v int32) *int32 { return &v }
+
+	tests := []struct {
+		name            string
+		score           int32
+		ranked          bool
+		previousAllTime *int32
+		previousMonth   *int32
+		want            PersonalBest
+	}{
+		{
+			name:   "first run",
+			score:  600000,
+			ranked: true,
+			want:   PersonalBest{NewAllTime: true, NewMonth: true},
+		},
+		{
+			name:            "improved",
+			score:           600000,
+			ranked:          true,
+			previousAllTime: score(500000),
+			previousMonth:   score(400000),
+			want:            PersonalBest{PreviousAllTime: score(500000), PreviousMonth: score(400000), NewAllTime: true, NewMonth: true},
+		},
+		{
+			name:            "best this month only",
+			score:           600000,
+			ranked:          true,
+			previousAllTime: score(700000),
+			previousMonth:   score(500000),
+			want:            PersonalBest{PreviousAllTime: score(700000), PreviousMonth: score(500000), NewMonth: true},
+		},
+		{
+			name:            "not a personal best",
+			score:           600000,
+			ranked:          true,
+			previousAllTime: score(700000),
+			previousMonth:   score(650000),
+			want:            PersonalBest{PreviousAllTime: score(700000), PreviousMonth: score(650000)},
+		},
+		{
+			name:            "equal is not a new best",
+			score:           600000,
+			ranked:          true,
+			previousAllTime: score(600000),
+			previousMonth:   score(600000),
+			want:            PersonalBest{PreviousAllTime: score(600000), PreviousMonth: score(600000)},
+		},
+		{
+			name:  "first run held for review",
+			score: 600000,
+			want:  PersonalBest{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := personalBest(tt.score, tt.ranked, tt.previousAllTime, tt.previousMonth)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("personalBest() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStanding(t *testing.T) {
+	scoreID, olderID := uuid.New(), uuid.New()
+
+	tests := []struct {
+		name    string
+		month   *db.Standing
+		allTime *db.Standing
+		want    *Standing
+	}{
+		{
+			name:    "records",
+			month:   &db.Standing{ScoreID: scoreID, Rank: 1},
+			allTime: &db.Standing{ScoreID: scoreID, Rank: 1},
+			want:    &Standing{MonthRank: 1, AllTimeRank: 1, MonthRecord: true, AllTimeRecord: true},
+		},
+		{
+			name:    "monthly record, older all-time best",
+			month:   &db.Standing{ScoreID: scoreID, Rank: 1},
+			allTime: &db.Standing{ScoreID: olderID, Rank: 4},
+			want:    &Standing{MonthRank: 1, AllTimeRank: 4, MonthRecord: true},
+		},
+		{
+			name:    "older best holds the record",
+			month:   &db.Standing{ScoreID: olderID, Rank: 1},
+			allTime: &db.Standing{ScoreID: olderID, Rank: 1},
+			want:    &Standing{MonthRank: 1, AllTimeRank: 1},
+		},
+		{
+			name:    "tied for second",
+			month:   &db.Standing{ScoreID: scoreID, Rank: 2},
+			allTime: &db.Standing{ScoreID: scoreID, Rank: 7},
+			want:    &Standing{MonthRank: 2, AllTimeRank: 7},
+		},
+		{"not on a board", nil, &db.Standing{ScoreID: scoreID, Rank: 3}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := standing(scoreID, tt.month, tt.allTime)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("standing() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
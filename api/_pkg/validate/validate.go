@@ -44,7 +44,7 @@ func FormatError(err error) string {
 }
 
 func formatFieldError(fe validator.FieldError) string {
-	field := fe.Field()
+	field := fieldPath(fe)
 	switch fe.Tag() {
 	case "required":
 		return field + " is required"
@@ -65,7 +65,27 @@ func formatFieldError(fe validator.FieldError) string {
 		return field + " must be at least " + fe.Param()
 	case "lte":
 		return field + " must not exceed " + fe.Param()
+	case "min":
+		if fe.Kind() == reflect.String {
+			return field + " must be at least " + fe.Param() + " characters"
+		}
+		return field + " must have at least " + fe.Param() + " items"
+	case "max":
+		if fe.Kind() == reflect.String {
+			return field + " must not exceed " + fe.Param() + " characters"
+		}
+		return field + " must not exceed " + fe.Param() + " items"
 	default:
 		return field + " is invalid"
 	}
 }
+
+// fieldPath names the field by its JSON path, e.g. "maps[2].map_uid", leaving
+// out the Go type name of the top-level struct.
+func fieldPath(fe validator.FieldError) string {
+	ns := fe.Namespace()
+	if i := strings.IndexByte(ns, '.'); i >= 0 {
+		return ns[i+1:]
+	}
+	return fe.Field()
+}
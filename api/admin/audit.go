@@ -0,0 +1,130 @@
+package handler
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"rmpc-server/api/_pkg/auth"
+	"rmpc-server/api/_pkg/db"
+	"rmpc-server/api/_pkg/response"
+	"rmpc-server/api/_pkg/validate"
+)
+
+type auditQuery struct {
+	TargetType string `json:"target_type" validate:"omitempty,oneof=player session api_key score metric"`
+	TargetID   string `json:"target_id"   validate:"omitempty,max=255"`
+}
+
+type auditEntryJSON struct {
+	ID         string          `json:"id"`
+	CreatedAt  time.Time       `json:"created_at"`
+	ActorID    *string         `json:"actor_id"`
+	Action     string          `json:"action"`
+	TargetType string          `json:"target_type"`
+	TargetID   *string         `json:"target_id"`
+	IP         *string         `json:"ip"`
+	Details    json.RawMessage `json:"details,omitempty"`
+}
+
+type auditResponse struct {
+	Entries []auditEntryJSON `json:"entries"`
+}
+
+const (
+	defaultAuditLimit = 100
+	maxAuditLimit     = 500
+)
+
+// Audit handles GET /api/admin/audit. Optional filters: target_type and
+// target_id, from and to (RFC 3339, from inclusive, to exclusive) and limit.
+// Entries are returned newest first.
+func Audit(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		response.Error(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	auth.RequireScope(auth.ScopeAuditRead, handleListAudit)(w, r)
+}
+
+func handleListAudit(w http.ResponseWriter, r *http.Request, _ *auth.Principal) {
+	q := r.URL.Query()
+
+	query := auditQuery{
+		TargetType: q.Get("target_type"),
+		TargetID:   q.Get("target_id"),
+	}
+	if err := validate.Struct(query); err != nil {
+		response.Error(w, http.StatusBadRequest, validate.FormatError(err))
+		return
+	}
+
+	filter := db.AuditFilter{
+		TargetType: query.TargetType,
+		TargetID:   query.TargetID,
+		Limit:      defaultAuditLimit,
+	}
+	if s := q.Get("from"); s != "" {
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "invalid from, expected RFC 3339 timestamp")
+			return
+		}
+		filter.From = t
+	}
+	if s := q.Get("to"); s != "" {
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "invalid to, expected RFC 3339 timestamp")
+			return
+		}
+		filter.To = t
+	}
+	if s := q.Get("limit"); s != "" {
+		limit, err := strconv.ParseInt(s, 10, 64)
+		if err != nil || limit < 1 || limit > maxAuditLimit {
+			response.Error(w, http.StatusBadRequest, "limit must be between 1 and "+strconv.Itoa(maxAuditLimit))
+			return
+		}
+		filter.Limit = limit
+	}
+
+	database, err := db.GetDB()
+	if err != nil {
+		slog.Error("database connection error", "error", err)
+		response.Error(w, http.StatusServiceUnavailable, "service unavailable")
+		return
+	}
+
+	rows, err := db.ListAuditEntries(database, filter)
+	if err != nil {
+		slog.Error("list audit entries error", "error", err)
+		response.Error(w, http.StatusServiceUnavailable, "service unavailable")
+		return
+	}
+
+	entries := make([]auditEntryJSON, len(rows))
+	for i, e := range rows {
+		entries[i] = auditEntryJSON{
+			ID:         e.ID.String(),
+			CreatedAt:  e.CreatedAt,
+			Action:     e.Action,
+			TargetType: e.TargetType,
+			TargetID:   e.TargetID,
+			IP:         e.IP,
+		}
+		if e.ActorID != nil {
+			actorID := e.ActorID.String()
+			entries[i].ActorID = &actorID
+		}
+		if e.Details != nil {
+			entries[i].Details = json.RawMessage(*e.Details)
+		}
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	response.JSON(w, http.StatusOK, auditResponse{Entries: entries})
+}
@@ -0,0 +1,203 @@
+package handler
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"rmpc-server/api/_pkg/audit"
+	"rmpc-server/api/_pkg/auth"
+	"rmpc-server/api/_pkg/db"
+	"rmpc-server/api/_pkg/response"
+	"rmpc-server/api/_pkg/validate"
+)
+
+type banRequest struct {
+	OpenplanetID   string `json:"openplanet_id"    validate:"required"`
+	Reason         string `json:"reason"           validate:"omitempty,max=500"`
+	ExpiresInHours int    `json:"expires_in_hours" validate:"omitempty,gte=1,lte=8760"`
+}
+
+type banJSON struct {
+	ID           string     `json:"id"`
+	OpenplanetID string     `json:"openplanet_id"`
+	DisplayName  string     `json:"display_name"`
+	Reason       *string    `json:"reason"`
+	BannedAt     *time.Time `json:"banned_at"`
+	ExpiresAt    *time.Time `json:"expires_at"`
+	BannedBy     *string    `json:"banned_by"`
+	LiftedAt     *time.Time `json:"lifted_at,omitempty"`
+	LiftedBy     *string    `json:"lifted_by,omitempty"`
+	LiftReason   *string    `json:"lift_reason,omitempty"`
+}
+
+type bansResponse struct {
+	Bans []banJSON `json:"bans"`
+}
+
+// Bans handles /api/admin/bans: GET lists bans in force, or with
+// ?openplanet_id=X that player's full ban history. POST bans a player,
+// permanently or for expires_in_hours, replacing any ban in force. DELETE
+// ?openplanet_id=X&reason=Y lifts the ban in force. Lifted and expired bans
+// stay in the history.
+func Bans(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		auth.RequireScope(auth.ScopePlayersBan, handleListBans)(w, r)
+	case http.MethodPost:
+		auth.RequireScope(auth.ScopePlayersBan, handleBan)(w, r)
+	case http.MethodDelete:
+		auth.RequireScope(auth.ScopePlayersBan, handleUnban)(w, r)
+	default:
+		response.Error(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+func handleListBans(w http.ResponseWriter, r *http.Request, _ *auth.Principal) {
+	database, err := db.GetDB()
+	if err != nil {
+		slog.Error("database connection error", "error", err)
+		response.Error(w, http.StatusServiceUnavailable, "service unavailable")
+		return
+	}
+
+	var rows []db.BanRow
+	if openplanetID := r.URL.Query().Get("openplanet_id"); openplanetID != "" {
+		playerID, ok := resolvePlayer(w, database, openplanetID)
+		if !ok {
+			return
+		}
+		rows, err = db.ListPlayerBans(database, playerID)
+	} else {
+		rows, err = db.ListBans(database)
+	}
+	if err != nil {
+		slog.Error("list bans error", "error", err)
+		response.Error(w, http.StatusServiceUnavailable, "service unavailable")
+		return
+	}
+
+	bans := make([]banJSON, len(rows))
+	for i, b := range rows {
+		bans[i] = banJSON{
+			ID:           b.ID.String(),
+			OpenplanetID: b.OpenplanetID,
+			DisplayName:  b.DisplayName,
+			Reason:       b.Reason,
+			BannedAt:     b.BannedAt,
+			ExpiresAt:    b.ExpiresAt,
+			BannedBy:     b.BannedBy,
+			LiftedAt:     b.LiftedAt,
+			LiftedBy:     b.LiftedBy,
+			LiftReason:   b.LiftReason,
+		}
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	response.JSON(w, http.StatusOK, bansResponse{Bans: bans})
+}
+
+func handleBan(w http.ResponseWriter, r *http.Request, principal *auth.Principal) {
+	r.Body = http.MaxBytesReader(w, r.Body, 4*1024) // 4KB
+	var req banRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if err := validate.Struct(req); err != nil {
+		response.Error(w, http.StatusBadRequest, validate.FormatError(err))
+		return
+	}
+
+	database, err := db.GetDB()
+	if err != nil {
+		slog.Error("database connection error", "error", err)
+		response.Error(w, http.StatusServiceUnavailable, "service unavailable")
+		return
+	}
+
+	playerID, ok := resolvePlayer(w, database, req.OpenplanetID)
+	if !ok {
+		return
+	}
+
+	input := db.BanInput{
+		PlayerID: playerID,
+		BannedBy: principal.PlayerID,
+	}
+	if req.Reason != "" {
+		input.Reason = &req.Reason
+	}
+	if req.ExpiresInHours > 0 {
+		t := time.Now().Add(time.Duration(req.ExpiresInHours) * time.Hour)
+		input.ExpiresAt = &t
+	}
+	if err := db.BanPlayer(database, input); err != nil {
+		slog.Error("ban player error", "error", err)
+		response.Error(w, http.StatusServiceUnavailable, "service unavailable")
+		return
+	}
+
+	audit.Record(r, audit.Entry{
+		Actor:      principal.PlayerID,
+		Action:     audit.ActionPlayerBan,
+		TargetType: audit.TargetPlayer,
+		TargetID:   playerID.String(),
+		Details: map[string]any{
+			"openplanet_id": req.OpenplanetID,
+			"reason":        input.Reason,
+			"expires_at":    input.ExpiresAt,
+		},
+	})
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func handleUnban(w http.ResponseWriter, r *http.Request, principal *auth.Principal) {
+	q := r.URL.Query()
+	var reason *string
+	if s := q.Get("reason"); s != "" {
+		if len(s) > 500 {
+			response.Error(w, http.StatusBadRequest, "reason must be at most 500 characters")
+			return
+		}
+		reason = &s
+	}
+
+	database, err := db.GetDB()
+	if err != nil {
+		slog.Error("database connection error", "error", err)
+		response.Error(w, http.StatusServiceUnavailable, "service unavailable")
+		return
+	}
+
+	playerID, ok := resolvePlayer(w, database, q.Get("openplanet_id"))
+	if !ok {
+		return
+	}
+
+	unbanned, err := db.UnbanPlayer(database, playerID, principal.PlayerID, reason)
+	if err != nil {
+		slog.Error("unban player error", "error", err)
+		response.Error(w, http.StatusServiceUnavailable, "service unavailable")
+		return
+	}
+	if !unbanned {
+		response.Error(w, http.StatusNotFound, "player is not banned")
+		return
+	}
+
+	audit.Record(r, audit.Entry{
+		Actor:      principal.PlayerID,
+		Action:     audit.ActionPlayerUnban,
+		TargetType: audit.TargetPlayer,
+		TargetID:   playerID.String(),
+		Details: map[string]any{
+			"openplanet_id": q.Get("openplanet_id"),
+			"reason":        reason,
+		},
+	})
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,207 @@
+package handler
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"rmpc-server/api/_pkg/audit"
+	"rmpc-server/api/_pkg/auth"
+	"rmpc-server/api/_pkg/db"
+	"rmpc-server/api/_pkg/response"
+	"rmpc-server/api/_pkg/validate"
+)
+
+type createKeyRequest struct {
+	Name          string   `json:"name"            validate:"required,max=100"`
+	Scopes        []string `json:"scopes"          validate:"required"`
+	ExpiresInDays int      `json:"expires_in_days" validate:"omitempty,gte=1,lte=365"`
+}
+
+type createKeyResponse struct {
+	ID        string     `json:"id"`
+	Key       string     `json:"key"`
+	Scopes    []string   `json:"scopes"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+type apiKeyJSON struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Scopes     []string   `json:"scopes"`
+	CreatedAt  *time.Time `json:"created_at"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+}
+
+type listKeysResponse struct {
+	Keys []apiKeyJSON `json:"keys"`
+}
+
+// Keys handles /api/admin/keys for moderators and admins signed in with a
+// regular session: GET lists their API keys, POST issues a new one (the
+// plaintext key is only ever returned here), DELETE ?id=X revokes one.
+// Roles themselves are assigned directly in the database.
+func Keys(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		auth.RequireAuth(handleListKeys)(w, r)
+	case http.MethodPost:
+		auth.RequireAuth(handleCreateKey)(w, r)
+	case http.MethodDelete:
+		auth.RequireAuth(handleRevokeKey)(w, r)
+	default:
+		response.Error(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+func handleCreateKey(w http.ResponseWriter, r *http.Request, playerID uuid.UUID) {
+	r.Body = http.MaxBytesReader(w, r.Body, 4*1024) // 4KB
+	var req createKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if err := validate.Struct(req); err != nil {
+		response.Error(w, http.StatusBadRequest, validate.FormatError(err))
+		return
+	}
+
+	database, err := db.GetDB()
+	if err != nil {
+		slog.Error("database connection error", "error", err)
+		response.Error(w, http.StatusServiceUnavailable, "service unavailable")
+		return
+	}
+
+	role, err := db.GetPlayerRole(database, playerID)
+	if err != nil {
+		slog.Error("player role lookup error", "error", err)
+		response.Error(w, http.StatusServiceUnavailable, "service unavailable")
+		return
+	}
+
+	scopes, err := auth.ParseScopes(role.String(), strings.Join(req.Scopes, " "))
+	if err != nil {
+		response.Error(w, http.StatusForbidden, err.Error())
+		return
+	}
+
+	plaintext, hash, err := auth.GenerateAPIKey()
+	if err != nil {
+		slog.Error("api key generation error", "error", err)
+		response.Error(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	var expiresAt *time.Time
+	if req.ExpiresInDays > 0 {
+		t := time.Now().AddDate(0, 0, req.ExpiresInDays)
+		expiresAt = &t
+	}
+
+	id, createdAt, err := db.CreateAPIKey(database, db.APIKeyInput{
+		PlayerID:  playerID,
+		Name:      req.Name,
+		KeyHash:   hash,
+		Scopes:    strings.Join(scopes, " "),
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		slog.Error("create api key error", "error", err)
+		response.Error(w, http.StatusServiceUnavailable, "service unavailable")
+		return
+	}
+
+	audit.Record(r, audit.Entry{
+		Actor:      playerID,
+		Action:     audit.ActionAPIKeyCreate,
+		TargetType: audit.TargetAPIKey,
+		TargetID:   id.String(),
+		Details: map[string]any{
+			"name":       req.Name,
+			"scopes":     scopes,
+			"expires_at": expiresAt,
+		},
+	})
+
+	w.Header().Set("Cache-Control", "no-store")
+	response.JSON(w, http.StatusCreated, createKeyResponse{
+		ID:        id.String(),
+		Key:       plaintext,
+		Scopes:    scopes,
+		CreatedAt: createdAt,
+		ExpiresAt: expiresAt,
+	})
+}
+
+func handleListKeys(w http.ResponseWriter, r *http.Request, playerID uuid.UUID) {
+	database, err := db.GetDB()
+	if err != nil {
+		slog.Error("database connection error", "error", err)
+		response.Error(w, http.StatusServiceUnavailable, "service unavailable")
+		return
+	}
+
+	keys, err := db.ListAPIKeys(database, playerID)
+	if err != nil {
+		slog.Error("list api keys error", "error", err)
+		response.Error(w, http.StatusServiceUnavailable, "service unavailable")
+		return
+	}
+
+	out := make([]apiKeyJSON, len(keys))
+	for i, k := range keys {
+		out[i] = apiKeyJSON{
+			ID:         k.ID.String(),
+			Name:       k.Name,
+			Scopes:     strings.Fields(k.Scopes),
+			CreatedAt:  k.CreatedAt,
+			ExpiresAt:  k.ExpiresAt,
+			LastUsedAt: k.LastUsedAt,
+		}
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	response.JSON(w, http.StatusOK, listKeysResponse{Keys: out})
+}
+
+func handleRevokeKey(w http.ResponseWriter, r *http.Request, playerID uuid.UUID) {
+	keyID, err := uuid.Parse(r.URL.Query().Get("id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid key id")
+		return
+	}
+
+	database, err := db.GetDB()
+	if err != nil {
+		slog.Error("database connection error", "error", err)
+		response.Error(w, http.StatusServiceUnavailable, "service unavailable")
+		return
+	}
+
+	revoked, err := db.RevokeAPIKey(database, playerID, keyID)
+	if err != nil {
+		slog.Error("revoke api key error", "error", err)
+		response.Error(w, http.StatusServiceUnavailable, "service unavailable")
+		return
+	}
+	if !revoked {
+		response.Error(w, http.StatusNotFound, "not found")
+		return
+	}
+
+	audit.Record(r, audit.Entry{
+		Actor:      playerID,
+		Action:     audit.ActionAPIKeyRevoke,
+		TargetType: audit.TargetAPIKey,
+		TargetID:   keyID.String(),
+	})
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,106 @@
+package handler
+
+import (
+	"database/sql"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+
+	"rmpc-server/api/_pkg/auth"
+	"rmpc-server/api/_pkg/db"
+	"rmpc-server/api/_pkg/response"
+)
+
+type adminPlayerJSON struct {
+	ID           string     `json:"id"`
+	OpenplanetID string     `json:"openplanet_id"`
+	DisplayName  string     `json:"display_name"`
+	Role         string     `json:"role"`
+	CreatedAt    *time.Time `json:"created_at"`
+	Banned       bool       `json:"banned"`
+	BanReason    *string    `json:"ban_reason,omitempty"`
+	BannedAt     *time.Time `json:"banned_at,omitempty"`
+	BanExpiresAt *time.Time `json:"ban_expires_at,omitempty"`
+}
+
+type adminPlayersResponse struct {
+	Players []adminPlayerJSON `json:"players"`
+}
+
+const playerLookupLimit = 20
+
+// Players handles GET /api/admin/players?openplanet_id=X or ?name=X.
+// Name lookups are case-insensitive substring matches.
+func Players(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		response.Error(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	auth.RequireScope(auth.ScopePlayersRead, handlePlayerLookup)(w, r)
+}
+
+func handlePlayerLookup(w http.ResponseWriter, r *http.Request, _ *auth.Principal) {
+	q := r.URL.Query()
+	openplanetID := q.Get("openplanet_id")
+	name := q.Get("name")
+	if (openplanetID == "") == (name == "") {
+		response.Error(w, http.StatusBadRequest, "exactly one of openplanet_id or name is required")
+		return
+	}
+
+	database, err := db.GetDB()
+	if err != nil {
+		slog.Error("database connection error", "error", err)
+		response.Error(w, http.StatusServiceUnavailable, "service unavailable")
+		return
+	}
+
+	rows, err := db.FindPlayers(database, openplanetID, name, playerLookupLimit)
+	if err != nil {
+		slog.Error("player lookup error", "error", err)
+		response.Error(w, http.StatusServiceUnavailable, "service unavailable")
+		return
+	}
+
+	players := make([]adminPlayerJSON, len(rows))
+	for i, p := range rows {
+		players[i] = adminPlayerJSON{
+			ID:           p.ID.String(),
+			OpenplanetID: p.OpenplanetID,
+			DisplayName:  p.DisplayName,
+			Role:         p.Role.String(),
+			CreatedAt:    p.CreatedAt,
+			Banned:       p.BannedAt != nil,
+			BanReason:    p.BanReason,
+			BannedAt:     p.BannedAt,
+			BanExpiresAt: p.BanExpiresAt,
+		}
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	response.JSON(w, http.StatusOK, adminPlayersResponse{Players: players})
+}
+
+// resolvePlayer maps an Openplanet ID to a player ID, writing the error
+// response itself when that fails.
+func resolvePlayer(w http.ResponseWriter, database *sql.DB, openplanetID string) (uuid.UUID, bool) {
+	if openplanetID == "" {
+		response.Error(w, http.StatusBadRequest, "openplanet_id is required")
+		return uuid.Nil, false
+	}
+
+	playerID, err := db.FindPlayerID(database, openplanetID)
+	if err != nil {
+		slog.Error("player lookup error", "error", err)
+		response.Error(w, http.StatusServiceUnavailable, "service unavailable")
+		return uuid.Nil, false
+	}
+	if playerID == uuid.Nil {
+		response.Error(w, http.StatusNotFound, "player not found")
+		return uuid.Nil, false
+	}
+	return playerID, true
+}
@@ -0,0 +1,153 @@
+package handler
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+
+	"rmpc-server/api/_pkg/audit"
+	"rmpc-server/api/_pkg/auth"
+	"rmpc-server/api/_pkg/db"
+	"rmpc-server/api/_pkg/response"
+	"rmpc-server/api/_pkg/validate"
+)
+
+type reviewRequest struct {
+	ScoreID  string `json:"score_id" validate:"required"`
+	Decision string `json:"decision" validate:"required,oneof=accept reject"`
+	Note     string `json:"note"     validate:"omitempty,max=500"`
+}
+
+type reviewJSON struct {
+	ScoreID       string     `json:"score_id"`
+	OpenplanetID  string     `json:"openplanet_id"`
+	DisplayName   string     `json:"display_name"`
+	GameMode      string     `json:"game_mode"`
+	Score         int32      `json:"score"`
+	MapsCompleted int32      `json:"maps_completed"`
+	MapsSkipped   int32      `json:"maps_skipped"`
+	DurationMs    int32      `json:"duration_ms"`
+	RunID         *string    `json:"run_id"`
+	FlagReason    *string    `json:"flag_reason"`
+	CreatedAt     *time.Time `json:"created_at"`
+	Proofs        int64      `json:"proofs"` // see GET /api/scores/proofs?score_id=X
+}
+
+type reviewsResponse struct {
+	Reviews []reviewJSON `json:"reviews"`
+}
+
+const reviewQueueLimit = 100
+
+// Reviews handles /api/admin/reviews, the queue of scores held back by the
+// plausibility checks or waiting for their proof to be checked. GET lists the
+// oldest pending scores; POST accepts (publishes) or rejects one.
+func Reviews(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		auth.RequireScope(auth.ScopeScoresModerate, handleListReviews)(w, r)
+	case http.MethodPost:
+		auth.RequireScope(auth.ScopeScoresModerate, handleReview)(w, r)
+	default:
+		response.Error(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+func handleListReviews(w http.ResponseWriter, r *http.Request, _ *auth.Principal) {
+	database, err := db.GetDB()
+	if err != nil {
+		slog.Error("database connection error", "error", err)
+		response.Error(w, http.StatusServiceUnavailable, "service unavailable")
+		return
+	}
+
+	rows, err := db.ListPendingReviews(database, reviewQueueLimit)
+	if err != nil {
+		slog.Error("list pending reviews error", "error", err)
+		response.Error(w, http.StatusServiceUnavailable, "service unavailable")
+		return
+	}
+
+	reviews := make([]reviewJSON, len(rows))
+	for i, s := range rows {
+		reviews[i] = reviewJSON{
+			ScoreID:       s.ID.String(),
+			OpenplanetID:  s.OpenplanetID,
+			DisplayName:   s.DisplayName,
+			GameMode:      s.GameMode.String(),
+			Score:         s.Score,
+			MapsCompleted: s.MapsCompleted,
+			MapsSkipped:   s.MapsSkipped,
+			DurationMs:    s.DurationMs,
+			FlagReason:    s.FlagReason,
+			CreatedAt:     s.CreatedAt,
+			Proofs:        s.Proofs,
+		}
+		if s.RunID != nil {
+			runID := s.RunID.String()
+			reviews[i].RunID = &runID
+		}
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	response.JSON(w, http.StatusOK, reviewsResponse{Reviews: reviews})
+}
+
+func handleReview(w http.ResponseWriter, r *http.Request, principal *auth.Principal) {
+	r.Body = http.MaxBytesReader(w, r.Body, 4*1024) // 4KB
+	var req reviewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if err := validate.Struct(req); err != nil {
+		response.Error(w, http.StatusBadRequest, validate.FormatError(err))
+		return
+	}
+	scoreID, err := uuid.Parse(req.ScoreID)
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid score id")
+		return
+	}
+
+	database, err := db.GetDB()
+	if err != nil {
+		slog.Error("database connection error", "error", err)
+		response.Error(w, http.StatusServiceUnavailable, "service unavailable")
+		return
+	}
+
+	var note *string
+	if req.Note != "" {
+		note = &req.Note
+	}
+	accept := req.Decision == "accept"
+
+	reviewed, err := db.ReviewScore(database, scoreID, principal.PlayerID, accept, note)
+	if err != nil {
+		slog.Error("review score error", "error", err)
+		response.Error(w, http.StatusServiceUnavailable, "service unavailable")
+		return
+	}
+	if !reviewed {
+		response.Error(w, http.StatusNotFound, "score not found or not pending review")
+		return
+	}
+
+	action := audit.ActionScoreReject
+	if accept {
+		action = audit.ActionScoreAccept
+	}
+	audit.Record(r, audit.Entry{
+		Actor:      principal.PlayerID,
+		Action:     action,
+		TargetType: audit.TargetScore,
+		TargetID:   scoreID.String(),
+		Details:    map[string]any{"note": note},
+	})
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/google/uuid"
+
+	"rmpc-server/api/_pkg/audit"
+	"rmpc-server/api/_pkg/auth"
+	"rmpc-server/api/_pkg/db"
+	"rmpc-server/api/_pkg/response"
+	"rmpc-server/api/_pkg/validate"
+)
+
+type scoreModerationRequest struct {
+	ScoreID string `json:"score_id" validate:"required"`
+	Action  string `json:"action"   validate:"required,oneof=remove restore"`
+	Reason  string `json:"reason"   validate:"omitempty,max=500"`
+}
+
+// Scores handles POST /api/admin/scores: soft-deletes ("remove") or restores
+// a single score. Removed scores stay in the database but are hidden from the
+// leaderboard, hall of fame, world records, player pages and activity.
+func Scores(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		response.Error(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	auth.RequireScope(auth.ScopeScoresModerate, handleScoreModeration)(w, r)
+}
+
+func handleScoreModeration(w http.ResponseWriter, r *http.Request, principal *auth.Principal) {
+	r.Body = http.MaxBytesReader(w, r.Body, 4*1024) // 4KB
+	var req scoreModerationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if err := validate.Struct(req); err != nil {
+		response.Error(w, http.StatusBadRequest, validate.FormatError(err))
+		return
+	}
+	scoreID, err := uuid.Parse(req.ScoreID)
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid score id")
+		return
+	}
+
+	database, err := db.GetDB()
+	if err != nil {
+		slog.Error("database connection error", "error", err)
+		response.Error(w, http.StatusServiceUnavailable, "service unavailable")
+		return
+	}
+
+	var reason *string
+	if req.Reason != "" {
+		reason = &req.Reason
+	}
+
+	var changed bool
+	action := audit.ActionScoreRemove
+	if req.Action == "remove" {
+		changed, err = db.RemoveScore(database, scoreID, principal.PlayerID, reason)
+	} else {
+		action = audit.ActionScoreRestore
+		changed, err = db.RestoreScore(database, scoreID)
+	}
+	if err != nil {
+		slog.Error("score moderation error", "error", err, "action", req.Action)
+		response.Error(w, http.StatusServiceUnavailable, "service unavailable")
+		return
+	}
+	if !changed {
+		response.Error(w, http.StatusNotFound, "score not found")
+		return
+	}
+
+	audit.Record(r, audit.Entry{
+		Actor:      principal.PlayerID,
+		Action:     action,
+		TargetType: audit.TargetScore,
+		TargetID:   scoreID.String(),
+		Details:    map[string]any{"reason": reason},
+	})
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"log/slog"
+	"net/http"
+
+	"rmpc-server/api/_pkg/audit"
+	"rmpc-server/api/_pkg/auth"
+	"rmpc-server/api/_pkg/db"
+	"rmpc-server/api/_pkg/response"
+)
+
+type revokedSessionsResponse struct {
+	Revoked int64 `json:"revoked"`
+}
+
+// Sessions handles DELETE /api/admin/sessions?openplanet_id=X: signs the
+// player out everywhere.
+func Sessions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		response.Error(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	auth.RequireScope(auth.ScopeSessionsRevoke, handleRevokePlayerSessions)(w, r)
+}
+
+func handleRevokePlayerSessions(w http.ResponseWriter, r *http.Request, principal *auth.Principal) {
+	database, err := db.GetDB()
+	if err != nil {
+		slog.Error("database connection error", "error", err)
+		response.Error(w, http.StatusServiceUnavailable, "service unavailable")
+		return
+	}
+
+	openplanetID := r.URL.Query().Get("openplanet_id")
+	playerID, ok := resolvePlayer(w, database, openplanetID)
+	if !ok {
+		return
+	}
+
+	n, err := db.DeleteSessions(database, playerID)
+	if err != nil {
+		slog.Error("delete sessions error", "error", err)
+		response.Error(w, http.StatusServiceUnavailable, "service unavailable")
+		return
+	}
+
+	audit.Record(r, audit.Entry{
+		Actor:      principal.PlayerID,
+		Action:     audit.ActionSessionsRevoke,
+		TargetType: audit.TargetPlayer,
+		TargetID:   playerID.String(),
+		Details: map[string]any{
+			"openplanet_id": openplanetID,
+			"revoked":       n,
+		},
+	})
+
+	response.JSON(w, http.StatusOK, revokedSessionsResponse{Revoked: n})
+}
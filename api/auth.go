@@ -2,10 +2,12 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"log/slog"
 	"net/http"
 	"time"
 
+	"rmpc-server/api/_pkg/audit"
 	"rmpc-server/api/_pkg/auth"
 	"rmpc-server/api/_pkg/config"
 	"rmpc-server/api/_pkg/db"
@@ -16,19 +18,29 @@ import (
 
 type authRequest struct {
 	OpenplanetToken string `json:"openplanet_token" validate:"required"`
+	DeviceLabel     string `json:"device_label"     validate:"omitempty,max=100"`
 }
 
 type authResponse struct {
+	SessionID    string    `json:"session_id"`
 	SessionToken string    `json:"session_token"`
 	ExpiresAt    time.Time `json:"expires_at"`
 }
 
+// Auth handles POST /api/auth (exchange an Openplanet token for a session)
+// and DELETE /api/auth (revoke the presented session token).
 func Auth(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
+	switch r.Method {
+	case http.MethodPost:
+		handleLogin(w, r)
+	case http.MethodDelete:
+		handleLogout(w, r)
+	default:
 		response.Error(w, http.StatusMethodNotAllowed, "method not allowed")
-		return
 	}
+}
 
+func handleLogin(w http.ResponseWriter, r *http.Request) {
 	// Rate limit by IP
 	ip := auth.GetClientIP(r)
 	if !ratelimit.AuthLimiter().Allow(ip) {
@@ -48,11 +60,21 @@ func Auth(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Validate with Openplanet
-	user, err := auth.ValidateOpenplanetToken(req.OpenplanetToken)
+	// Validate with the identity provider (Openplanet in production)
+	provider, err := auth.Provider()
 	if err != nil {
-		slog.Error("openplanet validation error", "error", err)
-		response.Error(w, http.StatusUnauthorized, "invalid openplanet token")
+		slog.Error("identity provider config error", "error", err)
+		response.Error(w, http.StatusServiceUnavailable, "service unavailable")
+		return
+	}
+	user, err := provider.Validate(req.OpenplanetToken)
+	if err != nil {
+		slog.Error("identity validation error", "error", err)
+		if errors.Is(err, auth.ErrProviderUnavailable) {
+			response.Error(w, http.StatusServiceUnavailable, "identity provider unavailable")
+		} else {
+			response.Error(w, http.StatusUnauthorized, "invalid openplanet token")
+		}
 		return
 	}
 
@@ -81,17 +103,76 @@ func Auth(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Determine expiry
-	expiresAt := time.Now().Add(config.Env.SessionTokenExpiry)
+	now := time.Now()
+	expiresAt := auth.SessionExpiry(now, now)
+
+	var deviceLabel *string
+	if req.DeviceLabel != "" {
+		deviceLabel = &req.DeviceLabel
+	}
 
 	// Store session
-	if err := db.CreateSession(database, playerID, hash, expiresAt); err != nil {
+	sessionID, err := db.CreateSession(database, db.SessionInput{
+		PlayerID:    playerID,
+		TokenHash:   hash,
+		DeviceLabel: deviceLabel,
+		ExpiresAt:   expiresAt,
+	}, config.Env.MaxSessionsPerPlayer)
+	if err != nil {
 		slog.Error("create session error", "error", err)
 		response.Error(w, http.StatusServiceUnavailable, "service unavailable")
 		return
 	}
 
+	audit.Record(r, audit.Entry{
+		Actor:      playerID,
+		Action:     audit.ActionSessionCreate,
+		TargetType: audit.TargetSession,
+		TargetID:   sessionID.String(),
+		Details: map[string]any{
+			"device_label": deviceLabel,
+			"expires_at":   expiresAt,
+		},
+	})
+
 	response.JSON(w, http.StatusOK, authResponse{
+		SessionID:    sessionID.String(),
 		SessionToken: plaintext,
 		ExpiresAt:    expiresAt,
 	})
 }
+
+func handleLogout(w http.ResponseWriter, r *http.Request) {
+	token, err := auth.BearerToken(r)
+	if err != nil {
+		response.Error(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	database, err := db.GetDB()
+	if err != nil {
+		slog.Error("database connection error", "error", err)
+		response.Error(w, http.StatusServiceUnavailable, "service unavailable")
+		return
+	}
+
+	session, err := db.DeleteSessionByTokenHash(database, auth.HashToken(token))
+	if err != nil {
+		slog.Error("delete session error", "error", err)
+		response.Error(w, http.StatusServiceUnavailable, "service unavailable")
+		return
+	}
+	if session == nil {
+		response.Error(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	audit.Record(r, audit.Entry{
+		Actor:      session.PlayerID,
+		Action:     audit.ActionSessionDelete,
+		TargetType: audit.TargetSession,
+		TargetID:   session.ID.String(),
+	})
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"rmpc-server/api/_pkg/audit"
+	"rmpc-server/api/_pkg/auth"
+	"rmpc-server/api/_pkg/db"
+	"rmpc-server/api/_pkg/response"
+)
+
+type refreshResponse struct {
+	SessionID    string    `json:"session_id"`
+	SessionToken string    `json:"session_token"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+// Refresh handles POST /api/auth/refresh: swaps the presented session token
+// for a new one. The old token stops working immediately; the session keeps
+// its creation time, so rotation can't extend it past SESSION_MAX_LIFETIME.
+func Refresh(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		response.Error(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	auth.RequireSession(handleRefresh)(w, r)
+}
+
+func handleRefresh(w http.ResponseWriter, r *http.Request, session *db.Session) {
+	// RequireSession already validated the header
+	token, _ := auth.BearerToken(r)
+
+	plaintext, hash, err := auth.GenerateSessionToken()
+	if err != nil {
+		slog.Error("token generation error", "error", err)
+		response.Error(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	database, err := db.GetDB()
+	if err != nil {
+		slog.Error("database connection error", "error", err)
+		response.Error(w, http.StatusServiceUnavailable, "service unavailable")
+		return
+	}
+
+	expiresAt := auth.SessionExpiry(session.CreatedAt, time.Now())
+	rotated, err := db.RotateSession(database, session.ID, auth.HashToken(token), hash, expiresAt)
+	if err != nil {
+		slog.Error("rotate session error", "error", err)
+		response.Error(w, http.StatusServiceUnavailable, "service unavailable")
+		return
+	}
+	if !rotated {
+		// Lost a race with another refresh or a revocation
+		response.Error(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	audit.Record(r, audit.Entry{
+		Actor:      session.PlayerID,
+		Action:     audit.ActionSessionRotate,
+		TargetType: audit.TargetSession,
+		TargetID:   session.ID.String(),
+		Details:    map[string]any{"expires_at": expiresAt},
+	})
+
+	response.JSON(w, http.StatusOK, refreshResponse{
+		SessionID:    session.ID.String(),
+		SessionToken: plaintext,
+		ExpiresAt:    expiresAt,
+	})
+}
@@ -0,0 +1,140 @@
+package handler
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+
+	"rmpc-server/api/_pkg/audit"
+	"rmpc-server/api/_pkg/auth"
+	"rmpc-server/api/_pkg/db"
+	"rmpc-server/api/_pkg/response"
+)
+
+type sessionJSON struct {
+	ID          string    `json:"id"`
+	DeviceLabel string    `json:"device_label,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	LastUsedAt  time.Time `json:"last_used_at"`
+	ExpiresAt   time.Time `json:"expires_at"`
+	Current     bool      `json:"current"`
+}
+
+type sessionsResponse struct {
+	Sessions []sessionJSON `json:"sessions"`
+}
+
+type revokeSessionsResponse struct {
+	Revoked int64 `json:"revoked"`
+}
+
+// Sessions handles GET /api/auth/sessions (list the caller's sessions) and
+// DELETE /api/auth/sessions?id=X or ?all=true (revoke one or all of them).
+func Sessions(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		auth.RequireSession(handleListSessions)(w, r)
+	case http.MethodDelete:
+		auth.RequireSession(handleRevokeSessions)(w, r)
+	default:
+		response.Error(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+func handleListSessions(w http.ResponseWriter, r *http.Request, session *db.Session) {
+	database, err := db.GetDB()
+	if err != nil {
+		slog.Error("database connection error", "error", err)
+		response.Error(w, http.StatusServiceUnavailable, "service unavailable")
+		return
+	}
+
+	sessions, err := db.ListSessions(database, session.PlayerID)
+	if err != nil {
+		slog.Error("list sessions error", "error", err)
+		response.Error(w, http.StatusServiceUnavailable, "service unavailable")
+		return
+	}
+
+	out := make([]sessionJSON, len(sessions))
+	for i, s := range sessions {
+		deviceLabel := ""
+		if s.DeviceLabel != nil {
+			deviceLabel = *s.DeviceLabel
+		}
+		out[i] = sessionJSON{
+			ID:          s.ID.String(),
+			DeviceLabel: deviceLabel,
+			CreatedAt:   s.CreatedAt,
+			LastUsedAt:  s.LastUsedAt,
+			ExpiresAt:   s.ExpiresAt,
+			Current:     s.ID == session.ID,
+		}
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	response.JSON(w, http.StatusOK, sessionsResponse{Sessions: out})
+}
+
+func handleRevokeSessions(w http.ResponseWriter, r *http.Request, session *db.Session) {
+	q := r.URL.Query()
+	all := q.Get("all") == "true"
+	idParam := q.Get("id")
+	if all == (idParam != "") {
+		response.Error(w, http.StatusBadRequest, "exactly one of id or all=true is required")
+		return
+	}
+
+	database, err := db.GetDB()
+	if err != nil {
+		slog.Error("database connection error", "error", err)
+		response.Error(w, http.StatusServiceUnavailable, "service unavailable")
+		return
+	}
+
+	if all {
+		n, err := db.DeleteSessions(database, session.PlayerID)
+		if err != nil {
+			slog.Error("delete sessions error", "error", err)
+			response.Error(w, http.StatusServiceUnavailable, "service unavailable")
+			return
+		}
+		audit.Record(r, audit.Entry{
+			Actor:      session.PlayerID,
+			Action:     audit.ActionSessionRevoke,
+			TargetType: audit.TargetPlayer,
+			TargetID:   session.PlayerID.String(),
+			Details:    map[string]any{"revoked": n},
+		})
+		response.JSON(w, http.StatusOK, revokeSessionsResponse{Revoked: n})
+		return
+	}
+
+	sessionID, err := uuid.Parse(idParam)
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid session id")
+		return
+	}
+
+	deleted, err := db.DeleteSession(database, session.PlayerID, sessionID)
+	if err != nil {
+		slog.Error("delete session error", "error", err)
+		response.Error(w, http.StatusServiceUnavailable, "service unavailable")
+		return
+	}
+	if !deleted {
+		response.Error(w, http.StatusNotFound, "not found")
+		return
+	}
+
+	audit.Record(r, audit.Entry{
+		Actor:      session.PlayerID,
+		Action:     audit.ActionSessionRevoke,
+		TargetType: audit.TargetSession,
+		TargetID:   sessionID.String(),
+	})
+
+	response.JSON(w, http.StatusOK, revokeSessionsResponse{Revoked: 1})
+}
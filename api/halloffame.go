@@ -33,6 +33,7 @@ type hofEntryJSON struct {
 
 type hofResponse struct {
 	GameMode string         `json:"game_mode"`
+	Ruleset  int16          `json:"ruleset"`
 	Entries  []hofEntryJSON `json:"entries"`
 }
 
@@ -51,6 +52,10 @@ func HallOfFame(w http.ResponseWriter, r *http.Request) {
 		response.Error(w, http.StatusBadRequest, validate.FormatError(err))
 		return
 	}
+	ruleset, ok := rulesetParam(w, r)
+	if !ok {
+		return
+	}
 
 	now := time.Now().UTC()
 	currentMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
@@ -62,7 +67,7 @@ func HallOfFame(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	rows, err := db.GetHallOfFame(database, query.GameMode, hofEarliestMonth, currentMonth)
+	rows, err := db.GetHallOfFame(database, query.GameMode, ruleset, hofEarliestMonth, currentMonth)
 	if err != nil {
 		slog.Error("hall of fame query error", "error", err)
 		response.Error(w, http.StatusServiceUnavailable, "service unavailable")
@@ -90,6 +95,7 @@ func HallOfFame(w http.ResponseWriter, r *http.Request) {
 	response.SetCache(w, config.Env.HallOfFameCacheTTL)
 	response.JSON(w, http.StatusOK, hofResponse{
 		GameMode: query.GameMode,
+		Ruleset:  ruleset,
 		Entries:  entries,
 	})
 }
@@ -1,28 +1,108 @@
 package handler
 
 import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/google/uuid"
+
+	"rmpc-server/api/_pkg/auth"
 	"rmpc-server/api/_pkg/config"
 	"rmpc-server/api/_pkg/db"
+	"rmpc-server/api/_pkg/pagination"
+	"rmpc-server/api/_pkg/period"
 	"rmpc-server/api/_pkg/playerlink"
+	"rmpc-server/api/_pkg/preset"
+	"rmpc-server/api/_pkg/ranking"
 	"rmpc-server/api/_pkg/response"
 	"rmpc-server/api/_pkg/validate"
 )
 
 type leaderboardQuery struct {
-	GameMode string `json:"game_mode" validate:"omitempty,oneof=author gold"`
+	GameMode string `json:"game_mode" validate:"omitempty,oneof=author gold custom"`
 	Month    string `json:"month"     validate:"omitempty"`
+	Period   string `json:"period"    validate:"omitempty,oneof=day week month season all"`
+	Preset   string `json:"preset"    validate:"omitempty"`
+	Around   string `json:"around"    validate:"omitempty,oneof=me"`
+	ID       string `json:"id"        validate:"omitempty"`
+	Sig      string `json:"t"         validate:"omitempty"`
 }
 
 type leaderboardResponse struct {
-	Scores   []leaderboardEntryJSON `json:"scores"`
-	Month    string                 `json:"month,omitempty"`
-	GameMode string                 `json:"game_mode"`
+	Scores     []leaderboardEntryJSON `json:"scores"`
+	Month      string                 `json:"month,omitempty"`
+	Period     periodJSON             `json:"period"`
+	GameMode   string                 `json:"game_mode"`
+	Ruleset    int16                  `json:"ruleset"`
+	Preset     *presetJSON            `json:"preset,omitempty"`
+	RankBy     string                 `json:"rank_by"`
+	MinScore   int32                  `json:"min_score,omitempty"` // for rank_by=fewest_skips
+	Total      int64                  `json:"total"`               // ranked players on the whole leaderboard
+	NextCursor string                 `json:"next_cursor,omitempty"`
+	Around     *aroundJSON            `json:"around,omitempty"`
+}
+
+// aroundJSON is the player a slice of the leaderboard is centered on. Rank is
+// null when the player has no score on the leaderboard.
+type aroundJSON struct {
+	Rank *int `json:"rank"`
+}
+
+// leaderboardCursor is the opaque ?cursor of the next page: the values the
+// last entry of the previous one is listed by.
+type leaderboardCursor struct {
+	Score         int32     `json:"s"`
+	MapsCompleted int32     `json:"m"`
+	MapsSkipped   int32     `json:"k"`
+	DurationMs    int32     `json:"d"`
+	Runs          int32     `json:"r"`
+	CreatedAt     time.Time `json:"t"`
+	PlayerID      uuid.UUID `json:"p"`
 }
 
+func encodeCursor(e db.LeaderboardEntry) string {
+	return pagination.Encode(leaderboardCursor(e.Cursor()))
+}
+
+type presetJSON struct {
+	Hash     string          `json:"hash"`
+	Settings json.RawMessage `json:"settings"`
+	Players  int64           `json:"players,omitempty"`
+	Runs     int64           `json:"runs,omitempty"`
+}
+
+type presetsResponse struct {
+	Presets  []presetJSON `json:"presets"`
+	Month    string       `json:"month,omitempty"`
+	Period   periodJSON   `json:"period"`
+	GameMode string       `json:"game_mode"`
+	Ruleset  int16        `json:"ruleset"`
+}
+
+// periodJSON is the time range a leaderboard covers, start inclusive and end
+// exclusive. Both are null for all time.
+type periodJSON struct {
+	Kind  string     `json:"kind"`
+	Start *time.Time `json:"start"`
+	End   *time.Time `json:"end"`
+}
+
+const popularPresetsLimit = 20
+
+// Entries shown above and below the player with ?around=me or ?id=X&t=Y
+const (
+	defaultAroundSpan = 5
+	maxAroundSpan     = 25
+)
+
 type leaderboardPlayerJSON struct {
 	OpenplanetID string `json:"openplanet_id"`
 	DisplayName  string `json:"display_name"`
@@ -36,25 +116,173 @@ type leaderboardEntryJSON struct {
 	MapsCompleted int32                 `json:"maps_completed"`
 	MapsSkipped   int32                 `json:"maps_skipped"`
 	DurationMs    int32                 `json:"duration_ms"`
+	ScorePerHour  float64               `json:"score_per_hour"`
+	Runs          int32                 `json:"runs"` // the player's runs on the leaderboard
 	GameMode      string                `json:"game_mode"`
 	CreatedAt     time.Time             `json:"created_at"`
 }
 
-func writeLeaderboardResponse(w http.ResponseWriter, scores []leaderboardEntryJSON, month, gameMode string) {
-	if gameMode == "" {
-		gameMode = "all"
+func writeLeaderboardResponse(w http.ResponseWriter, resp leaderboardResponse) {
+	if resp.GameMode == "" {
+		resp.GameMode = "all"
 	}
 	response.SetCache(w, config.Env.LeaderboardCacheTTL)
-	response.JSON(w, http.StatusOK, leaderboardResponse{
-		Scores:   scores,
+	response.JSON(w, http.StatusOK, resp)
+}
+
+// writePrivateLeaderboardResponse is writeLeaderboardResponse for an answer
+// about the caller's own session, which the edge must never cache.
+func writePrivateLeaderboardResponse(w http.ResponseWriter, resp leaderboardResponse) {
+	if resp.GameMode == "" {
+		resp.GameMode = "all"
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	response.JSON(w, http.StatusOK, resp)
+}
+
+func writePresetsResponse(w http.ResponseWriter, presets []presetJSON, month string, p periodJSON, ruleset int16) {
+	response.SetCache(w, config.Env.LeaderboardCacheTTL)
+	response.JSON(w, http.StatusOK, presetsResponse{
+		Presets:  presets,
 		Month:    month,
-		GameMode: gameMode,
+		Period:   p,
+		GameMode: "custom",
+		Ruleset:  ruleset,
 	})
 }
 
+// periodParam resolves the leaderboard's time range from ?month=YYYY-MM,
+// ?period=P&date=YYYY-MM-DD (the period containing date, today by default) or
+// ?from=YYYY-MM-DD&to=YYYY-MM-DD (both days included), and writes the error
+// response itself when that fails. Without any of them the range is all time.
+func periodParam(w http.ResponseWriter, q url.Values, now time.Time) (period.Range, bool) {
+	hasRange := q.Get("from") != "" || q.Get("to") != ""
+	given := 0
+	for _, b := range []bool{q.Get("month") != "", q.Get("period") != "", hasRange} {
+		if b {
+			given++
+		}
+	}
+	if given > 1 {
+		response.Error(w, http.StatusBadRequest, "month, period and from/to are mutually exclusive")
+		return period.Range{}, false
+	}
+
+	switch {
+	case q.Get("month") != "":
+		t, err := time.Parse("2006-01", q.Get("month"))
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "invalid month format, expected YYYY-MM")
+			return period.Range{}, false
+		}
+		r, _ := period.Resolve(period.Month, t)
+		return r, true
+
+	case hasRange:
+		from, err1 := time.Parse(period.DateLayout, q.Get("from"))
+		to, err2 := time.Parse(period.DateLayout, q.Get("to"))
+		if err1 != nil || err2 != nil {
+			response.Error(w, http.StatusBadRequest, "from and to are required, formatted YYYY-MM-DD")
+			return period.Range{}, false
+		}
+		maxDays := config.Env.LeaderboardMaxRangeDays
+		r, err := period.Between(from, to, maxDays)
+		if errors.Is(err, period.ErrRangeTooLarge) {
+			response.Error(w, http.StatusBadRequest, fmt.Sprintf("from and to must be at most %d days apart", maxDays))
+			return period.Range{}, false
+		}
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, err.Error())
+			return period.Range{}, false
+		}
+		return r, true
+	}
+
+	kind := q.Get("period")
+	if kind == "" {
+		kind = period.All
+	}
+	anchor := now
+	if s := q.Get("date"); s != "" {
+		t, err := time.Parse(period.DateLayout, s)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "invalid date format, expected YYYY-MM-DD")
+			return period.Range{}, false
+		}
+		anchor = t
+	}
+	r, err := period.Resolve(kind, anchor)
+	if err != nil {
+		// Already checked by leaderboardQuery validation
+		response.Error(w, http.StatusBadRequest, err.Error())
+		return period.Range{}, false
+	}
+	return r, true
+}
+
+// minScoreParam reads ?min_score=N, the score in milliseconds of medal time
+// runs need to count on a rank_by=fewest_skips leaderboard, and writes the
+// error response itself when that fails. Other metrics take no threshold and
+// get 0.
+func minScoreParam(w http.ResponseWriter, q url.Values, rankBy string) (int32, bool) {
+	s := q.Get("min_score")
+	if rankBy != ranking.ByFewestSkips {
+		if s != "" {
+			response.Error(w, http.StatusBadRequest, "min_score is only valid with rank_by=fewest_skips")
+			return 0, false
+		}
+		return 0, true
+	}
+	if s == "" {
+		return int32(config.Env.FewestSkipsMinScore), true
+	}
+	n, err := strconv.ParseInt(s, 10, 32)
+	if err != nil || n < 1 {
+		response.Error(w, http.StatusBadRequest, "min_score must be a positive integer")
+		return 0, false
+	}
+	return int32(n), true
+}
+
+// rulesetParam reads ?ruleset=N, defaulting to the newest ruleset, and writes
+// the error response itself when that fails.
+func rulesetParam(w http.ResponseWriter, r *http.Request) (int16, bool) {
+	if s := r.URL.Query().Get("ruleset"); s != "" {
+		n, err := strconv.ParseInt(s, 10, 16)
+		if err != nil || n < 1 {
+			response.Error(w, http.StatusBadRequest, "ruleset must be a positive integer")
+			return 0, false
+		}
+		return int16(n), true
+	}
+
+	rules, err := config.Plugin()
+	if err != nil {
+		slog.Error("plugin rules error", "error", err)
+		response.Error(w, http.StatusServiceUnavailable, "service unavailable")
+		return 0, false
+	}
+	return rules.CurrentRuleset(), true
+}
+
 // No leaderboard data exists before this month.
 var leaderboardEarliestMonth = time.Date(2025, time.November, 1, 0, 0, 0, 0, time.UTC)
 
+// Leaderboard handles GET /api/leaderboard?game_mode=X plus a time range (see
+// periodParam), which the response reports back as period. Without a game
+// mode, author and gold scores are ranked together. Only scores under one
+// ruleset count: the newest, or the one picked with ?ruleset=N. Custom runs
+// are only comparable within a settings preset: game_mode=custom lists the
+// most played presets, and game_mode=custom&preset=H ranks the runs of one.
+// Players are ranked by their best run on ?rank_by: score (the default),
+// maps_completed or score_per_hour, by fewest_skips among runs scoring at
+// least ?min_score, or by the number of runs they played. Ranking and ties
+// follow package ranking: runs equal on the metric, score and the
+// tie-breakers share a rank, so ranks can repeat and skip (1, 2, 2, 4).
+// Leaderboards come in pages of ?limit entries; next_cursor, passed back as
+// ?cursor, fetches the following page. Instead of a page, ?around=me (for the
+// caller's session) or ?id=X&t=Y (a signed player link) returns that player's
+// entry with ?span entries above and below it.
 func Leaderboard(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		response.Error(w, http.StatusMethodNotAllowed, "method not allowed")
@@ -66,32 +294,131 @@ func Leaderboard(w http.ResponseWriter, r *http.Request) {
 	query := leaderboardQuery{
 		GameMode: q.Get("game_mode"),
 		Month:    q.Get("month"),
+		Period:   q.Get("period"),
+		Preset:   q.Get("preset"),
+		Around:   q.Get("around"),
+		ID:       q.Get("id"),
+		Sig:      q.Get("t"),
 	}
 	if err := validate.Struct(query); err != nil {
 		response.Error(w, http.StatusBadRequest, validate.FormatError(err))
 		return
 	}
+	if query.Preset != "" {
+		if query.GameMode != "custom" {
+			response.Error(w, http.StatusBadRequest, "preset is only valid with game_mode=custom")
+			return
+		}
+		if !preset.ValidHash(query.Preset) {
+			response.Error(w, http.StatusBadRequest, "invalid preset")
+			return
+		}
+	}
+	listPresets := query.GameMode == "custom" && query.Preset == ""
 
-	var startTime *time.Time
-	var endTime *time.Time
-	if query.Month != "" {
-		t, err := time.Parse("2006-01", query.Month)
-		if err != nil {
-			response.Error(w, http.StatusBadRequest, "invalid month format, expected YYYY-MM")
+	rankBy := q.Get("rank_by")
+	if rankBy == "" {
+		rankBy = ranking.ByScore
+	}
+	if !ranking.ValidMetric(rankBy) {
+		response.Error(w, http.StatusBadRequest, "rank_by must be one of "+strings.Join(ranking.Metrics, ", "))
+		return
+	}
+	minScore, ok := minScoreParam(w, q, rankBy)
+	if !ok {
+		return
+	}
+
+	if (query.ID == "") != (query.Sig == "") {
+		response.Error(w, http.StatusBadRequest, "id and t must be given together")
+		return
+	}
+	aroundPlayer := query.Around != "" || query.ID != ""
+	span := defaultAroundSpan
+	if aroundPlayer {
+		if query.Around != "" && query.ID != "" {
+			response.Error(w, http.StatusBadRequest, "around and id are mutually exclusive")
 			return
 		}
+		if q.Has("cursor") {
+			response.Error(w, http.StatusBadRequest, "cursor is not valid with around or id")
+			return
+		}
+		if listPresets {
+			response.Error(w, http.StatusBadRequest, "preset is required with around or id for game_mode=custom")
+			return
+		}
+		if s := q.Get("span"); s != "" {
+			n, err := strconv.Atoi(s)
+			if err != nil || n < 0 || n > maxAroundSpan {
+				response.Error(w, http.StatusBadRequest, fmt.Sprintf("span must be between 0 and %d", maxAroundSpan))
+				return
+			}
+			span = n
+		}
+	}
+	if query.ID != "" && !playerlink.Verify(query.ID, query.Sig) {
+		response.SetCache(w, config.Env.LeaderboardCacheTTL)
+		response.Error(w, http.StatusNotFound, "not found")
+		return
+	}
 
-		// return empty leaderboard for requests outside expected range
-		now := time.Now().UTC()
-		currentMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
-		if t.Before(leaderboardEarliestMonth) || t.After(currentMonth) {
-			writeLeaderboardResponse(w, []leaderboardEntryJSON{}, query.Month, query.GameMode)
+	limit, err := pagination.Limit(q.Get("limit"), config.Env.LeaderboardPageSize, config.Env.LeaderboardMaxPageSize)
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	var after *db.RankedRun
+	if s := q.Get("cursor"); s != "" {
+		var c leaderboardCursor
+		if err := pagination.Decode(s, &c); err != nil {
+			response.Error(w, http.StatusBadRequest, err.Error())
 			return
 		}
+		run := db.RankedRun(c)
+		after = &run
+	}
 
-		end := t.AddDate(0, 1, 0)
-		startTime = &t
-		endTime = &end
+	ruleset, ok := rulesetParam(w, r)
+	if !ok {
+		return
+	}
+
+	now := time.Now().UTC()
+	rng, ok := periodParam(w, q, now)
+	if !ok {
+		return
+	}
+	startTime, endTime := rng.Start, rng.End
+	resolved := periodJSON{Kind: rng.Kind, Start: rng.Start, End: rng.End}
+
+	// return empty leaderboard for requests outside expected range
+	if !rng.Overlaps(leaderboardEarliestMonth, now) {
+		empty := leaderboardResponse{
+			Scores:   []leaderboardEntryJSON{},
+			Month:    query.Month,
+			Period:   resolved,
+			GameMode: query.GameMode,
+			Ruleset:  ruleset,
+			RankBy:   rankBy,
+			MinScore: minScore,
+		}
+		switch {
+		case listPresets:
+			writePresetsResponse(w, []presetJSON{}, query.Month, resolved, ruleset)
+		case query.Around == "me":
+			// Still only for a signed-in caller, and never cached
+			auth.RequireAuth(func(w http.ResponseWriter, r *http.Request, _ uuid.UUID) {
+				empty.Around = &aroundJSON{}
+				writePrivateLeaderboardResponse(w, empty)
+			})(w, r)
+		case aroundPlayer:
+			empty.Around = &aroundJSON{}
+			writeLeaderboardResponse(w, empty)
+		default:
+			writeLeaderboardResponse(w, empty)
+		}
+		return
 	}
 
 	database, err := db.GetDB()
@@ -101,17 +428,147 @@ func Leaderboard(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	entries, err := db.GetLeaderboard(database, db.LeaderboardParams{
-		GameMode:  query.GameMode,
-		StartTime: startTime,
-		EndTime:   endTime,
-	})
+	if listPresets {
+		rows, err := db.ListPopularPresets(database, ruleset, startTime, endTime, popularPresetsLimit)
+		if err != nil {
+			slog.Error("preset list query error", "error", err)
+			response.Error(w, http.StatusServiceUnavailable, "service unavailable")
+			return
+		}
+		presets := make([]presetJSON, len(rows))
+		for i, p := range rows {
+			presets[i] = presetJSON{
+				Hash:     p.Hash,
+				Settings: json.RawMessage(p.Settings),
+				Players:  p.Players,
+				Runs:     p.Runs,
+			}
+		}
+		writePresetsResponse(w, presets, query.Month, resolved, ruleset)
+		return
+	}
+
+	var boardPreset *presetJSON
+	if query.Preset != "" {
+		p, err := db.GetPreset(database, query.Preset)
+		if err != nil {
+			slog.Error("preset lookup error", "error", err)
+			response.Error(w, http.StatusServiceUnavailable, "service unavailable")
+			return
+		}
+		if p == nil {
+			response.SetCache(w, config.Env.LeaderboardCacheTTL)
+			response.Error(w, http.StatusNotFound, "preset not found")
+			return
+		}
+		boardPreset = &presetJSON{Hash: p.Hash, Settings: json.RawMessage(p.Settings)}
+	}
+
+	params := db.LeaderboardParams{
+		GameMode:     query.GameMode,
+		SettingsHash: query.Preset,
+		Ruleset:      ruleset,
+		StartTime:    startTime,
+		EndTime:      endTime,
+		RankBy:       rankBy,
+		MinScore:     minScore,
+	}
+	resp := leaderboardResponse{
+		Month:    query.Month,
+		Period:   resolved,
+		GameMode: query.GameMode,
+		Ruleset:  ruleset,
+		Preset:   boardPreset,
+		RankBy:   rankBy,
+		MinScore: minScore,
+	}
+
+	switch {
+	case query.Around == "me":
+		auth.RequireAuth(func(w http.ResponseWriter, r *http.Request, playerID uuid.UUID) {
+			writeAround(w, database, params, playerID, span, resp, true)
+		})(w, r)
+		return
+	case query.ID != "":
+		playerID, err := db.FindPlayerID(database, query.ID)
+		if err != nil {
+			slog.Error("player lookup error", "error", err)
+			response.Error(w, http.StatusServiceUnavailable, "service unavailable")
+			return
+		}
+		if playerID == uuid.Nil {
+			response.SetCache(w, config.Env.LeaderboardCacheTTL)
+			response.Error(w, http.StatusNotFound, "not found")
+			return
+		}
+		writeAround(w, database, params, playerID, span, resp, false)
+		return
+	}
+
+	// One extra entry tells whether there is a next page
+	entries, err := db.GetLeaderboard(database, params, after, int64(limit)+1)
 	if err != nil {
 		slog.Error("leaderboard query error", "error", err)
 		response.Error(w, http.StatusServiceUnavailable, "service unavailable")
 		return
 	}
+	var nextCursor string
+	if len(entries) > limit {
+		entries = entries[:limit]
+		nextCursor = encodeCursor(entries[limit-1])
+	}
+
+	total, err := db.CountRankedPlayers(database, params)
+	if err != nil {
+		slog.Error("leaderboard count error", "error", err)
+		response.Error(w, http.StatusServiceUnavailable, "service unavailable")
+		return
+	}
+
+	resp.Scores = toLeaderboardJSON(entries)
+	resp.Total = total
+	resp.NextCursor = nextCursor
+	writeLeaderboardResponse(w, resp)
+}
+
+// writeAround answers with the slice of the leaderboard around playerID;
+// next_cursor continues below it. A private answer, for the caller's own
+// session, is never cached by the edge.
+func writeAround(w http.ResponseWriter, database *sql.DB, params db.LeaderboardParams, playerID uuid.UUID, span int, resp leaderboardResponse, private bool) {
+	entries, err := db.GetLeaderboardAround(database, params, playerID, span)
+	if err != nil {
+		slog.Error("leaderboard around query error", "error", err)
+		response.Error(w, http.StatusServiceUnavailable, "service unavailable")
+		return
+	}
+	total, err := db.CountRankedPlayers(database, params)
+	if err != nil {
+		slog.Error("leaderboard count error", "error", err)
+		response.Error(w, http.StatusServiceUnavailable, "service unavailable")
+		return
+	}
 
+	resp.Scores = toLeaderboardJSON(entries)
+	resp.Total = total
+	resp.Around = &aroundJSON{}
+	for _, e := range entries {
+		if e.PlayerID == playerID {
+			rank := e.Rank
+			resp.Around.Rank = &rank
+		}
+	}
+	if n := len(entries); n > 0 && int64(entries[n-1].Position) < total {
+		resp.NextCursor = encodeCursor(entries[n-1])
+	}
+
+	if private {
+		writePrivateLeaderboardResponse(w, resp)
+		return
+	}
+	writeLeaderboardResponse(w, resp)
+}
+
+func toLeaderboardJSON(entries []db.LeaderboardEntry) []leaderboardEntryJSON {
 	scores := make([]leaderboardEntryJSON, len(entries))
 	for i, e := range entries {
 		createdAt := time.Time{}
@@ -129,10 +586,11 @@ func Leaderboard(w http.ResponseWriter, r *http.Request) {
 			MapsCompleted: e.MapsCompleted,
 			MapsSkipped:   e.MapsSkipped,
 			DurationMs:    e.DurationMs,
+			ScorePerHour:  ranking.ScorePerHour(e.Score, e.DurationMs),
+			Runs:          e.Runs,
 			GameMode:      e.GameMode.String(),
 			CreatedAt:     createdAt,
 		}
 	}
-
-	writeLeaderboardResponse(w, scores, query.Month, query.GameMode)
+	return scores
 }
@@ -6,6 +6,7 @@ import (
 
 	"github.com/google/uuid"
 
+	"rmpc-server/api/_pkg/audit"
 	"rmpc-server/api/_pkg/auth"
 	"rmpc-server/api/_pkg/config"
 	"rmpc-server/api/_pkg/db"
@@ -21,8 +22,14 @@ func Handler(w http.ResponseWriter, r *http.Request) {
 
 	name := r.URL.Query().Get("name")
 
-	auth.RequireAuth(func(w http.ResponseWriter, r *http.Request, _ uuid.UUID) {
+	auth.RequireAuth(func(w http.ResponseWriter, r *http.Request, playerID uuid.UUID) {
 		if !config.IsAllowedMetric(name) {
+			audit.Record(r, audit.Entry{
+				Actor:      playerID,
+				Action:     audit.ActionMetricRejected,
+				TargetType: audit.TargetMetric,
+				TargetID:   name,
+			})
 			response.Error(w, http.StatusBadRequest, "metric name not allowed")
 			return
 		}
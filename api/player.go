@@ -23,6 +23,7 @@ type playerScoreJSON struct {
 	MapsSkipped   int32     `json:"maps_skipped"`
 	DurationMs    int32     `json:"duration_ms"`
 	CreatedAt     time.Time `json:"created_at"`
+	Preset        string    `json:"preset,omitempty"` // custom mode only
 }
 
 type playerModeJSON struct {
@@ -90,6 +91,7 @@ func buildPlayerResponse(d *db.PlayerDetail) playerResponse {
 	modes := map[string]*playerModeJSON{
 		"author": {GameMode: "author", Scores: []playerScoreJSON{}},
 		"gold":   {GameMode: "gold", Scores: []playerScoreJSON{}},
+		"custom": {GameMode: "custom", Scores: []playerScoreJSON{}},
 	}
 	for _, s := range d.Scores {
 		m, ok := modes[s.GameMode.String()]
@@ -100,19 +102,23 @@ func buildPlayerResponse(d *db.PlayerDetail) playerResponse {
 		if s.CreatedAt != nil {
 			createdAt = *s.CreatedAt
 		}
-		m.Scores = append(m.Scores, playerScoreJSON{
+		score := playerScoreJSON{
 			Score:         s.Score,
 			MapsCompleted: s.MapsCompleted,
 			MapsSkipped:   s.MapsSkipped,
 			DurationMs:    s.DurationMs,
 			CreatedAt:     createdAt,
-		})
+		}
+		if s.SettingsHash != nil {
+			score.Preset = *s.SettingsHash
+		}
+		m.Scores = append(m.Scores, score)
 	}
 	return playerResponse{
 		Player: playerHeaderJSON{
 			OpenplanetID: d.OpenplanetID,
 			DisplayName:  d.DisplayName,
 		},
-		Modes: []playerModeJSON{*modes["author"], *modes["gold"]},
+		Modes: []playerModeJSON{*modes["author"], *modes["gold"], *modes["custom"]},
 	}
 }
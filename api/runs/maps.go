@@ -0,0 +1,170 @@
+package handler
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/google/uuid"
+
+	"rmpc-server/api/_pkg/auth"
+	"rmpc-server/api/_pkg/config"
+	"rmpc-server/api/_pkg/db"
+	"rmpc-server/api/_pkg/response"
+	"rmpc-server/api/_pkg/runs"
+	"rmpc-server/api/_pkg/validate"
+)
+
+type runMapJSON struct {
+	Seq          int32  `json:"seq"            validate:"gte=0,lte=999"`
+	MapUID       string `json:"map_uid"        validate:"required,max=64"`
+	Medal        string `json:"medal"          validate:"required,oneof=none bronze silver gold author"`
+	TimeMs       *int32 `json:"time_ms"        validate:"omitempty,gte=1"`
+	TargetTimeMs int32  `json:"target_time_ms" validate:"gte=1,lte=3600000"`
+	Skipped      bool   `json:"skipped"`
+	Broken       bool   `json:"broken"`
+}
+
+type runMapsRequest struct {
+	RunID string       `json:"run_id" validate:"required"`
+	Maps  []runMapJSON `json:"maps"   validate:"required,min=1,max=100,dive"`
+}
+
+type runMapsResponse struct {
+	Maps          []runMapJSON `json:"maps"`
+	Score         *int64       `json:"score,omitempty"` // only with MAP_RESULTS_SCORE_CHECK set
+	MapsCompleted int64        `json:"maps_completed"`
+	MapsSkipped   int64        `json:"maps_skipped"`
+}
+
+// Maps handles /api/runs/maps. POST reports map results for an unfinished run
+// (one map at a time as they are played, or all of them at once); resending a
+// seq replaces it. GET ?score_id=X returns the map results behind a score.
+func Maps(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		handleGetRunMaps(w, r)
+	case http.MethodPost:
+		auth.RequireAuth(handleReportRunMaps)(w, r)
+	default:
+		response.Error(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+func handleReportRunMaps(w http.ResponseWriter, r *http.Request, playerID uuid.UUID) {
+	r.Body = http.MaxBytesReader(w, r.Body, 64*1024) // 64KB
+	var req runMapsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if err := validate.Struct(req); err != nil {
+		response.Error(w, http.StatusBadRequest, validate.FormatError(err))
+		return
+	}
+	runID, err := uuid.Parse(req.RunID)
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid run_id")
+		return
+	}
+
+	database, err := db.GetDB()
+	if err != nil {
+		slog.Error("database connection error", "error", err)
+		response.Error(w, http.StatusServiceUnavailable, "service unavailable")
+		return
+	}
+
+	run, err := db.FindRun(database, runID, playerID)
+	if err != nil {
+		slog.Error("run lookup error", "error", err)
+		response.Error(w, http.StatusServiceUnavailable, "service unavailable")
+		return
+	}
+	if run == nil {
+		response.Error(w, http.StatusNotFound, "run not found")
+		return
+	}
+	if run.FinishedAt != nil {
+		response.Error(w, http.StatusConflict, "run has already been submitted")
+		return
+	}
+
+	maps := make([]db.RunMapInput, len(req.Maps))
+	for i, m := range req.Maps {
+		maps[i] = db.RunMapInput{
+			Seq:          m.Seq,
+			MapUID:       m.MapUID,
+			Medal:        m.Medal,
+			TimeMs:       m.TimeMs,
+			TargetTimeMs: m.TargetTimeMs,
+			Skipped:      m.Skipped,
+			Broken:       m.Broken,
+		}
+	}
+	if err := db.UpsertRunMaps(database, runID, maps); err != nil {
+		slog.Error("upsert run maps error", "error", err)
+		response.Error(w, http.StatusServiceUnavailable, "service unavailable")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func handleGetRunMaps(w http.ResponseWriter, r *http.Request) {
+	scoreID, err := uuid.Parse(r.URL.Query().Get("score_id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid score_id")
+		return
+	}
+
+	database, err := db.GetDB()
+	if err != nil {
+		slog.Error("database connection error", "error", err)
+		response.Error(w, http.StatusServiceUnavailable, "service unavailable")
+		return
+	}
+
+	rows, err := db.GetScoreRunMaps(database, scoreID)
+	if err != nil {
+		slog.Error("get run maps error", "error", err)
+		response.Error(w, http.StatusServiceUnavailable, "service unavailable")
+		return
+	}
+	if len(rows) == 0 {
+		response.Error(w, http.StatusNotFound, "no map results for this score")
+		return
+	}
+
+	maps := make([]runMapJSON, len(rows))
+	results := make([]runs.MapResult, len(rows))
+	for i, m := range rows {
+		maps[i] = runMapJSON{
+			Seq:          m.Seq,
+			MapUID:       m.MapUID,
+			Medal:        m.Medal.String(),
+			TimeMs:       m.TimeMs,
+			TargetTimeMs: m.TargetTimeMs,
+			Skipped:      m.Skipped,
+			Broken:       m.Broken,
+		}
+		results[i] = runs.MapResult{
+			TimeMs:       m.TimeMs,
+			TargetTimeMs: m.TargetTimeMs,
+			Skipped:      m.Skipped,
+			Broken:       m.Broken,
+		}
+	}
+	totals := runs.ComputeTotals(results)
+	resp := runMapsResponse{
+		Maps:          maps,
+		MapsCompleted: totals.MapsCompleted,
+		MapsSkipped:   totals.MapsSkipped,
+	}
+	if config.Env.MapResultsScoreCheck {
+		resp.Score = &totals.Score
+	}
+
+	response.SetCache(w, config.Env.PlayerCacheTTL)
+	response.JSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+
+	"rmpc-server/api/_pkg/auth"
+	"rmpc-server/api/_pkg/config"
+	"rmpc-server/api/_pkg/db"
+	"rmpc-server/api/_pkg/response"
+	"rmpc-server/api/_pkg/validate"
+)
+
+type runStartRequest struct {
+	GameMode string `json:"game_mode" validate:"required,oneof=author gold custom"`
+}
+
+type runStartResponse struct {
+	RunID     string    `json:"run_id"`
+	StartedAt time.Time `json:"started_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// Start handles POST /api/runs/start. The returned run_id goes into the score
+// submission at the end of the run, which is then checked against the
+// server's start time and can't be submitted twice. A player keeps at most
+// MAX_OPEN_RUNS unfinished runs; starting another drops the oldest.
+func Start(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		response.Error(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	auth.RequireAuth(handleRunStart)(w, r)
+}
+
+func handleRunStart(w http.ResponseWriter, r *http.Request, playerID uuid.UUID) {
+	r.Body = http.MaxBytesReader(w, r.Body, 4*1024) // 4KB
+	var req runStartRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if err := validate.Struct(req); err != nil {
+		response.Error(w, http.StatusBadRequest, validate.FormatError(err))
+		return
+	}
+
+	database, err := db.GetDB()
+	if err != nil {
+		slog.Error("database connection error", "error", err)
+		response.Error(w, http.StatusServiceUnavailable, "service unavailable")
+		return
+	}
+
+	runID, startedAt, err := db.CreateRun(database, playerID, req.GameMode, config.Env.RunMaxAge, config.Env.MaxOpenRuns)
+	if err != nil {
+		slog.Error("create run error", "error", err)
+		response.Error(w, http.StatusServiceUnavailable, "service unavailable")
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	response.JSON(w, http.StatusCreated, runStartResponse{
+		RunID:     runID.String(),
+		StartedAt: startedAt,
+		ExpiresAt: startedAt.Add(config.Env.RunMaxAge),
+	})
+}
@@ -1,34 +1,39 @@
 package handler
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
 	"encoding/json"
+	"io"
 	"log/slog"
 	"net/http"
 	"time"
 
 	"github.com/google/uuid"
 
+	"rmpc-server/api/_pkg/audit"
 	"rmpc-server/api/_pkg/auth"
 	"rmpc-server/api/_pkg/config"
 	"rmpc-server/api/_pkg/db"
 	"rmpc-server/api/_pkg/response"
-	"rmpc-server/api/_pkg/validate"
+	"rmpc-server/api/_pkg/submit"
 )
 
-type scoreSubmitRequest struct {
-	GameMode      string          `json:"game_mode"      validate:"required,oneof=author gold custom"`
-	Score         int32           `json:"score"           validate:"gte=0"`
-	MapsCompleted int32           `json:"maps_completed"  validate:"gte=0"`
-	MapsSkipped   int32           `json:"maps_skipped"    validate:"gte=0"`
-	DurationMs    int32           `json:"duration_ms"     validate:"gte=60000,lte=7200000"`
-	Metadata      json.RawMessage `json:"metadata,omitempty"`
-}
-
-type scoreSubmitResponse struct {
-	ID        string    `json:"id"`
-	CreatedAt time.Time `json:"created_at"`
-}
+const maxIdempotencyKeyLength = 255
 
+// Scores handles POST /api/scores. Clients may send an Idempotency-Key header
+// so that retrying a submission whose response was lost replays the original
+// response instead of tripping the cooldown or recording the run twice. A key
+// held by a request that never stored its score is freed after
+// IDEMPOTENCY_RESERVATION_TIMEOUT. The X-Plugin-Version header decides whether
+// the plugin may submit at all and which scoring ruleset the run counts under.
+// The response tells the player how the run compares with their previous
+// bests and where it places them.
+// With PROOF_REQUIRED_TOP_N set, a run that would place that high is held as
+// pending_proof until a file is attached through /api/scores/proofs, and then
+// waits for a moderator to check it.
 func Scores(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		response.Error(w, http.StatusMethodNotAllowed, "method not allowed")
@@ -55,40 +60,73 @@ func handleScoreSubmit(w http.ResponseWriter, r *http.Request, playerID uuid.UUI
 	}
 	if banned {
 		// Fake OK
-		response.JSON(w, http.StatusCreated, scoreSubmitResponse{
-			ID:        uuid.New().String(),
-			CreatedAt: time.Now(),
-		})
+		response.JSON(w, http.StatusCreated, submit.Fake(time.Now()))
+		return
+	}
+
+	client, serr := submit.CheckClient(r, playerID)
+	if serr != nil {
+		response.Error(w, serr.Status, serr.Message)
 		return
 	}
 
-	// Parse request
+	// Parse request. The raw body is kept to fingerprint it for idempotency.
 	r.Body = http.MaxBytesReader(w, r.Body, 512*1024) // 512KB
-	var req scoreSubmitRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	var req submit.Request
+	if err := json.NewDecoder(bytes.NewReader(body)).Decode(&req); err != nil {
 		response.Error(w, http.StatusBadRequest, "invalid request body")
 		return
 	}
-	if err := validate.Struct(req); err != nil {
-		response.Error(w, http.StatusBadRequest, validate.FormatError(err))
+	if serr := submit.Validate(&req); serr != nil {
+		response.Error(w, serr.Status, serr.Message)
 		return
 	}
 
-	// Validate metadata
-	if len(req.Metadata) > 0 {
-		if len(req.Metadata) > 256*1024 {
-			response.Error(w, http.StatusBadRequest, "metadata must not exceed 256KB")
+	// Claim the idempotency key, or answer from its earlier use
+	idempotencyKey := r.Header.Get("Idempotency-Key")
+	inserted := false
+	if idempotencyKey != "" {
+		if !validIdempotencyKey(idempotencyKey) {
+			response.Error(w, http.StatusBadRequest, "invalid Idempotency-Key header")
 			return
 		}
-		var metaMap map[string]interface{}
-		if err := json.Unmarshal(req.Metadata, &metaMap); err != nil {
-			response.Error(w, http.StatusBadRequest, "metadata must be a JSON object")
+
+		sum := sha256.Sum256(body)
+		requestHash := hex.EncodeToString(sum[:])
+		prev, err := db.ReserveIdempotencyKey(database, playerID, idempotencyKey, requestHash, config.Env.IdempotencyKeyTTL, config.Env.IdempotencyReservationTimeout)
+		if err != nil {
+			slog.Error("idempotency key reserve error", "error", err)
+			response.Error(w, http.StatusServiceUnavailable, "service unavailable")
 			return
 		}
-		if len(metaMap) > 10 {
-			response.Error(w, http.StatusBadRequest, "metadata must not have more than 10 keys")
+		if prev != nil {
+			replayScoreSubmit(w, database, prev, requestHash)
 			return
 		}
+
+		// Free the key again unless the score got stored, so a retry after a
+		// failure is processed instead of being told to wait forever
+		defer func() {
+			if inserted {
+				return
+			}
+			if err := db.ReleaseIdempotencyKey(database, playerID, idempotencyKey); err != nil {
+				slog.Error("idempotency key release error", "error", err)
+			}
+		}()
+	}
+
+	// Check the run against the server's start time and its map results, and
+	// the totals against the plausibility rules
+	input, serr := submit.Prepare(r, database, playerID, client, &req, time.Now())
+	if serr != nil {
+		response.Error(w, serr.Status, serr.Message)
+		return
 	}
 
 	// Check cooldown
@@ -99,35 +137,108 @@ func handleScoreSubmit(w http.ResponseWriter, r *http.Request, playerID uuid.UUI
 		return
 	}
 	if !canSubmit {
+		audit.Record(r, audit.Entry{
+			Actor:      playerID,
+			Action:     audit.ActionScoreRejected,
+			TargetType: audit.TargetPlayer,
+			TargetID:   playerID.String(),
+			Details: map[string]any{
+				"reason":   "cooldown",
+				"cooldown": config.Env.ScoreCooldown.String(),
+			},
+		})
 		response.Error(w, http.StatusTooManyRequests, "please wait before submitting another score")
 		return
 	}
 
-	// Convert metadata to *string for go-jet JSONB column
-	var metadata *string
-	if len(req.Metadata) > 0 {
-		s := string(req.Metadata)
-		metadata = &s
+	if idempotencyKey != "" {
+		input.IdempotencyKey = &idempotencyKey
+	}
+	resp, serr := submit.Store(r, database, input)
+	if serr != nil {
+		response.Error(w, serr.Status, serr.Message)
+		return
+	}
+	inserted = true
+
+	if idempotencyKey != "" {
+		b, err := json.Marshal(resp)
+		if err == nil {
+			err = db.CompleteIdempotencyKey(database, playerID, idempotencyKey, string(b))
+		}
+		if err != nil {
+			slog.Error("idempotency key store error", "error", err)
+		}
+	}
+
+	response.JSON(w, http.StatusCreated, resp)
+}
+
+// replayScoreSubmit answers a request whose Idempotency-Key was seen before.
+func replayScoreSubmit(w http.ResponseWriter, database *sql.DB, prev *db.IdempotencyKey, requestHash string) {
+	if prev.RequestHash != requestHash {
+		response.Error(w, http.StatusUnprocessableEntity, "Idempotency-Key was already used for a different request")
+		return
+	}
+	if prev.Response == nil && prev.ScoreID != nil {
+		replayStoredScore(w, database, *prev.ScoreID)
+		return
+	}
+	if prev.Response == nil {
+		response.Error(w, http.StatusConflict, "a request with this Idempotency-Key is still being processed")
+		return
+	}
+
+	var resp submit.Response
+	if err := json.Unmarshal([]byte(*prev.Response), &resp); err != nil {
+		slog.Error("idempotency key replay error", "error", err)
+		response.Error(w, http.StatusInternalServerError, "internal server error")
+		return
 	}
 
-	// Insert score
-	id, createdAt, err := db.InsertScore(database, db.ScoreInput{
-		PlayerID:      playerID,
-		GameMode:      req.GameMode,
-		Score:         req.Score,
-		MapsCompleted: req.MapsCompleted,
-		MapsSkipped:   req.MapsSkipped,
-		DurationMs:    req.DurationMs,
-		Metadata:      metadata,
-	})
+	w.Header().Set("Idempotent-Replayed", "true")
+	response.JSON(w, http.StatusCreated, resp)
+}
+
+// replayStoredScore answers for a score whose request failed to store its
+// response, with what the score itself records.
+func replayStoredScore(w http.ResponseWriter, database *sql.DB, scoreID uuid.UUID) {
+	score, err := db.FindScore(database, scoreID)
 	if err != nil {
-		slog.Error("insert score error", "error", err)
+		slog.Error("idempotency key replay error", "error", err)
 		response.Error(w, http.StatusServiceUnavailable, "service unavailable")
 		return
 	}
+	if score == nil {
+		response.Error(w, http.StatusNotFound, "score not found")
+		return
+	}
+
+	resp := submit.Response{
+		ID:           score.ID.String(),
+		ReviewStatus: score.ReviewStatus.String(),
+	}
+	if score.CreatedAt != nil {
+		resp.CreatedAt = *score.CreatedAt
+	}
+	if score.SettingsHash != nil {
+		resp.Preset = *score.SettingsHash
+	}
+
+	w.Header().Set("Idempotent-Replayed", "true")
+	response.JSON(w, http.StatusCreated, resp)
+}
 
-	response.JSON(w, http.StatusCreated, scoreSubmitResponse{
-		ID:        id.String(),
-		CreatedAt: createdAt,
-	})
+// validIdempotencyKey accepts up to 255 printable ASCII characters, which
+// covers UUIDs and any other reasonable client-generated token.
+func validIdempotencyKey(key string) bool {
+	if len(key) > maxIdempotencyKeyLength {
+		return false
+	}
+	for i := 0; i < len(key); i++ {
+		if key[i] < 0x21 || key[i] > 0x7e {
+			return false
+		}
+	}
+	return true
 }
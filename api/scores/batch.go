@@ -0,0 +1,179 @@
+package handler
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"rmpc-server/api/_pkg/auth"
+	"rmpc-server/api/_pkg/config"
+	"rmpc-server/api/_pkg/db"
+	"rmpc-server/api/_pkg/response"
+	"rmpc-server/api/_pkg/runs"
+	"rmpc-server/api/_pkg/submit"
+)
+
+type batchRequest struct {
+	Runs []json.RawMessage `json:"runs"`
+}
+
+type batchResultJSON struct {
+	Index  int              `json:"index"`
+	Status int              `json:"status"` // what the run would get from POST /api/scores
+	Score  *submit.Response `json:"score,omitempty"`
+	Error  string           `json:"error,omitempty"`
+}
+
+type batchResponse struct {
+	Results  []batchResultJSON `json:"results"`
+	Accepted int               `json:"accepted"`
+}
+
+// Batch handles POST /api/scores/batch, for runs the plugin queued while the
+// server was unreachable. Each run carries its finished_at, which becomes its
+// created_at and must lie within BATCH_MAX_AGE and the current month. Runs are
+// validated like single submissions, except that the cooldown is replaced by a
+// check that the player wasn't playing two runs at once, and by a per-player
+// daily upload limit. Runs linked to a run_id must be submitted live instead.
+// The response holds a result per run, in request order.
+func Batch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		response.Error(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	auth.RequireSession(handleBatch)(w, r)
+}
+
+// batch holds what is known while working through one upload.
+type batch struct {
+	submit.Batch
+	r        *http.Request
+	database *sql.DB
+	session  *db.Session
+	client   submit.Client
+}
+
+func handleBatch(w http.ResponseWriter, r *http.Request, session *db.Session) {
+	client, serr := submit.CheckClient(r, session.PlayerID)
+	if serr != nil {
+		response.Error(w, serr.Status, serr.Message)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, 4*1024*1024) // 4MB
+	var req batchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if len(req.Runs) == 0 {
+		response.Error(w, http.StatusBadRequest, "runs is required")
+		return
+	}
+	if len(req.Runs) > config.Env.BatchMaxRuns {
+		response.Error(w, http.StatusBadRequest, fmt.Sprintf("runs must not exceed %d items", config.Env.BatchMaxRuns))
+		return
+	}
+
+	database, err := db.GetDB()
+	if err != nil {
+		slog.Error("database connection error", "error", err)
+		response.Error(w, http.StatusServiceUnavailable, "service unavailable")
+		return
+	}
+
+	banned, err := db.IsPlayerBanned(database, session.PlayerID)
+	if err != nil {
+		slog.Error("ban check error", "error", err)
+		response.Error(w, http.StatusServiceUnavailable, "service unavailable")
+		return
+	}
+	if banned {
+		// Fake OK
+		resp := batchResponse{Results: make([]batchResultJSON, len(req.Runs)), Accepted: len(req.Runs)}
+		for i := range req.Runs {
+			score := submit.Fake(time.Now())
+			resp.Results[i] = batchResultJSON{Index: i, Status: http.StatusCreated, Score: &score}
+		}
+		response.JSON(w, http.StatusOK, resp)
+		return
+	}
+
+	b := &batch{
+		r:        r,
+		database: database,
+		session:  session,
+		client:   client,
+	}
+	b.Now = time.Now()
+
+	used, err := db.CountBatchScores(database, session.PlayerID, b.Now.Add(-24*time.Hour))
+	if err != nil {
+		slog.Error("batch count error", "error", err)
+		response.Error(w, http.StatusServiceUnavailable, "service unavailable")
+		return
+	}
+	b.Remaining = int64(config.Env.BatchPlayerDailyLimit) - used
+
+	// Runs last at most two hours, so older scores can't overlap the batch
+	from := b.Now.Add(-config.Env.BatchMaxAge - 2*time.Hour)
+	rows, err := db.ListPlayerScoreSpans(database, session.PlayerID, from, b.Now.Add(time.Hour))
+	if err != nil {
+		slog.Error("score spans error", "error", err)
+		response.Error(w, http.StatusServiceUnavailable, "service unavailable")
+		return
+	}
+	for _, row := range rows {
+		b.Spans = append(b.Spans, runs.Span{
+			FinishedAt: row.CreatedAt,
+			Duration:   time.Duration(row.DurationMs) * time.Millisecond,
+		})
+	}
+
+	resp := batchResponse{Results: make([]batchResultJSON, len(req.Runs))}
+	for i, raw := range req.Runs {
+		score, serr := b.add(raw)
+		result := batchResultJSON{Index: i, Status: http.StatusCreated, Score: score}
+		if serr != nil {
+			result.Status = serr.Status
+			result.Error = serr.Message
+		} else {
+			resp.Accepted++
+		}
+		resp.Results[i] = result
+	}
+
+	response.JSON(w, http.StatusOK, resp)
+}
+
+// add validates and stores one run of the batch.
+func (b *batch) add(raw json.RawMessage) (*submit.Response, *submit.Error) {
+	var run submit.BatchRun
+	if err := json.Unmarshal(raw, &run); err != nil {
+		return nil, &submit.Error{Status: http.StatusBadRequest, Message: "invalid run"}
+	}
+	if serr := submit.Validate(&run.Request); serr != nil {
+		return nil, serr
+	}
+	if serr := b.Check(&run); serr != nil {
+		return nil, serr
+	}
+
+	input, serr := submit.Prepare(b.r, b.database, b.session.PlayerID, b.client, &run.Request, run.FinishedAt)
+	if serr != nil {
+		return nil, serr
+	}
+	input.CreatedAt = &run.FinishedAt
+	input.BatchSessionID = &b.session.ID
+
+	score, serr := submit.Store(b.r, b.database, input)
+	if serr != nil {
+		return nil, serr
+	}
+	b.Added(&run)
+	return score, nil
+}
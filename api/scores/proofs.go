@@ -0,0 +1,345 @@
+package handler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+
+	"rmpc-server/api/_pkg/audit"
+	"rmpc-server/api/_pkg/auth"
+	"rmpc-server/api/_pkg/config"
+	"rmpc-server/api/_pkg/db"
+	"rmpc-server/api/_pkg/response"
+	"rmpc-server/api/_pkg/storage"
+	"rmpc-server/db/.gen/rmpc/public/model"
+)
+
+type proofJSON struct {
+	ID          string    `json:"id"`
+	ScoreID     string    `json:"score_id"`
+	Filename    string    `json:"filename"`
+	ContentType string    `json:"content_type"`
+	SizeBytes   int64     `json:"size_bytes"`
+	Sha256      string    `json:"sha256"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type proofsResponse struct {
+	Proofs []proofJSON `json:"proofs"`
+}
+
+type proofUploadResponse struct {
+	Proof  proofJSON `json:"proof"`
+	Queued bool      `json:"queued"` // the score was waiting for this proof and is now waiting for review
+}
+
+const maxProofsPerScore = 5
+
+// Proofs handles /api/scores/proofs, files such as replays or per-map logs
+// that back up a score. POST ?score_id=X&filename=Y uploads the request body
+// as a proof of one of the caller's own scores, with its media type in
+// Content-Type; a score held until a proof is attached moves to the review
+// queue, where a moderator checks the proof. GET ?score_id=X lists a score's
+// proofs and GET ?id=X downloads one, for scores on the public boards only,
+// or for any score with an API key allowed to moderate scores.
+func Proofs(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		handleGetProofs(w, r)
+	case http.MethodPost:
+		auth.RequireAuth(handleUploadProof)(w, r)
+	default:
+		response.Error(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+func handleUploadProof(w http.ResponseWriter, r *http.Request, playerID uuid.UUID) {
+	q := r.URL.Query()
+	scoreID, err := uuid.Parse(q.Get("score_id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid score_id")
+		return
+	}
+	filename, ok := proofFilename(q.Get("filename"))
+	if !ok {
+		response.Error(w, http.StatusBadRequest, "invalid filename")
+		return
+	}
+
+	contentType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || !slices.Contains(allowedProofTypes(), contentType) {
+		response.Error(w, http.StatusUnsupportedMediaType, "content type must be one of "+config.Env.ProofContentTypes)
+		return
+	}
+	maxBytes := int64(config.Env.ProofMaxBytes)
+	tooLarge := fmt.Sprintf("proof must not exceed %d bytes", maxBytes)
+	if r.ContentLength > maxBytes {
+		response.Error(w, http.StatusRequestEntityTooLarge, tooLarge)
+		return
+	}
+
+	database, err := db.GetDB()
+	if err != nil {
+		slog.Error("database connection error", "error", err)
+		response.Error(w, http.StatusServiceUnavailable, "service unavailable")
+		return
+	}
+
+	score, err := db.FindScore(database, scoreID)
+	if err != nil {
+		slog.Error("score lookup error", "error", err)
+		response.Error(w, http.StatusServiceUnavailable, "service unavailable")
+		return
+	}
+	if score == nil || score.PlayerID != playerID || score.RemovedAt != nil {
+		response.Error(w, http.StatusNotFound, "score not found")
+		return
+	}
+	if score.ReviewStatus == model.ReviewStatus_Rejected {
+		response.Error(w, http.StatusConflict, "score was rejected")
+		return
+	}
+
+	count, err := db.CountProofs(database, scoreID)
+	if err != nil {
+		slog.Error("count proofs error", "error", err)
+		response.Error(w, http.StatusServiceUnavailable, "service unavailable")
+		return
+	}
+	if count >= maxProofsPerScore {
+		response.Error(w, http.StatusConflict, fmt.Sprintf("a score can have at most %d proofs", maxProofsPerScore))
+		return
+	}
+
+	// Hash and measure the file while it streams into the store
+	proofID := uuid.New()
+	key := "proofs/" + scoreID.String() + "/" + proofID.String()
+	hash := sha256.New()
+	body := &countingReader{r: io.TeeReader(http.MaxBytesReader(w, r.Body, maxBytes), hash)}
+
+	store, err := storage.Open()
+	if err != nil {
+		slog.Error("proof store error", "error", err)
+		response.Error(w, http.StatusServiceUnavailable, "service unavailable")
+		return
+	}
+	if err := store.Put(r.Context(), key, body); err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			response.Error(w, http.StatusRequestEntityTooLarge, tooLarge)
+			return
+		}
+		slog.Error("proof store error", "error", err)
+		response.Error(w, http.StatusServiceUnavailable, "service unavailable")
+		return
+	}
+	if body.n == 0 {
+		deleteBlob(r, store, key)
+		response.Error(w, http.StatusBadRequest, "proof is empty")
+		return
+	}
+
+	proof, queued, err := db.AttachProof(database, db.ProofInput{
+		ID:          proofID,
+		ScoreID:     scoreID,
+		UploadedBy:  playerID,
+		BlobKey:     key,
+		Filename:    filename,
+		ContentType: contentType,
+		SizeBytes:   body.n,
+		Sha256:      hex.EncodeToString(hash.Sum(nil)),
+	})
+	if err != nil {
+		deleteBlob(r, store, key)
+		slog.Error("attach proof error", "error", err)
+		response.Error(w, http.StatusServiceUnavailable, "service unavailable")
+		return
+	}
+
+	audit.Record(r, audit.Entry{
+		Actor:      playerID,
+		Action:     audit.ActionScoreProof,
+		TargetType: audit.TargetScore,
+		TargetID:   scoreID.String(),
+		Details: map[string]any{
+			"proof_id": proof.ID.String(),
+			"sha256":   proof.Sha256,
+			"queued":   queued,
+		},
+	})
+
+	response.JSON(w, http.StatusCreated, proofUploadResponse{
+		Proof:  toProofJSON(proof),
+		Queued: queued,
+	})
+}
+
+func handleGetProofs(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	if q.Has("id") {
+		handleDownloadProof(w, r, q.Get("id"))
+		return
+	}
+
+	scoreID, err := uuid.Parse(q.Get("score_id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid score_id")
+		return
+	}
+
+	database, err := db.GetDB()
+	if err != nil {
+		slog.Error("database connection error", "error", err)
+		response.Error(w, http.StatusServiceUnavailable, "service unavailable")
+		return
+	}
+
+	rows, err := db.ListProofs(database, scoreID, moderating(r))
+	if err != nil {
+		slog.Error("list proofs error", "error", err)
+		response.Error(w, http.StatusServiceUnavailable, "service unavailable")
+		return
+	}
+
+	proofs := make([]proofJSON, len(rows))
+	for i, p := range rows {
+		proofs[i] = toProofJSON(p)
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	response.JSON(w, http.StatusOK, proofsResponse{Proofs: proofs})
+}
+
+func handleDownloadProof(w http.ResponseWriter, r *http.Request, id string) {
+	proofID, err := uuid.Parse(id)
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+
+	database, err := db.GetDB()
+	if err != nil {
+		slog.Error("database connection error", "error", err)
+		response.Error(w, http.StatusServiceUnavailable, "service unavailable")
+		return
+	}
+
+	proof, err := db.GetProof(database, proofID, moderating(r))
+	if err != nil {
+		slog.Error("proof lookup error", "error", err)
+		response.Error(w, http.StatusServiceUnavailable, "service unavailable")
+		return
+	}
+	if proof == nil {
+		response.Error(w, http.StatusNotFound, "proof not found")
+		return
+	}
+
+	store, err := storage.Open()
+	if err != nil {
+		slog.Error("proof store error", "error", err)
+		response.Error(w, http.StatusServiceUnavailable, "service unavailable")
+		return
+	}
+	rc, err := store.Get(r.Context(), proof.BlobKey)
+	if errors.Is(err, storage.ErrNotFound) {
+		slog.Error("proof blob missing", "proof_id", proof.ID, "key", proof.BlobKey)
+		response.Error(w, http.StatusNotFound, "proof not found")
+		return
+	}
+	if err != nil {
+		slog.Error("proof read error", "error", err)
+		response.Error(w, http.StatusServiceUnavailable, "service unavailable")
+		return
+	}
+	defer rc.Close()
+
+	// Always a download, never rendered in the browser
+	w.Header().Set("Content-Type", proof.ContentType)
+	w.Header().Set("Content-Length", strconv.FormatInt(proof.SizeBytes, 10))
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": proof.Filename}))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+	if _, err := io.Copy(w, rc); err != nil {
+		slog.Error("proof download error", "error", err)
+	}
+}
+
+// moderating reports whether the request carries an API key allowed to
+// moderate scores, which may see the proofs of scores held off the boards.
+func moderating(r *http.Request) bool {
+	principal, err := auth.AuthenticateAPIKey(r)
+	return err == nil && principal.HasScope(auth.ScopeScoresModerate)
+}
+
+func toProofJSON(p model.ScoreProofs) proofJSON {
+	return proofJSON{
+		ID:          p.ID.String(),
+		ScoreID:     p.ScoreID.String(),
+		Filename:    p.Filename,
+		ContentType: p.ContentType,
+		SizeBytes:   p.SizeBytes,
+		Sha256:      p.Sha256,
+		CreatedAt:   p.CreatedAt,
+	}
+}
+
+func allowedProofTypes() []string {
+	var types []string
+	for _, t := range strings.Split(config.Env.ProofContentTypes, ",") {
+		if t = strings.ToLower(strings.TrimSpace(t)); t != "" {
+			types = append(types, t)
+		}
+	}
+	return types
+}
+
+// proofFilename keeps the base name of a client-supplied filename for the
+// download's Content-Disposition. Empty means "proof".
+func proofFilename(s string) (string, bool) {
+	if s == "" {
+		return "proof", true
+	}
+	name := path.Base(strings.ReplaceAll(s, `\`, "/"))
+	if len(name) > 255 || name == "." || name == ".." || name == "/" {
+		return "", false
+	}
+	for _, c := range name {
+		if unicode.IsControl(c) {
+			return "", false
+		}
+	}
+	return name, true
+}
+
+// deleteBlob removes a blob that won't be recorded in the database.
+func deleteBlob(r *http.Request, store storage.BlobStore, key string) {
+	if err := store.Delete(r.Context(), key); err != nil {
+		slog.Error("proof cleanup error", "error", err, "key", key)
+	}
+}
+
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
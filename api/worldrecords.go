@@ -33,6 +33,11 @@ func Worldrecords(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	ruleset, ok := rulesetParam(w, r)
+	if !ok {
+		return
+	}
+
 	database, err := db.GetDB()
 	if err != nil {
 		slog.Error("database connection error", "error", err)
@@ -41,7 +46,7 @@ func Worldrecords(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// All-time world records
-	allTime, err := db.GetWorldRecords(database, db.WorldRecordParams{})
+	allTime, err := db.GetWorldRecords(database, db.WorldRecordParams{Ruleset: ruleset})
 	if err != nil {
 		slog.Error("world records query error", "error", err)
 		response.Error(w, http.StatusServiceUnavailable, "service unavailable")
@@ -53,6 +58,7 @@ func Worldrecords(w http.ResponseWriter, r *http.Request) {
 	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
 	monthEnd := monthStart.AddDate(0, 1, 0)
 	monthly, err := db.GetWorldRecords(database, db.WorldRecordParams{
+		Ruleset:   ruleset,
 		StartTime: &monthStart,
 		EndTime:   &monthEnd,
 	})
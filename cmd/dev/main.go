@@ -1,43 +1,63 @@
 package main
 
 import (
-	"encoding/json"
 	"log/slog"
 	"net/http"
 	"os"
 
 	handler "rmpc-server/api"
+	"rmpc-server/api/_pkg/auth"
+	"rmpc-server/api/_pkg/config"
+	"rmpc-server/api/_pkg/storage"
+	adminapi "rmpc-server/api/admin"
+	authapi "rmpc-server/api/auth"
 	metricsinc "rmpc-server/api/metrics"
+	runsapi "rmpc-server/api/runs"
+	scoresapi "rmpc-server/api/scores"
 )
 
-var devPlayers = map[string]struct {
-	AccountID   string
-	DisplayName string
-}{
-	"token-alice":   {"op-alice2-001", "AlicE"},
-	"token-bob":     {"op-bob2-002", "Boob"},
-	"token-charlie": {"op-charlie2-003", "Charlie New"},
-	"token-diana":   {"op-diana2-004", "Diana The Destroyer"},
-	"token-eve":     {"op-eve2-005", "Evelyn"},
+var devPlayers = map[string]auth.Identity{
+	"token-alice":   {AccountID: "op-alice2-001", DisplayName: "AlicE"},
+	"token-bob":     {AccountID: "op-bob2-002", DisplayName: "Boob"},
+	"token-charlie": {AccountID: "op-charlie2-003", DisplayName: "Charlie New"},
+	"token-diana":   {AccountID: "op-diana2-004", DisplayName: "Diana The Destroyer"},
+	"token-eve":     {AccountID: "op-eve2-005", DisplayName: "Evelyn"},
 }
 
 func main() {
-	// Start mock Openplanet auth server
-	mockAddr := os.Getenv("MOCK_OPENPLANET_ADDR")
-	if mockAddr == "" {
-		mockAddr = ":8081"
+	// Dev players log in with fixed tokens instead of going through Openplanet
+	auth.SetProvider(auth.NewStaticProvider(devPlayers))
+
+	// Runs held for a proof could never leave the hold without a store for it
+	if config.Env.ProofRequiredTopN > 0 {
+		if _, err := storage.Open(); err != nil {
+			slog.Error("PROOF_REQUIRED_TOP_N needs blob storage", "error", err)
+			os.Exit(1)
+		}
 	}
-	go startMockOpenplanet(mockAddr)
 
 	// Start main server
 	mux := http.NewServeMux()
 	mux.HandleFunc("/api/auth", handler.Auth)
+	mux.HandleFunc("/api/auth/sessions", authapi.Sessions)
+	mux.HandleFunc("/api/auth/refresh", authapi.Refresh)
 	mux.HandleFunc("/api/scores", handler.Scores)
+	mux.HandleFunc("/api/scores/batch", scoresapi.Batch)
+	mux.HandleFunc("/api/scores/proofs", scoresapi.Proofs)
+	mux.HandleFunc("/api/runs/start", runsapi.Start)
+	mux.HandleFunc("/api/runs/maps", runsapi.Maps)
 	mux.HandleFunc("/api/leaderboard", handler.Leaderboard)
 	mux.HandleFunc("/api/halloffame", handler.HallOfFame)
 	mux.HandleFunc("/api/player", handler.Player)
 	mux.HandleFunc("/api/activity", handler.Activity)
 	mux.HandleFunc("/api/metrics/inc", metricsinc.Handler)
+	mux.HandleFunc("/api/admin/keys", adminapi.Keys)
+	mux.HandleFunc("/api/admin/players", adminapi.Players)
+	mux.HandleFunc("/api/admin/bans", adminapi.Bans)
+	mux.HandleFunc("/api/admin/scores", adminapi.Scores)
+	mux.HandleFunc("/api/admin/reviews", adminapi.Reviews)
+	mux.HandleFunc("/api/admin/sessions", adminapi.Sessions)
+	mux.HandleFunc("/api/admin/audit", adminapi.Audit)
 	mux.Handle("/", http.FileServer(http.Dir("public")))
 
 	addr := os.Getenv("ADDR")
@@ -46,7 +66,6 @@ func main() {
 	}
 
 	slog.Info("listening", "addr", addr)
-	slog.Info("mock openplanet", "addr", mockAddr)
 	for token, p := range devPlayers {
 		slog.Info("dev player", "token", token, "name", p.DisplayName)
 	}
@@ -55,42 +74,3 @@ func main() {
 		os.Exit(1)
 	}
 }
-
-func startMockOpenplanet(addr string) {
-	mux := http.NewServeMux()
-	mux.HandleFunc("/api/auth/validate", handleMockValidate)
-
-	if err := http.ListenAndServe(addr, mux); err != nil {
-		slog.Error("mock openplanet error", "error", err)
-		os.Exit(1)
-	}
-}
-
-func handleMockValidate(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
-	var req struct {
-		Token  string `json:"token"`
-		Secret string `json:"secret"`
-	}
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "bad request", http.StatusBadRequest)
-		return
-	}
-
-	player, ok := devPlayers[req.Token]
-	if !ok {
-		w.WriteHeader(http.StatusUnauthorized)
-		json.NewEncoder(w).Encode(map[string]string{"error": "invalid token"})
-		return
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{
-		"account_id":   player.AccountID,
-		"display_name": player.DisplayName,
-	})
-}
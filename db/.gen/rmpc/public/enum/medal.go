@@ -0,0 +1,24 @@
+//
+// Code generated by go-jet DO NOT EDIT.
+//
+// WARNING: Changes to this file may cause incorrect behavior
+// and will be lost if the code is regenerated
+//
+
+package enum
+
+import "github.com/go-jet/jet/v2/postgres"
+
+var Medal = &struct {
+	None   postgres.StringExpression
+	Bronze postgres.StringExpression
+	Silver postgres.StringExpression
+	Gold   postgres.StringExpression
+	Author postgres.StringExpression
+}{
+	None:   postgres.NewEnumValue("none"),
+	Bronze: postgres.NewEnumValue("bronze"),
+	Silver: postgres.NewEnumValue("silver"),
+	Gold:   postgres.NewEnumValue("gold"),
+	Author: postgres.NewEnumValue("author"),
+}
@@ -0,0 +1,20 @@
+//
+// Code generated by go-jet DO NOT EDIT.
+//
+// WARNING: Changes to this file may cause incorrect behavior
+// and will be lost if the code is regenerated
+//
+
+package enum
+
+import "github.com/go-jet/jet/v2/postgres"
+
+var PlayerRole = &struct {
+	Player    postgres.StringExpression
+	Moderator postgres.StringExpression
+	Admin     postgres.StringExpression
+}{
+	Player:    postgres.NewEnumValue("player"),
+	Moderator: postgres.NewEnumValue("moderator"),
+	Admin:     postgres.NewEnumValue("admin"),
+}
@@ -0,0 +1,22 @@
+//
+// Code generated by go-jet DO NOT EDIT.
+//
+// WARNING: Changes to this file may cause incorrect behavior
+// and will be lost if the code is regenerated
+//
+
+package enum
+
+import "github.com/go-jet/jet/v2/postgres"
+
+var ReviewStatus = &struct {
+	Accepted      postgres.StringExpression
+	PendingReview postgres.StringExpression
+	Rejected      postgres.StringExpression
+	PendingProof  postgres.StringExpression
+}{
+	Accepted:      postgres.NewEnumValue("accepted"),
+	PendingReview: postgres.NewEnumValue("pending_review"),
+	Rejected:      postgres.NewEnumValue("rejected"),
+	PendingProof:  postgres.NewEnumValue("pending_proof"),
+}
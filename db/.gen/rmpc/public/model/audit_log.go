@@ -0,0 +1,24 @@
+//
+// Code generated by go-jet DO NOT EDIT.
+//
+// WARNING: Changes to this file may cause incorrect behavior
+// and will be lost if the code is regenerated
+//
+
+package model
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+type AuditLog struct {
+	ID         uuid.UUID `sql:"primary_key"`
+	CreatedAt  time.Time
+	ActorID    *uuid.UUID
+	Action     string
+	TargetType string
+	TargetID   *string
+	IP         *string
+	Details    *string
+}
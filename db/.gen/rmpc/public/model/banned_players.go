@@ -13,8 +13,13 @@ import (
 )
 
 type BannedPlayers struct {
-	ID       uuid.UUID `sql:"primary_key"`
-	PlayerID uuid.UUID
-	Reason   *string
-	BannedAt *time.Time
+	ID         uuid.UUID `sql:"primary_key"`
+	PlayerID   uuid.UUID
+	Reason     *string
+	BannedAt   *time.Time
+	ExpiresAt  *time.Time
+	BannedBy   *uuid.UUID
+	LiftedAt   *time.Time
+	LiftedBy   *uuid.UUID
+	LiftReason *string
 }
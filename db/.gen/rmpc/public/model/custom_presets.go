@@ -0,0 +1,18 @@
+//
+// Code generated by go-jet DO NOT EDIT.
+//
+// WARNING: Changes to this file may cause incorrect behavior
+// and will be lost if the code is regenerated
+//
+
+package model
+
+import (
+	"time"
+)
+
+type CustomPresets struct {
+	Hash      string `sql:"primary_key"`
+	Settings  string
+	CreatedAt *time.Time
+}
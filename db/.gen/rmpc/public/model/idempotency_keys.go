@@ -0,0 +1,23 @@
+//
+// Code generated by go-jet DO NOT EDIT.
+//
+// WARNING: Changes to this file may cause incorrect behavior
+// and will be lost if the code is regenerated
+//
+
+package model
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+type IdempotencyKeys struct {
+	PlayerID    uuid.UUID `sql:"primary_key"`
+	Key         string    `sql:"primary_key"`
+	RequestHash string
+	Response    *string
+	CreatedAt   time.Time
+	ReservedAt  time.Time
+	ScoreID     *uuid.UUID
+}
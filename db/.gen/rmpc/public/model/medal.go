@@ -0,0 +1,61 @@
+//
+// Code generated by go-jet DO NOT EDIT.
+//
+// WARNING: Changes to this file may cause incorrect behavior
+// and will be lost if the code is regenerated
+//
+
+package model
+
+import "errors"
+
+type Medal string
+
+const (
+	Medal_None   Medal = "none"
+	Medal_Bronze Medal = "bronze"
+	Medal_Silver Medal = "silver"
+	Medal_Gold   Medal = "gold"
+	Medal_Author Medal = "author"
+)
+
+var MedalAllValues = []Medal{
+	Medal_None,
+	Medal_Bronze,
+	Medal_Silver,
+	Medal_Gold,
+	Medal_Author,
+}
+
+func (e *Medal) Scan(value interface{}) error {
+	var enumValue string
+	switch val := value.(type) {
+	case string:
+		enumValue = val
+	case []byte:
+		enumValue = string(val)
+	default:
+		return errors.New("jet: Invalid scan value for AllTypesEnum enum. Enum value has to be of type string or []byte")
+	}
+
+	switch enumValue {
+	case "none":
+		*e = Medal_None
+	case "bronze":
+		*e = Medal_Bronze
+	case "silver":
+		*e = Medal_Silver
+	case "gold":
+		*e = Medal_Gold
+	case "author":
+		*e = Medal_Author
+	default:
+		return errors.New("jet: Invalid scan value '" + enumValue + "' for Medal enum")
+	}
+
+	return nil
+}
+
+func (e Medal) String() string {
+	return string(e)
+}
@@ -0,0 +1,53 @@
+//
+// Code generated by go-jet DO NOT EDIT.
+//
+// WARNING: Changes to this file may cause incorrect behavior
+// and will be lost if the code is regenerated
+//
+
+package model
+
+import "errors"
+
+type PlayerRole string
+
+const (
+	PlayerRole_Player    PlayerRole = "player"
+	PlayerRole_Moderator PlayerRole = "moderator"
+	PlayerRole_Admin     PlayerRole = "admin"
+)
+
+var PlayerRoleAllValues = []PlayerRole{
+	PlayerRole_Player,
+	PlayerRole_Moderator,
+	PlayerRole_Admin,
+}
+
+func (e *PlayerRole) Scan(value interface{}) error {
+	var enumValue string
+	switch val := value.(type) {
+	case string:
+		enumValue = val
+	case []byte:
+		enumValue = string(val)
+	default:
+		return errors.New("jet: Invalid scan value for AllTypesEnum enum. Enum value has to be of type string or []byte")
+	}
+
+	switch enumValue {
+	case "player":
+		*e = PlayerRole_Player
+	case "moderator":
+		*e = PlayerRole_Moderator
+	case "admin":
+		*e = PlayerRole_Admin
+	default:
+		return errors.New("jet: Invalid scan value '" + enumValue + "' for PlayerRole enum")
+	}
+
+	return nil
+}
+
+func (e PlayerRole) String() string {
+	return string(e)
+}
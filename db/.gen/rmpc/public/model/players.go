@@ -18,4 +18,5 @@ type Players struct {
 	DisplayName  string
 	CreatedAt    *time.Time
 	UpdatedAt    *time.Time
+	Role         PlayerRole
 }
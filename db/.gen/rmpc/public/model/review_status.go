@@ -0,0 +1,57 @@
+//
+// Code generated by go-jet DO NOT EDIT.
+//
+// WARNING: Changes to this file may cause incorrect behavior
+// and will be lost if the code is regenerated
+//
+
+package model
+
+import "errors"
+
+type ReviewStatus string
+
+const (
+	ReviewStatus_Accepted      ReviewStatus = "accepted"
+	ReviewStatus_PendingReview ReviewStatus = "pending_review"
+	ReviewStatus_Rejected      ReviewStatus = "rejected"
+	ReviewStatus_PendingProof  ReviewStatus = "pending_proof"
+)
+
+var ReviewStatusAllValues = []ReviewStatus{
+	ReviewStatus_Accepted,
+	ReviewStatus_PendingReview,
+	ReviewStatus_Rejected,
+	ReviewStatus_PendingProof,
+}
+
+func (e *ReviewStatus) Scan(value interface{}) error {
+	var enumValue string
+	switch val := value.(type) {
+	case string:
+		enumValue = val
+	case []byte:
+		enumValue = string(val)
+	default:
+		return errors.New("jet: Invalid scan value for AllTypesEnum enum. Enum value has to be of type string or []byte")
+	}
+
+	switch enumValue {
+	case "accepted":
+		*e = ReviewStatus_Accepted
+	case "pending_review":
+		*e = ReviewStatus_PendingReview
+	case "rejected":
+		*e = ReviewStatus_Rejected
+	case "pending_proof":
+		*e = ReviewStatus_PendingProof
+	default:
+		return errors.New("jet: Invalid scan value '" + enumValue + "' for ReviewStatus enum")
+	}
+
+	return nil
+}
+
+func (e ReviewStatus) String() string {
+	return string(e)
+}
@@ -0,0 +1,26 @@
+//
+// Code generated by go-jet DO NOT EDIT.
+//
+// WARNING: Changes to this file may cause incorrect behavior
+// and will be lost if the code is regenerated
+//
+
+package model
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+type RunMaps struct {
+	ID           uuid.UUID `sql:"primary_key"`
+	RunID        uuid.UUID
+	Seq          int32
+	MapUID       string
+	Medal        Medal
+	TimeMs       *int32
+	TargetTimeMs int32
+	Skipped      bool
+	Broken       bool
+	CreatedAt    time.Time
+}
@@ -0,0 +1,21 @@
+//
+// Code generated by go-jet DO NOT EDIT.
+//
+// WARNING: Changes to this file may cause incorrect behavior
+// and will be lost if the code is regenerated
+//
+
+package model
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+type Runs struct {
+	ID         uuid.UUID `sql:"primary_key"`
+	PlayerID   uuid.UUID
+	GameMode   GameMode
+	StartedAt  time.Time
+	FinishedAt *time.Time
+}
@@ -0,0 +1,25 @@
+//
+// Code generated by go-jet DO NOT EDIT.
+//
+// WARNING: Changes to this file may cause incorrect behavior
+// and will be lost if the code is regenerated
+//
+
+package model
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+type ScoreProofs struct {
+	ID          uuid.UUID `sql:"primary_key"`
+	ScoreID     uuid.UUID
+	UploadedBy  *uuid.UUID
+	BlobKey     string
+	Filename    string
+	ContentType string
+	SizeBytes   int64
+	Sha256      string
+	CreatedAt   time.Time
+}
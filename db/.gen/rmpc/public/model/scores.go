@@ -13,13 +13,30 @@ import (
 )
 
 type Scores struct {
-	ID            uuid.UUID `sql:"primary_key"`
-	PlayerID      uuid.UUID
-	GameMode      GameMode
-	Score         int32
-	MapsCompleted int32
-	MapsSkipped   int32
-	DurationMs    int32
-	Metadata      *string
-	CreatedAt     *time.Time
+	ID              uuid.UUID `sql:"primary_key"`
+	PlayerID        uuid.UUID
+	GameMode        GameMode
+	Score           int32
+	MapsCompleted   int32
+	MapsSkipped     int32
+	DurationMs      int32
+	Metadata        *string
+	CreatedAt       *time.Time
+	RemovedAt       *time.Time
+	RemovedBy       *uuid.UUID
+	RemovedReason   *string
+	RunID           *uuid.UUID
+	FlagReason      *string
+	ReviewStatus    ReviewStatus
+	ReviewedAt      *time.Time
+	ReviewedBy      *uuid.UUID
+	ReviewNote      *string
+	MetadataVersion *int16
+	PluginVersion   *string
+	GameVersion     *string
+	SettingsHash    *string
+	Ruleset         int16
+	SubmittedAt     *time.Time
+	BatchSessionID  *uuid.UUID
+	Batched         bool
 }
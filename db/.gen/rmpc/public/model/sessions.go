@@ -13,9 +13,11 @@ import (
 )
 
 type Sessions struct {
-	ID        uuid.UUID `sql:"primary_key"`
-	PlayerID  uuid.UUID
-	TokenHash string
-	CreatedAt *time.Time
-	ExpiresAt time.Time
+	ID          uuid.UUID `sql:"primary_key"`
+	PlayerID    uuid.UUID
+	TokenHash   string
+	CreatedAt   *time.Time
+	ExpiresAt   time.Time
+	DeviceLabel *string
+	LastUsedAt  *time.Time
 }
@@ -0,0 +1,102 @@
+//
+// Code generated by go-jet DO NOT EDIT.
+//
+// WARNING: Changes to this file may cause incorrect behavior
+// and will be lost if the code is regenerated
+//
+
+package table
+
+import (
+	"github.com/go-jet/jet/v2/postgres"
+)
+
+var APIKeys = newAPIKeysTable("public", "api_keys", "")
+
+type aPIKeysTable struct {
+	postgres.Table
+
+	// Columns
+	ID         postgres.ColumnString
+	PlayerID   postgres.ColumnString
+	Name       postgres.ColumnString
+	KeyHash    postgres.ColumnString
+	Scopes     postgres.ColumnString
+	CreatedAt  postgres.ColumnTimestampz
+	ExpiresAt  postgres.ColumnTimestampz
+	LastUsedAt postgres.ColumnTimestampz
+	RevokedAt  postgres.ColumnTimestampz
+
+	AllColumns     postgres.ColumnList
+	MutableColumns postgres.ColumnList
+	DefaultColumns postgres.ColumnList
+}
+
+type APIKeysTable struct {
+	aPIKeysTable
+
+	EXCLUDED aPIKeysTable
+}
+
+// AS creates new APIKeysTable with assigned alias
+func (a APIKeysTable) AS(alias string) *APIKeysTable {
+	return newAPIKeysTable(a.SchemaName(), a.TableName(), alias)
+}
+
+// Schema creates new APIKeysTable with assigned schema name
+func (a APIKeysTable) FromSchema(schemaName string) *APIKeysTable {
+	return newAPIKeysTable(schemaName, a.TableName(), a.Alias())
+}
+
+// WithPrefix creates new APIKeysTable with assigned table prefix
+func (a APIKeysTable) WithPrefix(prefix string) *APIKeysTable {
+	return newAPIKeysTable(a.SchemaName(), prefix+a.TableName(), a.TableName())
+}
+
+// WithSuffix creates new APIKeysTable with assigned table suffix
+func (a APIKeysTable) WithSuffix(suffix string) *APIKeysTable {
+	return newAPIKeysTable(a.SchemaName(), a.TableName()+suffix, a.TableName())
+}
+
+func newAPIKeysTable(schemaName, tableName, alias string) *APIKeysTable {
+	return &APIKeysTable{
+		aPIKeysTable: newAPIKeysTableImpl(schemaName, tableName, alias),
+		EXCLUDED:     newAPIKeysTableImpl("", "excluded", ""),
+	}
+}
+
+func newAPIKeysTableImpl(schemaName, tableName, alias string) aPIKeysTable {
+	var (
+		IDColumn         = postgres.StringColumn("id")
+		PlayerIDColumn   = postgres.StringColumn("player_id")
+		NameColumn       = postgres.StringColumn("name")
+		KeyHashColumn    = postgres.StringColumn("key_hash")
+		ScopesColumn     = postgres.StringColumn("scopes")
+		CreatedAtColumn  = postgres.TimestampzColumn("created_at")
+		ExpiresAtColumn  = postgres.TimestampzColumn("expires_at")
+		LastUsedAtColumn = postgres.TimestampzColumn("last_used_at")
+		RevokedAtColumn  = postgres.TimestampzColumn("revoked_at")
+		allColumns       = postgres.ColumnList{IDColumn, PlayerIDColumn, NameColumn, KeyHashColumn, ScopesColumn, CreatedAtColumn, ExpiresAtColumn, LastUsedAtColumn, RevokedAtColumn}
+		mutableColumns   = postgres.ColumnList{PlayerIDColumn, NameColumn, KeyHashColumn, ScopesColumn, CreatedAtColumn, ExpiresAtColumn, LastUsedAtColumn, RevokedAtColumn}
+		defaultColumns   = postgres.ColumnList{IDColumn, CreatedAtColumn}
+	)
+
+	return aPIKeysTable{
+		Table: postgres.NewTable(schemaName, tableName, alias, allColumns...),
+
+		//Columns
+		ID:         IDColumn,
+		PlayerID:   PlayerIDColumn,
+		Name:       NameColumn,
+		KeyHash:    KeyHashColumn,
+		Scopes:     ScopesColumn,
+		CreatedAt:  CreatedAtColumn,
+		ExpiresAt:  ExpiresAtColumn,
+		LastUsedAt: LastUsedAtColumn,
+		RevokedAt:  RevokedAtColumn,
+
+		AllColumns:     allColumns,
+		MutableColumns: mutableColumns,
+		DefaultColumns: defaultColumns,
+	}
+}
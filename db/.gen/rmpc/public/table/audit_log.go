@@ -0,0 +1,99 @@
+//
+// Code generated by go-jet DO NOT EDIT.
+//
+// WARNING: Changes to this file may cause incorrect behavior
+// and will be lost if the code is regenerated
+//
+
+package table
+
+import (
+	"github.com/go-jet/jet/v2/postgres"
+)
+
+var AuditLog = newAuditLogTable("public", "audit_log", "")
+
+type auditLogTable struct {
+	postgres.Table
+
+	// Columns
+	ID         postgres.ColumnString
+	CreatedAt  postgres.ColumnTimestampz
+	ActorID    postgres.ColumnString
+	Action     postgres.ColumnString
+	TargetType postgres.ColumnString
+	TargetID   postgres.ColumnString
+	IP         postgres.ColumnString
+	Details    postgres.ColumnString
+
+	AllColumns     postgres.ColumnList
+	MutableColumns postgres.ColumnList
+	DefaultColumns postgres.ColumnList
+}
+
+type AuditLogTable struct {
+	auditLogTable
+
+	EXCLUDED auditLogTable
+}
+
+// AS creates new AuditLogTable with assigned alias
+func (a AuditLogTable) AS(alias string) *AuditLogTable {
+	return newAuditLogTable(a.SchemaName(), a.TableName(), alias)
+}
+
+// Schema creates new AuditLogTable with assigned schema name
+func (a AuditLogTable) FromSchema(schemaName string) *AuditLogTable {
+	return newAuditLogTable(schemaName, a.TableName(), a.Alias())
+}
+
+// WithPrefix creates new AuditLogTable with assigned table prefix
+func (a AuditLogTable) WithPrefix(prefix string) *AuditLogTable {
+	return newAuditLogTable(a.SchemaName(), prefix+a.TableName(), a.TableName())
+}
+
+// WithSuffix creates new AuditLogTable with assigned table suffix
+func (a AuditLogTable) WithSuffix(suffix string) *AuditLogTable {
+	return newAuditLogTable(a.SchemaName(), a.TableName()+suffix, a.TableName())
+}
+
+func newAuditLogTable(schemaName, tableName, alias string) *AuditLogTable {
+	return &AuditLogTable{
+		auditLogTable: newAuditLogTableImpl(schemaName, tableName, alias),
+		EXCLUDED:      newAuditLogTableImpl("", "excluded", ""),
+	}
+}
+
+func newAuditLogTableImpl(schemaName, tableName, alias string) auditLogTable {
+	var (
+		IDColumn         = postgres.StringColumn("id")
+		CreatedAtColumn  = postgres.TimestampzColumn("created_at")
+		ActorIDColumn    = postgres.StringColumn("actor_id")
+		ActionColumn     = postgres.StringColumn("action")
+		TargetTypeColumn = postgres.StringColumn("target_type")
+		TargetIDColumn   = postgres.StringColumn("target_id")
+		IPColumn         = postgres.StringColumn("ip")
+		DetailsColumn    = postgres.StringColumn("details")
+		allColumns       = postgres.ColumnList{IDColumn, CreatedAtColumn, ActorIDColumn, ActionColumn, TargetTypeColumn, TargetIDColumn, IPColumn, DetailsColumn}
+		mutableColumns   = postgres.ColumnList{CreatedAtColumn, ActorIDColumn, ActionColumn, TargetTypeColumn, TargetIDColumn, IPColumn, DetailsColumn}
+		defaultColumns   = postgres.ColumnList{IDColumn, CreatedAtColumn}
+	)
+
+	return auditLogTable{
+		Table: postgres.NewTable(schemaName, tableName, alias, allColumns...),
+
+		//Columns
+		ID:         IDColumn,
+		CreatedAt:  CreatedAtColumn,
+		ActorID:    ActorIDColumn,
+		Action:     ActionColumn,
+		TargetType: TargetTypeColumn,
+		TargetID:   TargetIDColumn,
+		IP:         IPColumn,
+		Details:    DetailsColumn,
+
+		AllColumns:     allColumns,
+		MutableColumns: mutableColumns,
+		DefaultColumns: defaultColumns,
+	}
+}
@@ -17,10 +17,15 @@ type bannedPlayersTable struct {
 	postgres.Table
 
 	// Columns
-	ID       postgres.ColumnString
-	PlayerID postgres.ColumnString
-	Reason   postgres.ColumnString
-	BannedAt postgres.ColumnTimestampz
+	ID         postgres.ColumnString
+	PlayerID   postgres.ColumnString
+	Reason     postgres.ColumnString
+	BannedAt   postgres.ColumnTimestampz
+	ExpiresAt  postgres.ColumnTimestampz
+	BannedBy   postgres.ColumnString
+	LiftedAt   postgres.ColumnTimestampz
+	LiftedBy   postgres.ColumnString
+	LiftReason postgres.ColumnString
 
 	AllColumns     postgres.ColumnList
 	MutableColumns postgres.ColumnList
@@ -62,23 +67,33 @@ func newBannedPlayersTable(schemaName, tableName, alias string) *BannedPlayersTa
 
 func newBannedPlayersTableImpl(schemaName, tableName, alias string) bannedPlayersTable {
 	var (
-		IDColumn       = postgres.StringColumn("id")
-		PlayerIDColumn = postgres.StringColumn("player_id")
-		ReasonColumn   = postgres.StringColumn("reason")
-		BannedAtColumn = postgres.TimestampzColumn("banned_at")
-		allColumns     = postgres.ColumnList{IDColumn, PlayerIDColumn, ReasonColumn, BannedAtColumn}
-		mutableColumns = postgres.ColumnList{PlayerIDColumn, ReasonColumn, BannedAtColumn}
-		defaultColumns = postgres.ColumnList{IDColumn, BannedAtColumn}
+		IDColumn         = postgres.StringColumn("id")
+		PlayerIDColumn   = postgres.StringColumn("player_id")
+		ReasonColumn     = postgres.StringColumn("reason")
+		BannedAtColumn   = postgres.TimestampzColumn("banned_at")
+		ExpiresAtColumn  = postgres.TimestampzColumn("expires_at")
+		BannedByColumn   = postgres.StringColumn("banned_by")
+		LiftedAtColumn   = postgres.TimestampzColumn("lifted_at")
+		LiftedByColumn   = postgres.StringColumn("lifted_by")
+		LiftReasonColumn = postgres.StringColumn("lift_reason")
+		allColumns       = postgres.ColumnList{IDColumn, PlayerIDColumn, ReasonColumn, BannedAtColumn, ExpiresAtColumn, BannedByColumn, LiftedAtColumn, LiftedByColumn, LiftReasonColumn}
+		mutableColumns   = postgres.ColumnList{PlayerIDColumn, ReasonColumn, BannedAtColumn, ExpiresAtColumn, BannedByColumn, LiftedAtColumn, LiftedByColumn, LiftReasonColumn}
+		defaultColumns   = postgres.ColumnList{IDColumn, BannedAtColumn}
 	)
 
 	return bannedPlayersTable{
 		Table: postgres.NewTable(schemaName, tableName, alias, allColumns...),
 
 		//Columns
-		ID:       IDColumn,
-		PlayerID: PlayerIDColumn,
-		Reason:   ReasonColumn,
-		BannedAt: BannedAtColumn,
+		ID:         IDColumn,
+		PlayerID:   PlayerIDColumn,
+		Reason:     ReasonColumn,
+		BannedAt:   BannedAtColumn,
+		ExpiresAt:  ExpiresAtColumn,
+		BannedBy:   BannedByColumn,
+		LiftedAt:   LiftedAtColumn,
+		LiftedBy:   LiftedByColumn,
+		LiftReason: LiftReasonColumn,
 
 		AllColumns:     allColumns,
 		MutableColumns: mutableColumns,
@@ -0,0 +1,84 @@
+//
+// Code generated by go-jet DO NOT EDIT.
+//
+// WARNING: Changes to this file may cause incorrect behavior
+// and will be lost if the code is regenerated
+//
+
+package table
+
+import (
+	"github.com/go-jet/jet/v2/postgres"
+)
+
+var CustomPresets = newCustomPresetsTable("public", "custom_presets", "")
+
+type customPresetsTable struct {
+	postgres.Table
+
+	// Columns
+	Hash      postgres.ColumnString
+	Settings  postgres.ColumnString
+	CreatedAt postgres.ColumnTimestampz
+
+	AllColumns     postgres.ColumnList
+	MutableColumns postgres.ColumnList
+	DefaultColumns postgres.ColumnList
+}
+
+type CustomPresetsTable struct {
+	customPresetsTable
+
+	EXCLUDED customPresetsTable
+}
+
+// AS creates new CustomPresetsTable with assigned alias
+func (a CustomPresetsTable) AS(alias string) *CustomPresetsTable {
+	return newCustomPresetsTable(a.SchemaName(), a.TableName(), alias)
+}
+
+// Schema creates new CustomPresetsTable with assigned schema name
+func (a CustomPresetsTable) FromSchema(schemaName string) *CustomPresetsTable {
+	return newCustomPresetsTable(schemaName, a.TableName(), a.Alias())
+}
+
+// WithPrefix creates new CustomPresetsTable with assigned table prefix
+func (a CustomPresetsTable) WithPrefix(prefix string) *CustomPresetsTable {
+	return newCustomPresetsTable(a.SchemaName(), prefix+a.TableName(), a.TableName())
+}
+
+// WithSuffix creates new CustomPresetsTable with assigned table suffix
+func (a CustomPresetsTable) WithSuffix(suffix string) *CustomPresetsTable {
+	return newCustomPresetsTable(a.SchemaName(), a.TableName()+suffix, a.TableName())
+}
+
+func newCustomPresetsTable(schemaName, tableName, alias string) *CustomPresetsTable {
+	return &CustomPresetsTable{
+		customPresetsTable: newCustomPresetsTableImpl(schemaName, tableName, alias),
+		EXCLUDED:           newCustomPresetsTableImpl("", "excluded", ""),
+	}
+}
+
+func newCustomPresetsTableImpl(schemaName, tableName, alias string) customPresetsTable {
+	var (
+		HashColumn      = postgres.StringColumn("hash")
+		SettingsColumn  = postgres.StringColumn("settings")
+		CreatedAtColumn = postgres.TimestampzColumn("created_at")
+		allColumns      = postgres.ColumnList{HashColumn, SettingsColumn, CreatedAtColumn}
+		mutableColumns  = postgres.ColumnList{SettingsColumn, CreatedAtColumn}
+		defaultColumns  = postgres.ColumnList{CreatedAtColumn}
+	)
+
+	return customPresetsTable{
+		Table: postgres.NewTable(schemaName, tableName, alias, allColumns...),
+
+		//Columns
+		Hash:      HashColumn,
+		Settings:  SettingsColumn,
+		CreatedAt: CreatedAtColumn,
+
+		AllColumns:     allColumns,
+		MutableColumns: mutableColumns,
+		DefaultColumns: defaultColumns,
+	}
+}
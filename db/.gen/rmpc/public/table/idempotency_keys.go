@@ -0,0 +1,96 @@
+//
+// Code generated by go-jet DO NOT EDIT.
+//
+// WARNING: Changes to this file may cause incorrect behavior
+// and will be lost if the code is regenerated
+//
+
+package table
+
+import (
+	"github.com/go-jet/jet/v2/postgres"
+)
+
+var IdempotencyKeys = newIdempotencyKeysTable("public", "idempotency_keys", "")
+
+type idempotencyKeysTable struct {
+	postgres.Table
+
+	// Columns
+	PlayerID    postgres.ColumnString
+	Key         postgres.ColumnString
+	RequestHash postgres.ColumnString
+	Response    postgres.ColumnString
+	CreatedAt   postgres.ColumnTimestampz
+	ReservedAt  postgres.ColumnTimestampz
+	ScoreID     postgres.ColumnString
+
+	AllColumns     postgres.ColumnList
+	MutableColumns postgres.ColumnList
+	DefaultColumns postgres.ColumnList
+}
+
+type IdempotencyKeysTable struct {
+	idempotencyKeysTable
+
+	EXCLUDED idempotencyKeysTable
+}
+
+// AS creates new IdempotencyKeysTable with assigned alias
+func (a IdempotencyKeysTable) AS(alias string) *IdempotencyKeysTable {
+	return newIdempotencyKeysTable(a.SchemaName(), a.TableName(), alias)
+}
+
+// Schema creates new IdempotencyKeysTable with assigned schema name
+func (a IdempotencyKeysTable) FromSchema(schemaName string) *IdempotencyKeysTable {
+	return newIdempotencyKeysTable(schemaName, a.TableName(), a.Alias())
+}
+
+// WithPrefix creates new IdempotencyKeysTable with assigned table prefix
+func (a IdempotencyKeysTable) WithPrefix(prefix string) *IdempotencyKeysTable {
+	return newIdempotencyKeysTable(a.SchemaName(), prefix+a.TableName(), a.TableName())
+}
+
+// WithSuffix creates new IdempotencyKeysTable with assigned table suffix
+func (a IdempotencyKeysTable) WithSuffix(suffix string) *IdempotencyKeysTable {
+	return newIdempotencyKeysTable(a.SchemaName(), a.TableName()+suffix, a.TableName())
+}
+
+func newIdempotencyKeysTable(schemaName, tableName, alias string) *IdempotencyKeysTable {
+	return &IdempotencyKeysTable{
+		idempotencyKeysTable: newIdempotencyKeysTableImpl(schemaName, tableName, alias),
+		EXCLUDED:             newIdempotencyKeysTableImpl("", "excluded", ""),
+	}
+}
+
+func newIdempotencyKeysTableImpl(schemaName, tableName, alias string) idempotencyKeysTable {
+	var (
+		PlayerIDColumn    = postgres.StringColumn("player_id")
+		KeyColumn         = postgres.StringColumn("key")
+		RequestHashColumn = postgres.StringColumn("request_hash")
+		ResponseColumn    = postgres.StringColumn("response")
+		CreatedAtColumn   = postgres.TimestampzColumn("created_at")
+		ReservedAtColumn  = postgres.TimestampzColumn("reserved_at")
+		ScoreIDColumn     = postgres.StringColumn("score_id")
+		allColumns        = postgres.ColumnList{PlayerIDColumn, KeyColumn, RequestHashColumn, ResponseColumn, CreatedAtColumn, ReservedAtColumn, ScoreIDColumn}
+		mutableColumns    = postgres.ColumnList{RequestHashColumn, ResponseColumn, CreatedAtColumn, ReservedAtColumn, ScoreIDColumn}
+		defaultColumns    = postgres.ColumnList{CreatedAtColumn, ReservedAtColumn}
+	)
+
+	return idempotencyKeysTable{
+		Table: postgres.NewTable(schemaName, tableName, alias, allColumns...),
+
+		//Columns
+		PlayerID:    PlayerIDColumn,
+		Key:         KeyColumn,
+		RequestHash: RequestHashColumn,
+		Response:    ResponseColumn,
+		CreatedAt:   CreatedAtColumn,
+		ReservedAt:  ReservedAtColumn,
+		ScoreID:     ScoreIDColumn,
+
+		AllColumns:     allColumns,
+		MutableColumns: mutableColumns,
+		DefaultColumns: defaultColumns,
+	}
+}
@@ -22,6 +22,7 @@ type playersTable struct {
 	DisplayName  postgres.ColumnString
 	CreatedAt    postgres.ColumnTimestampz
 	UpdatedAt    postgres.ColumnTimestampz
+	Role         postgres.ColumnString
 
 	AllColumns     postgres.ColumnList
 	MutableColumns postgres.ColumnList
@@ -68,9 +69,10 @@ func newPlayersTableImpl(schemaName, tableName, alias string) playersTable {
 		DisplayNameColumn  = postgres.StringColumn("display_name")
 		CreatedAtColumn    = postgres.TimestampzColumn("created_at")
 		UpdatedAtColumn    = postgres.TimestampzColumn("updated_at")
-		allColumns         = postgres.ColumnList{IDColumn, OpenplanetIDColumn, DisplayNameColumn, CreatedAtColumn, UpdatedAtColumn}
-		mutableColumns     = postgres.ColumnList{OpenplanetIDColumn, DisplayNameColumn, CreatedAtColumn, UpdatedAtColumn}
-		defaultColumns     = postgres.ColumnList{IDColumn, CreatedAtColumn, UpdatedAtColumn}
+		RoleColumn         = postgres.StringColumn("role")
+		allColumns         = postgres.ColumnList{IDColumn, OpenplanetIDColumn, DisplayNameColumn, CreatedAtColumn, UpdatedAtColumn, RoleColumn}
+		mutableColumns     = postgres.ColumnList{OpenplanetIDColumn, DisplayNameColumn, CreatedAtColumn, UpdatedAtColumn, RoleColumn}
+		defaultColumns     = postgres.ColumnList{IDColumn, CreatedAtColumn, UpdatedAtColumn, RoleColumn}
 	)
 
 	return playersTable{
@@ -82,6 +84,7 @@ func newPlayersTableImpl(schemaName, tableName, alias string) playersTable {
 		DisplayName:  DisplayNameColumn,
 		CreatedAt:    CreatedAtColumn,
 		UpdatedAt:    UpdatedAtColumn,
+		Role:         RoleColumn,
 
 		AllColumns:     allColumns,
 		MutableColumns: mutableColumns,
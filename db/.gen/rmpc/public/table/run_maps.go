@@ -0,0 +1,105 @@
+//
+// Code generated by go-jet DO NOT EDIT.
+//
+// WARNING: Changes to this file may cause incorrect behavior
+// and will be lost if the code is regenerated
+//
+
+package table
+
+import (
+	"github.com/go-jet/jet/v2/postgres"
+)
+
+var RunMaps = newRunMapsTable("public", "run_maps", "")
+
+type runMapsTable struct {
+	postgres.Table
+
+	// Columns
+	ID           postgres.ColumnString
+	RunID        postgres.ColumnString
+	Seq          postgres.ColumnInteger
+	MapUID       postgres.ColumnString
+	Medal        postgres.ColumnString
+	TimeMs       postgres.ColumnInteger
+	TargetTimeMs postgres.ColumnInteger
+	Skipped      postgres.ColumnBool
+	Broken       postgres.ColumnBool
+	CreatedAt    postgres.ColumnTimestampz
+
+	AllColumns     postgres.ColumnList
+	MutableColumns postgres.ColumnList
+	DefaultColumns postgres.ColumnList
+}
+
+type RunMapsTable struct {
+	runMapsTable
+
+	EXCLUDED runMapsTable
+}
+
+// AS creates new RunMapsTable with assigned alias
+func (a RunMapsTable) AS(alias string) *RunMapsTable {
+	return newRunMapsTable(a.SchemaName(), a.TableName(), alias)
+}
+
+// Schema creates new RunMapsTable with assigned schema name
+func (a RunMapsTable) FromSchema(schemaName string) *RunMapsTable {
+	return newRunMapsTable(schemaName, a.TableName(), a.Alias())
+}
+
+// WithPrefix creates new RunMapsTable with assigned table prefix
+func (a RunMapsTable) WithPrefix(prefix string) *RunMapsTable {
+	return newRunMapsTable(a.SchemaName(), prefix+a.TableName(), a.TableName())
+}
+
+// WithSuffix creates new RunMapsTable with assigned table suffix
+func (a RunMapsTable) WithSuffix(suffix string) *RunMapsTable {
+	return newRunMapsTable(a.SchemaName(), a.TableName()+suffix, a.TableName())
+}
+
+func newRunMapsTable(schemaName, tableName, alias string) *RunMapsTable {
+	return &RunMapsTable{
+		runMapsTable: newRunMapsTableImpl(schemaName, tableName, alias),
+		EXCLUDED:     newRunMapsTableImpl("", "excluded", ""),
+	}
+}
+
+func newRunMapsTableImpl(schemaName, tableName, alias string) runMapsTable {
+	var (
+		IDColumn           = postgres.StringColumn("id")
+		RunIDColumn        = postgres.StringColumn("run_id")
+		SeqColumn          = postgres.IntegerColumn("seq")
+		MapUIDColumn       = postgres.StringColumn("map_uid")
+		MedalColumn        = postgres.StringColumn("medal")
+		TimeMsColumn       = postgres.IntegerColumn("time_ms")
+		TargetTimeMsColumn = postgres.IntegerColumn("target_time_ms")
+		SkippedColumn      = postgres.BoolColumn("skipped")
+		BrokenColumn       = postgres.BoolColumn("broken")
+		CreatedAtColumn    = postgres.TimestampzColumn("created_at")
+		allColumns         = postgres.ColumnList{IDColumn, RunIDColumn, SeqColumn, MapUIDColumn, MedalColumn, TimeMsColumn, TargetTimeMsColumn, SkippedColumn, BrokenColumn, CreatedAtColumn}
+		mutableColumns     = postgres.ColumnList{RunIDColumn, SeqColumn, MapUIDColumn, MedalColumn, TimeMsColumn, TargetTimeMsColumn, SkippedColumn, BrokenColumn, CreatedAtColumn}
+		defaultColumns     = postgres.ColumnList{IDColumn, MedalColumn, SkippedColumn, BrokenColumn, CreatedAtColumn}
+	)
+
+	return runMapsTable{
+		Table: postgres.NewTable(schemaName, tableName, alias, allColumns...),
+
+		//Columns
+		ID:           IDColumn,
+		RunID:        RunIDColumn,
+		Seq:          SeqColumn,
+		MapUID:       MapUIDColumn,
+		Medal:        MedalColumn,
+		TimeMs:       TimeMsColumn,
+		TargetTimeMs: TargetTimeMsColumn,
+		Skipped:      SkippedColumn,
+		Broken:       BrokenColumn,
+		CreatedAt:    CreatedAtColumn,
+
+		AllColumns:     allColumns,
+		MutableColumns: mutableColumns,
+		DefaultColumns: defaultColumns,
+	}
+}
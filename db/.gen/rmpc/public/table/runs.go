@@ -0,0 +1,90 @@
+//
+// Code generated by go-jet DO NOT EDIT.
+//
+// WARNING: Changes to this file may cause incorrect behavior
+// and will be lost if the code is regenerated
+//
+
+package table
+
+import (
+	"github.com/go-jet/jet/v2/postgres"
+)
+
+var Runs = newRunsTable("public", "runs", "")
+
+type runsTable struct {
+	postgres.Table
+
+	// Columns
+	ID         postgres.ColumnString
+	PlayerID   postgres.ColumnString
+	GameMode   postgres.ColumnString
+	StartedAt  postgres.ColumnTimestampz
+	FinishedAt postgres.ColumnTimestampz
+
+	AllColumns     postgres.ColumnList
+	MutableColumns postgres.ColumnList
+	DefaultColumns postgres.ColumnList
+}
+
+type RunsTable struct {
+	runsTable
+
+	EXCLUDED runsTable
+}
+
+// AS creates new RunsTable with assigned alias
+func (a RunsTable) AS(alias string) *RunsTable {
+	return newRunsTable(a.SchemaName(), a.TableName(), alias)
+}
+
+// Schema creates new RunsTable with assigned schema name
+func (a RunsTable) FromSchema(schemaName string) *RunsTable {
+	return newRunsTable(schemaName, a.TableName(), a.Alias())
+}
+
+// WithPrefix creates new RunsTable with assigned table prefix
+func (a RunsTable) WithPrefix(prefix string) *RunsTable {
+	return newRunsTable(a.SchemaName(), prefix+a.TableName(), a.TableName())
+}
+
+// WithSuffix creates new RunsTable with assigned table suffix
+func (a RunsTable) WithSuffix(suffix string) *RunsTable {
+	return newRunsTable(a.SchemaName(), a.TableName()+suffix, a.TableName())
+}
+
+func newRunsTable(schemaName, tableName, alias string) *RunsTable {
+	return &RunsTable{
+		runsTable: newRunsTableImpl(schemaName, tableName, alias),
+		EXCLUDED:  newRunsTableImpl("", "excluded", ""),
+	}
+}
+
+func newRunsTableImpl(schemaName, tableName, alias string) runsTable {
+	var (
+		IDColumn         = postgres.StringColumn("id")
+		PlayerIDColumn   = postgres.StringColumn("player_id")
+		GameModeColumn   = postgres.StringColumn("game_mode")
+		StartedAtColumn  = postgres.TimestampzColumn("started_at")
+		FinishedAtColumn = postgres.TimestampzColumn("finished_at")
+		allColumns       = postgres.ColumnList{IDColumn, PlayerIDColumn, GameModeColumn, StartedAtColumn, FinishedAtColumn}
+		mutableColumns   = postgres.ColumnList{PlayerIDColumn, GameModeColumn, StartedAtColumn, FinishedAtColumn}
+		defaultColumns   = postgres.ColumnList{IDColumn, StartedAtColumn}
+	)
+
+	return runsTable{
+		Table: postgres.NewTable(schemaName, tableName, alias, allColumns...),
+
+		//Columns
+		ID:         IDColumn,
+		PlayerID:   PlayerIDColumn,
+		GameMode:   GameModeColumn,
+		StartedAt:  StartedAtColumn,
+		FinishedAt: FinishedAtColumn,
+
+		AllColumns:     allColumns,
+		MutableColumns: mutableColumns,
+		DefaultColumns: defaultColumns,
+	}
+}
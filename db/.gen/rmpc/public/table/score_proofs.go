@@ -0,0 +1,102 @@
+//
+// Code generated by go-jet DO NOT EDIT.
+//
+// WARNING: Changes to this file may cause incorrect behavior
+// and will be lost if the code is regenerated
+//
+
+package table
+
+import (
+	"github.com/go-jet/jet/v2/postgres"
+)
+
+var ScoreProofs = newScoreProofsTable("public", "score_proofs", "")
+
+type scoreProofsTable struct {
+	postgres.Table
+
+	// Columns
+	ID          postgres.ColumnString
+	ScoreID     postgres.ColumnString
+	UploadedBy  postgres.ColumnString
+	BlobKey     postgres.ColumnString
+	Filename    postgres.ColumnString
+	ContentType postgres.ColumnString
+	SizeBytes   postgres.ColumnInteger
+	Sha256      postgres.ColumnString
+	CreatedAt   postgres.ColumnTimestampz
+
+	AllColumns     postgres.ColumnList
+	MutableColumns postgres.ColumnList
+	DefaultColumns postgres.ColumnList
+}
+
+type ScoreProofsTable struct {
+	scoreProofsTable
+
+	EXCLUDED scoreProofsTable
+}
+
+// AS creates new ScoreProofsTable with assigned alias
+func (a ScoreProofsTable) AS(alias string) *ScoreProofsTable {
+	return newScoreProofsTable(a.SchemaName(), a.TableName(), alias)
+}
+
+// Schema creates new ScoreProofsTable with assigned schema name
+func (a ScoreProofsTable) FromSchema(schemaName string) *ScoreProofsTable {
+	return newScoreProofsTable(schemaName, a.TableName(), a.Alias())
+}
+
+// WithPrefix creates new ScoreProofsTable with assigned table prefix
+func (a ScoreProofsTable) WithPrefix(prefix string) *ScoreProofsTable {
+	return newScoreProofsTable(a.SchemaName(), prefix+a.TableName(), a.TableName())
+}
+
+// WithSuffix creates new ScoreProofsTable with assigned table suffix
+func (a ScoreProofsTable) WithSuffix(suffix string) *ScoreProofsTable {
+	return newScoreProofsTable(a.SchemaName(), a.TableName()+suffix, a.TableName())
+}
+
+func newScoreProofsTable(schemaName, tableName, alias string) *ScoreProofsTable {
+	return &ScoreProofsTable{
+		scoreProofsTable: newScoreProofsTableImpl(schemaName, tableName, alias),
+		EXCLUDED:         newScoreProofsTableImpl("", "excluded", ""),
+	}
+}
+
+func newScoreProofsTableImpl(schemaName, tableName, alias string) scoreProofsTable {
+	var (
+		IDColumn          = postgres.StringColumn("id")
+		ScoreIDColumn     = postgres.StringColumn("score_id")
+		UploadedByColumn  = postgres.StringColumn("uploaded_by")
+		BlobKeyColumn     = postgres.StringColumn("blob_key")
+		FilenameColumn    = postgres.StringColumn("filename")
+		ContentTypeColumn = postgres.StringColumn("content_type")
+		SizeBytesColumn   = postgres.IntegerColumn("size_bytes")
+		Sha256Column      = postgres.StringColumn("sha256")
+		CreatedAtColumn   = postgres.TimestampzColumn("created_at")
+		allColumns        = postgres.ColumnList{IDColumn, ScoreIDColumn, UploadedByColumn, BlobKeyColumn, FilenameColumn, ContentTypeColumn, SizeBytesColumn, Sha256Column, CreatedAtColumn}
+		mutableColumns    = postgres.ColumnList{ScoreIDColumn, UploadedByColumn, BlobKeyColumn, FilenameColumn, ContentTypeColumn, SizeBytesColumn, Sha256Column, CreatedAtColumn}
+		defaultColumns    = postgres.ColumnList{IDColumn, CreatedAtColumn}
+	)
+
+	return scoreProofsTable{
+		Table: postgres.NewTable(schemaName, tableName, alias, allColumns...),
+
+		//Columns
+		ID:          IDColumn,
+		ScoreID:     ScoreIDColumn,
+		UploadedBy:  UploadedByColumn,
+		BlobKey:     BlobKeyColumn,
+		Filename:    FilenameColumn,
+		ContentType: ContentTypeColumn,
+		SizeBytes:   SizeBytesColumn,
+		Sha256:      Sha256Column,
+		CreatedAt:   CreatedAtColumn,
+
+		AllColumns:     allColumns,
+		MutableColumns: mutableColumns,
+		DefaultColumns: defaultColumns,
+	}
+}
@@ -17,15 +17,32 @@ type scoresTable struct {
 	postgres.Table
 
 	// Columns
-	ID            postgres.ColumnString
-	PlayerID      postgres.ColumnString
-	GameMode      postgres.ColumnString
-	Score         postgres.ColumnInteger
-	MapsCompleted postgres.ColumnInteger
-	MapsSkipped   postgres.ColumnInteger
-	DurationMs    postgres.ColumnInteger
-	Metadata      postgres.ColumnString
-	CreatedAt     postgres.ColumnTimestampz
+	ID              postgres.ColumnString
+	PlayerID        postgres.ColumnString
+	GameMode        postgres.ColumnString
+	Score           postgres.ColumnInteger
+	MapsCompleted   postgres.ColumnInteger
+	MapsSkipped     postgres.ColumnInteger
+	DurationMs      postgres.ColumnInteger
+	Metadata        postgres.ColumnString
+	CreatedAt       postgres.ColumnTimestampz
+	RemovedAt       postgres.ColumnTimestampz
+	RemovedBy       postgres.ColumnString
+	RemovedReason   postgres.ColumnString
+	RunID           postgres.ColumnString
+	FlagReason      postgres.ColumnString
+	ReviewStatus    postgres.ColumnString
+	ReviewedAt      postgres.ColumnTimestampz
+	ReviewedBy      postgres.ColumnString
+	ReviewNote      postgres.ColumnString
+	MetadataVersion postgres.ColumnInteger
+	PluginVersion   postgres.ColumnString
+	GameVersion     postgres.ColumnString
+	SettingsHash    postgres.ColumnString
+	Ruleset         postgres.ColumnInteger
+	SubmittedAt     postgres.ColumnTimestampz
+	BatchSessionID  postgres.ColumnString
+	Batched         postgres.ColumnBool
 
 	AllColumns     postgres.ColumnList
 	MutableColumns postgres.ColumnList
@@ -67,33 +84,67 @@ func newScoresTable(schemaName, tableName, alias string) *ScoresTable {
 
 func newScoresTableImpl(schemaName, tableName, alias string) scoresTable {
 	var (
-		IDColumn            = postgres.StringColumn("id")
-		PlayerIDColumn      = postgres.StringColumn("player_id")
-		GameModeColumn      = postgres.StringColumn("game_mode")
-		ScoreColumn         = postgres.IntegerColumn("score")
-		MapsCompletedColumn = postgres.IntegerColumn("maps_completed")
-		MapsSkippedColumn   = postgres.IntegerColumn("maps_skipped")
-		DurationMsColumn    = postgres.IntegerColumn("duration_ms")
-		MetadataColumn      = postgres.StringColumn("metadata")
-		CreatedAtColumn     = postgres.TimestampzColumn("created_at")
-		allColumns          = postgres.ColumnList{IDColumn, PlayerIDColumn, GameModeColumn, ScoreColumn, MapsCompletedColumn, MapsSkippedColumn, DurationMsColumn, MetadataColumn, CreatedAtColumn}
-		mutableColumns      = postgres.ColumnList{PlayerIDColumn, GameModeColumn, ScoreColumn, MapsCompletedColumn, MapsSkippedColumn, DurationMsColumn, MetadataColumn, CreatedAtColumn}
-		defaultColumns      = postgres.ColumnList{IDColumn, CreatedAtColumn}
+		IDColumn              = postgres.StringColumn("id")
+		PlayerIDColumn        = postgres.StringColumn("player_id")
+		GameModeColumn        = postgres.StringColumn("game_mode")
+		ScoreColumn           = postgres.IntegerColumn("score")
+		MapsCompletedColumn   = postgres.IntegerColumn("maps_completed")
+		MapsSkippedColumn     = postgres.IntegerColumn("maps_skipped")
+		DurationMsColumn      = postgres.IntegerColumn("duration_ms")
+		MetadataColumn        = postgres.StringColumn("metadata")
+		CreatedAtColumn       = postgres.TimestampzColumn("created_at")
+		RemovedAtColumn       = postgres.TimestampzColumn("removed_at")
+		RemovedByColumn       = postgres.StringColumn("removed_by")
+		RemovedReasonColumn   = postgres.StringColumn("removed_reason")
+		RunIDColumn           = postgres.StringColumn("run_id")
+		FlagReasonColumn      = postgres.StringColumn("flag_reason")
+		ReviewStatusColumn    = postgres.StringColumn("review_status")
+		ReviewedAtColumn      = postgres.TimestampzColumn("reviewed_at")
+		ReviewedByColumn      = postgres.StringColumn("reviewed_by")
+		ReviewNoteColumn      = postgres.StringColumn("review_note")
+		MetadataVersionColumn = postgres.IntegerColumn("metadata_version")
+		PluginVersionColumn   = postgres.StringColumn("plugin_version")
+		GameVersionColumn     = postgres.StringColumn("game_version")
+		SettingsHashColumn    = postgres.StringColumn("settings_hash")
+		RulesetColumn         = postgres.IntegerColumn("ruleset")
+		SubmittedAtColumn     = postgres.TimestampzColumn("submitted_at")
+		BatchSessionIDColumn  = postgres.StringColumn("batch_session_id")
+		BatchedColumn         = postgres.BoolColumn("batched")
+		allColumns            = postgres.ColumnList{IDColumn, PlayerIDColumn, GameModeColumn, ScoreColumn, MapsCompletedColumn, MapsSkippedColumn, DurationMsColumn, MetadataColumn, CreatedAtColumn, RemovedAtColumn, RemovedByColumn, RemovedReasonColumn, RunIDColumn, FlagReasonColumn, ReviewStatusColumn, ReviewedAtColumn, ReviewedByColumn, ReviewNoteColumn, MetadataVersionColumn, PluginVersionColumn, GameVersionColumn, SettingsHashColumn, RulesetColumn, SubmittedAtColumn, BatchSessionIDColumn, BatchedColumn}
+		mutableColumns        = postgres.ColumnList{PlayerIDColumn, GameModeColumn, ScoreColumn, MapsCompletedColumn, MapsSkippedColumn, DurationMsColumn, MetadataColumn, CreatedAtColumn, RemovedAtColumn, RemovedByColumn, RemovedReasonColumn, RunIDColumn, FlagReasonColumn, ReviewStatusColumn, ReviewedAtColumn, ReviewedByColumn, ReviewNoteColumn, MetadataVersionColumn, PluginVersionColumn, GameVersionColumn, SettingsHashColumn, RulesetColumn, SubmittedAtColumn, BatchSessionIDColumn, BatchedColumn}
+		defaultColumns        = postgres.ColumnList{IDColumn, CreatedAtColumn, ReviewStatusColumn, RulesetColumn, SubmittedAtColumn, BatchedColumn}
 	)
 
 	return scoresTable{
 		Table: postgres.NewTable(schemaName, tableName, alias, allColumns...),
 
 		//Columns
-		ID:            IDColumn,
-		PlayerID:      PlayerIDColumn,
-		GameMode:      GameModeColumn,
-		Score:         ScoreColumn,
-		MapsCompleted: MapsCompletedColumn,
-		MapsSkipped:   MapsSkippedColumn,
-		DurationMs:    DurationMsColumn,
-		Metadata:      MetadataColumn,
-		CreatedAt:     CreatedAtColumn,
+		ID:              IDColumn,
+		PlayerID:        PlayerIDColumn,
+		GameMode:        GameModeColumn,
+		Score:           ScoreColumn,
+		MapsCompleted:   MapsCompletedColumn,
+		MapsSkipped:     MapsSkippedColumn,
+		DurationMs:      DurationMsColumn,
+		Metadata:        MetadataColumn,
+		CreatedAt:       CreatedAtColumn,
+		RemovedAt:       RemovedAtColumn,
+		RemovedBy:       RemovedByColumn,
+		RemovedReason:   RemovedReasonColumn,
+		RunID:           RunIDColumn,
+		FlagReason:      FlagReasonColumn,
+		ReviewStatus:    ReviewStatusColumn,
+		ReviewedAt:      ReviewedAtColumn,
+		ReviewedBy:      ReviewedByColumn,
+		ReviewNote:      ReviewNoteColumn,
+		MetadataVersion: MetadataVersionColumn,
+		PluginVersion:   PluginVersionColumn,
+		GameVersion:     GameVersionColumn,
+		SettingsHash:    SettingsHashColumn,
+		Ruleset:         RulesetColumn,
+		SubmittedAt:     SubmittedAtColumn,
+		BatchSessionID:  BatchSessionIDColumn,
+		Batched:         BatchedColumn,
 
 		AllColumns:     allColumns,
 		MutableColumns: mutableColumns,
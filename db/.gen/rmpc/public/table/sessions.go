@@ -17,11 +17,13 @@ type sessionsTable struct {
 	postgres.Table
 
 	// Columns
-	ID        postgres.ColumnString
-	PlayerID  postgres.ColumnString
-	TokenHash postgres.ColumnString
-	CreatedAt postgres.ColumnTimestampz
-	ExpiresAt postgres.ColumnTimestampz
+	ID          postgres.ColumnString
+	PlayerID    postgres.ColumnString
+	TokenHash   postgres.ColumnString
+	CreatedAt   postgres.ColumnTimestampz
+	ExpiresAt   postgres.ColumnTimestampz
+	DeviceLabel postgres.ColumnString
+	LastUsedAt  postgres.ColumnTimestampz
 
 	AllColumns     postgres.ColumnList
 	MutableColumns postgres.ColumnList
@@ -63,25 +65,29 @@ func newSessionsTable(schemaName, tableName, alias string) *SessionsTable {
 
 func newSessionsTableImpl(schemaName, tableName, alias string) sessionsTable {
 	var (
-		IDColumn        = postgres.StringColumn("id")
-		PlayerIDColumn  = postgres.StringColumn("player_id")
-		TokenHashColumn = postgres.StringColumn("token_hash")
-		CreatedAtColumn = postgres.TimestampzColumn("created_at")
-		ExpiresAtColumn = postgres.TimestampzColumn("expires_at")
-		allColumns      = postgres.ColumnList{IDColumn, PlayerIDColumn, TokenHashColumn, CreatedAtColumn, ExpiresAtColumn}
-		mutableColumns  = postgres.ColumnList{PlayerIDColumn, TokenHashColumn, CreatedAtColumn, ExpiresAtColumn}
-		defaultColumns  = postgres.ColumnList{IDColumn, CreatedAtColumn}
+		IDColumn          = postgres.StringColumn("id")
+		PlayerIDColumn    = postgres.StringColumn("player_id")
+		TokenHashColumn   = postgres.StringColumn("token_hash")
+		CreatedAtColumn   = postgres.TimestampzColumn("created_at")
+		ExpiresAtColumn   = postgres.TimestampzColumn("expires_at")
+		DeviceLabelColumn = postgres.StringColumn("device_label")
+		LastUsedAtColumn  = postgres.TimestampzColumn("last_used_at")
+		allColumns        = postgres.ColumnList{IDColumn, PlayerIDColumn, TokenHashColumn, CreatedAtColumn, ExpiresAtColumn, DeviceLabelColumn, LastUsedAtColumn}
+		mutableColumns    = postgres.ColumnList{PlayerIDColumn, TokenHashColumn, CreatedAtColumn, ExpiresAtColumn, DeviceLabelColumn, LastUsedAtColumn}
+		defaultColumns    = postgres.ColumnList{IDColumn, CreatedAtColumn, LastUsedAtColumn}
 	)
 
 	return sessionsTable{
 		Table: postgres.NewTable(schemaName, tableName, alias, allColumns...),
 
 		//Columns
-		ID:        IDColumn,
-		PlayerID:  PlayerIDColumn,
-		TokenHash: TokenHashColumn,
-		CreatedAt: CreatedAtColumn,
-		ExpiresAt: ExpiresAtColumn,
+		ID:          IDColumn,
+		PlayerID:    PlayerIDColumn,
+		TokenHash:   TokenHashColumn,
+		CreatedAt:   CreatedAtColumn,
+		ExpiresAt:   ExpiresAtColumn,
+		DeviceLabel: DeviceLabelColumn,
+		LastUsedAt:  LastUsedAtColumn,
 
 		AllColumns:     allColumns,
 		MutableColumns: mutableColumns,
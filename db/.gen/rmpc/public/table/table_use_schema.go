@@ -10,9 +10,16 @@ package table
 // UseSchema sets a new schema name for all generated table SQL builder types. It is recommended to invoke
 // this method only once at the beginning of the program.
 func UseSchema(schema string) {
+	APIKeys = APIKeys.FromSchema(schema)
+	AuditLog = AuditLog.FromSchema(schema)
 	BannedPlayers = BannedPlayers.FromSchema(schema)
+	CustomPresets = CustomPresets.FromSchema(schema)
+	IdempotencyKeys = IdempotencyKeys.FromSchema(schema)
 	Metrics = Metrics.FromSchema(schema)
 	Players = Players.FromSchema(schema)
+	RunMaps = RunMaps.FromSchema(schema)
+	Runs = Runs.FromSchema(schema)
+	ScoreProofs = ScoreProofs.FromSchema(schema)
 	Scores = Scores.FromSchema(schema)
 	Sessions = Sessions.FromSchema(schema)
 }
@@ -0,0 +1,5 @@
+-- Multiple named sessions per player
+ALTER TABLE sessions ADD COLUMN device_label VARCHAR(100);
+ALTER TABLE sessions ADD COLUMN last_used_at TIMESTAMP WITH TIME ZONE DEFAULT NOW();
+
+CREATE INDEX idx_sessions_expires_at ON sessions(expires_at);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS api_keys;
+ALTER TABLE players DROP COLUMN IF EXISTS role;
+DROP TYPE IF EXISTS player_role;
@@ -0,0 +1,20 @@
+-- Player roles
+CREATE TYPE player_role AS ENUM ('player', 'moderator', 'admin');
+
+ALTER TABLE players ADD COLUMN role player_role NOT NULL DEFAULT 'player';
+
+-- Scoped API keys for privileged endpoints. Scopes are space-separated,
+-- e.g. 'scores:moderate players:ban'.
+CREATE TABLE api_keys (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    player_id UUID NOT NULL REFERENCES players(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    key_hash VARCHAR(255) UNIQUE NOT NULL,
+    scopes TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    expires_at TIMESTAMP WITH TIME ZONE,
+    last_used_at TIMESTAMP WITH TIME ZONE,
+    revoked_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_api_keys_player_id ON api_keys(player_id);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_players_display_name_trgm;
+ALTER TABLE scores DROP COLUMN IF EXISTS removed_reason;
+ALTER TABLE scores DROP COLUMN IF EXISTS removed_by;
+ALTER TABLE scores DROP COLUMN IF EXISTS removed_at;
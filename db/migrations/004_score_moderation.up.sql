@@ -0,0 +1,9 @@
+-- Soft-deleted scores stay in the table but are hidden from every public read
+ALTER TABLE scores ADD COLUMN removed_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE scores ADD COLUMN removed_by UUID REFERENCES players(id) ON DELETE SET NULL;
+ALTER TABLE scores ADD COLUMN removed_reason TEXT;
+
+-- Admin player search matches LOWER(display_name) LIKE '%name%', which only a
+-- trigram index can serve
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+CREATE INDEX idx_players_display_name_trgm ON players USING GIN (LOWER(display_name) gin_trgm_ops);
@@ -0,0 +1,10 @@
+-- Only the open ban per player survives the rollback
+DELETE FROM banned_players WHERE lifted_at IS NOT NULL;
+DROP INDEX IF EXISTS idx_banned_players_player_id;
+DROP INDEX IF EXISTS idx_banned_players_open;
+ALTER TABLE banned_players DROP COLUMN IF EXISTS lift_reason;
+ALTER TABLE banned_players DROP COLUMN IF EXISTS lifted_by;
+ALTER TABLE banned_players DROP COLUMN IF EXISTS lifted_at;
+ALTER TABLE banned_players DROP COLUMN IF EXISTS banned_by;
+ALTER TABLE banned_players DROP COLUMN IF EXISTS expires_at;
+ALTER TABLE banned_players ADD CONSTRAINT banned_players_player_id_key UNIQUE (player_id);
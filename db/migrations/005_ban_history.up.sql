@@ -0,0 +1,12 @@
+-- Bans become a history: lifting a ban closes its row instead of deleting it,
+-- and a ban may end on its own at expires_at. At most one ban per player is
+-- open (not lifted) at a time.
+ALTER TABLE banned_players DROP CONSTRAINT banned_players_player_id_key;
+ALTER TABLE banned_players ADD COLUMN expires_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE banned_players ADD COLUMN banned_by UUID REFERENCES players(id) ON DELETE SET NULL;
+ALTER TABLE banned_players ADD COLUMN lifted_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE banned_players ADD COLUMN lifted_by UUID REFERENCES players(id) ON DELETE SET NULL;
+ALTER TABLE banned_players ADD COLUMN lift_reason TEXT;
+
+CREATE UNIQUE INDEX idx_banned_players_open ON banned_players(player_id) WHERE lifted_at IS NULL;
+CREATE INDEX idx_banned_players_player_id ON banned_players(player_id, banned_at DESC);
@@ -0,0 +1,25 @@
+-- Append-only record of privileged and security-relevant actions. actor_id is
+-- deliberately not a foreign key: entries must outlive the players they name.
+CREATE TABLE audit_log (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    actor_id UUID,
+    action VARCHAR(64) NOT NULL,
+    target_type VARCHAR(32) NOT NULL,
+    target_id VARCHAR(255),
+    ip VARCHAR(64),
+    details JSONB
+);
+
+CREATE INDEX idx_audit_log_target ON audit_log(target_type, target_id, created_at DESC);
+CREATE INDEX idx_audit_log_created_at ON audit_log(created_at DESC);
+
+CREATE FUNCTION audit_log_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_log_append_only
+    BEFORE UPDATE OR DELETE ON audit_log
+    FOR EACH ROW EXECUTE FUNCTION audit_log_append_only();
@@ -0,0 +1,12 @@
+-- Idempotency-Key header values per player with the response they produced.
+-- response is NULL while the first request is still being processed.
+CREATE TABLE idempotency_keys (
+    player_id UUID NOT NULL REFERENCES players(id) ON DELETE CASCADE,
+    key VARCHAR(255) NOT NULL,
+    request_hash VARCHAR(64) NOT NULL,
+    response JSONB,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (player_id, key)
+);
+
+CREATE INDEX idx_idempotency_keys_created_at ON idempotency_keys(created_at);
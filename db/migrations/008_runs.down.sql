@@ -0,0 +1,2 @@
+ALTER TABLE scores DROP COLUMN IF EXISTS run_id;
+DROP TABLE IF EXISTS runs;
@@ -0,0 +1,14 @@
+-- Runs started through POST /api/runs/start. The server's own start time lets
+-- score submissions be checked against wall-clock time; finished_at is set
+-- when a score claims the run, so each run yields at most one score.
+CREATE TABLE runs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    player_id UUID NOT NULL REFERENCES players(id) ON DELETE CASCADE,
+    game_mode game_mode NOT NULL,
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    finished_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_runs_player_started ON runs(player_id, started_at DESC);
+
+ALTER TABLE scores ADD COLUMN run_id UUID UNIQUE REFERENCES runs(id) ON DELETE SET NULL;
@@ -0,0 +1,3 @@
+ALTER TABLE scores DROP COLUMN IF EXISTS flag_reason;
+DROP TABLE IF EXISTS run_maps;
+DROP TYPE IF EXISTS medal;
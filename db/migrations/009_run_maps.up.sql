@@ -0,0 +1,21 @@
+-- Medal reached on a map, from none to author
+CREATE TYPE medal AS ENUM ('none', 'bronze', 'silver', 'gold', 'author');
+
+-- Per-map results reported during a run. seq is the map's position in the
+-- run; reporting the same position again replaces the earlier result.
+CREATE TABLE run_maps (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    run_id UUID NOT NULL REFERENCES runs(id) ON DELETE CASCADE,
+    seq INTEGER NOT NULL,
+    map_uid VARCHAR(64) NOT NULL,
+    medal medal NOT NULL DEFAULT 'none',
+    time_ms INTEGER,
+    target_time_ms INTEGER NOT NULL,
+    skipped BOOLEAN NOT NULL DEFAULT FALSE,
+    broken BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE(run_id, seq)
+);
+
+-- Set when a submission's totals disagree with its run's map results
+ALTER TABLE scores ADD COLUMN flag_reason TEXT;
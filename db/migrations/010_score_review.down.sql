@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_scores_pending_review;
+ALTER TABLE scores DROP COLUMN IF EXISTS review_note;
+ALTER TABLE scores DROP COLUMN IF EXISTS reviewed_by;
+ALTER TABLE scores DROP COLUMN IF EXISTS reviewed_at;
+ALTER TABLE scores DROP COLUMN IF EXISTS review_status;
+DROP TYPE IF EXISTS review_status;
@@ -0,0 +1,10 @@
+-- Scores breaking a plausibility rule wait in pending_review, off the public
+-- boards, until an admin accepts or rejects them
+CREATE TYPE review_status AS ENUM ('accepted', 'pending_review', 'rejected');
+
+ALTER TABLE scores ADD COLUMN review_status review_status NOT NULL DEFAULT 'accepted';
+ALTER TABLE scores ADD COLUMN reviewed_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE scores ADD COLUMN reviewed_by UUID REFERENCES players(id) ON DELETE SET NULL;
+ALTER TABLE scores ADD COLUMN review_note TEXT;
+
+CREATE INDEX idx_scores_pending_review ON scores(created_at) WHERE review_status = 'pending_review';
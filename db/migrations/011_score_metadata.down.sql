@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_scores_metadata;
+DROP INDEX IF EXISTS idx_scores_game_version;
+DROP INDEX IF EXISTS idx_scores_plugin_version;
+ALTER TABLE scores DROP COLUMN IF EXISTS game_version;
+ALTER TABLE scores DROP COLUMN IF EXISTS plugin_version;
+ALTER TABLE scores DROP COLUMN IF EXISTS metadata_version;
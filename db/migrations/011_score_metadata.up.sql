@@ -0,0 +1,9 @@
+-- Known fields of versioned run metadata, copied out of the JSONB so they can
+-- be filtered on. NULL for scores with legacy (unversioned) metadata.
+ALTER TABLE scores ADD COLUMN metadata_version SMALLINT;
+ALTER TABLE scores ADD COLUMN plugin_version VARCHAR(32);
+ALTER TABLE scores ADD COLUMN game_version VARCHAR(64);
+
+CREATE INDEX idx_scores_plugin_version ON scores(plugin_version);
+CREATE INDEX idx_scores_game_version ON scores(game_version);
+CREATE INDEX idx_scores_metadata ON scores USING GIN (metadata jsonb_path_ops);
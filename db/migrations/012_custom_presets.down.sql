@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_scores_settings_hash;
+ALTER TABLE scores DROP CONSTRAINT IF EXISTS scores_settings_hash_custom;
+ALTER TABLE scores DROP COLUMN IF EXISTS settings_hash;
+DROP TABLE IF EXISTS custom_presets;
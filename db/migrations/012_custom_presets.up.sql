@@ -0,0 +1,13 @@
+-- Settings of custom-mode runs, keyed by the hash of their canonical JSON.
+-- Custom scores are only comparable within one preset.
+CREATE TABLE custom_presets (
+    hash VARCHAR(16) PRIMARY KEY,
+    settings JSONB NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+ALTER TABLE scores ADD COLUMN settings_hash VARCHAR(16) REFERENCES custom_presets(hash);
+ALTER TABLE scores ADD CONSTRAINT scores_settings_hash_custom
+    CHECK (settings_hash IS NULL OR game_mode = 'custom');
+
+CREATE INDEX idx_scores_settings_hash ON scores(settings_hash, score DESC) WHERE settings_hash IS NOT NULL;
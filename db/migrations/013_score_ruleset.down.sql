@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_scores_ruleset;
+ALTER TABLE scores DROP COLUMN IF EXISTS ruleset;
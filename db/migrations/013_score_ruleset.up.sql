@@ -0,0 +1,5 @@
+-- Scoring ruleset a run counts under, from config/plugin.yaml. Runs from
+-- different rulesets are never ranked against each other.
+ALTER TABLE scores ADD COLUMN ruleset SMALLINT NOT NULL DEFAULT 1;
+
+CREATE INDEX idx_scores_ruleset ON scores(ruleset, game_mode, created_at);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_scores_player_created_at;
+DROP INDEX IF EXISTS idx_scores_batch_session;
+ALTER TABLE scores DROP COLUMN IF EXISTS batch_session_id;
+ALTER TABLE scores DROP COLUMN IF EXISTS submitted_at;
@@ -0,0 +1,11 @@
+-- Runs played while the server was unreachable are uploaded later in a batch:
+-- created_at is when the run finished, submitted_at when the server got it
+ALTER TABLE scores ADD COLUMN submitted_at TIMESTAMP WITH TIME ZONE;
+UPDATE scores SET submitted_at = created_at;
+ALTER TABLE scores ALTER COLUMN submitted_at SET DEFAULT NOW();
+
+-- Session that uploaded the score in a batch, for per-session limits
+ALTER TABLE scores ADD COLUMN batch_session_id UUID REFERENCES sessions(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_scores_batch_session ON scores(batch_session_id, submitted_at) WHERE batch_session_id IS NOT NULL;
+CREATE INDEX idx_scores_player_created_at ON scores(player_id, created_at);
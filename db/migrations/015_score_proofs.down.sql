@@ -0,0 +1,13 @@
+DROP INDEX IF EXISTS idx_score_proofs_score;
+DROP TABLE IF EXISTS score_proofs;
+
+-- Postgres can't drop an enum value, so rebuild the type without it
+UPDATE scores SET review_status = 'pending_review' WHERE review_status = 'pending_proof';
+DROP INDEX IF EXISTS idx_scores_pending_review;
+ALTER TABLE scores ALTER COLUMN review_status DROP DEFAULT;
+ALTER TYPE review_status RENAME TO review_status_old;
+CREATE TYPE review_status AS ENUM ('accepted', 'pending_review', 'rejected');
+ALTER TABLE scores ALTER COLUMN review_status TYPE review_status USING review_status::text::review_status;
+ALTER TABLE scores ALTER COLUMN review_status SET DEFAULT 'accepted';
+DROP TYPE review_status_old;
+CREATE INDEX idx_scores_pending_review ON scores(created_at) WHERE review_status = 'pending_review';
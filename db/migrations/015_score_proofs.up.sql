@@ -0,0 +1,19 @@
+-- Runs that would place in the top N wait in pending_proof, off the public
+-- boards, until the player attaches a proof file
+ALTER TYPE review_status ADD VALUE 'pending_proof';
+
+-- Proof files (replays, per-map logs) attached to a score. The bytes live in
+-- blob storage under blob_key.
+CREATE TABLE score_proofs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    score_id UUID NOT NULL REFERENCES scores(id) ON DELETE CASCADE,
+    uploaded_by UUID REFERENCES players(id) ON DELETE SET NULL,
+    blob_key VARCHAR(255) NOT NULL UNIQUE,
+    filename VARCHAR(255) NOT NULL,
+    content_type VARCHAR(100) NOT NULL,
+    size_bytes BIGINT NOT NULL,
+    sha256 CHAR(64) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_score_proofs_score ON score_proofs(score_id, created_at);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_scores_player_batched;
+ALTER TABLE scores DROP COLUMN IF EXISTS batched;
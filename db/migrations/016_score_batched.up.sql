@@ -0,0 +1,6 @@
+-- Batched uploads are limited per player. batch_session_id is cleared when
+-- the session goes away, so it can't tell which scores count
+ALTER TABLE scores ADD COLUMN batched BOOLEAN NOT NULL DEFAULT FALSE;
+UPDATE scores SET batched = TRUE WHERE batch_session_id IS NOT NULL;
+
+CREATE INDEX idx_scores_player_batched ON scores(player_id, submitted_at) WHERE batched;
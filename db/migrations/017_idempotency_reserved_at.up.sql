@@ -0,0 +1,5 @@
+-- When the request now holding the key claimed it. A reservation without a
+-- response that is older than the function timeout was abandoned by a killed
+-- request and may be taken over
+ALTER TABLE idempotency_keys ADD COLUMN reserved_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW();
+UPDATE idempotency_keys SET reserved_at = created_at;
@@ -0,0 +1,4 @@
+-- The score stored by the request holding the key, set in the same
+-- transaction as the score. A key with a score is never released or taken
+-- over, even if its response couldn't be stored
+ALTER TABLE idempotency_keys ADD COLUMN score_id UUID REFERENCES scores(id) ON DELETE SET NULL;
@@ -14,6 +14,13 @@ SCORE=$((RANDOM % 2200000 + 550000))
 MAPS_COMPLETED=$((RANDOM % 20 + 1))
 MAPS_SKIPPED=$((RANDOM % 5))
 DURATION_MS=$(( (RANDOM % 540 + 60) * 1000 ))  # 60s – 600s
+PLUGIN_VERSION="${PLUGIN_VERSION:-1.0.0}"
+
+# Custom runs need their settings preset
+SETTINGS=""
+if [ "$GAME_MODE" = "custom" ]; then
+  SETTINGS=", \"settings\": {\"time_limit_ms\": 3600000, \"target_medal\": \"author\"}"
+fi
 
 echo "Player: $TOKEN | Mode: $GAME_MODE | Score: $SCORE | Maps: $MAPS_COMPLETED done, $MAPS_SKIPPED skipped | Duration: ${DURATION_MS}ms"
 
@@ -36,10 +43,11 @@ echo "Submitting score..."
 curl -s -X POST "$BASE_URL/api/scores" \
   -H "Content-Type: application/json" \
   -H "Authorization: Bearer $SESSION_TOKEN" \
+  -H "X-Plugin-Version: $PLUGIN_VERSION" \
   -d "{
     \"game_mode\": \"$GAME_MODE\",
     \"score\": $SCORE,
     \"maps_completed\": $MAPS_COMPLETED,
     \"maps_skipped\": $MAPS_SKIPPED,
-    \"duration_ms\": $DURATION_MS
+    \"duration_ms\": $DURATION_MS$SETTINGS
   }" | python3 -m json.tool 2>/dev/null || echo "(raw response above)"